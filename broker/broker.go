@@ -0,0 +1,261 @@
+package broker
+
+import (
+	"github.com/op/go-logging"
+	"sync"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/logger"
+	"foodtastechess/queries"
+	"foodtastechess/users"
+)
+
+// how many updates a watcher may fall behind by before it is dropped
+const watcherBuffer = 32
+
+// Broker passes each game's events on to whoever is watching the game,
+// or either of its players, along with the state of the game after the
+// event
+type Broker interface {
+	events.EventSubscriber
+
+	Watch(gameId game.Id) *Watcher
+	Spectate(gameId game.Id) *Watcher
+	WatchUser(userId users.Id) *Watcher
+	Unwatch(watcher *Watcher)
+
+	Spectators(gameId game.Id) int
+
+	Missed(userId users.Id, lastId int) []events.Event
+}
+
+// Update is an event in a game and the game as it stands after it
+type Update struct {
+	Event events.Event
+	Game  queries.GameInformation
+}
+
+// Watcher receives the updates to a game, or to every game a user plays
+// in. Spectator watchers are counted towards a game's spectators. Updates
+// is closed once the watcher is unwatched, or if it falls too far behind.
+type Watcher struct {
+	GameId    game.Id
+	UserId    users.Id
+	Spectator bool
+	Updates   chan Update
+}
+
+type BrokerService struct {
+	Queries   queries.ClientQueries `inject:"clientQueries"`
+	Events    events.Events         `inject:"events"`
+	Publisher events.EventPublisher `inject:"eventSubscriber"`
+
+	log *logging.Logger
+
+	lock         sync.Mutex
+	watchers     map[game.Id]map[*Watcher]bool
+	userWatchers map[users.Id]map[*Watcher]bool
+}
+
+func New() Broker {
+	s := new(BrokerService)
+	s.log = logger.Log("broker")
+	s.watchers = make(map[game.Id]map[*Watcher]bool)
+	s.userWatchers = make(map[users.Id]map[*Watcher]bool)
+	return s
+}
+
+func (s *BrokerService) PostPopulate() error {
+	// hear about events once queries reflect them, so the game state
+	// sent along with each is current
+	s.Publisher.Subscribe(s)
+	return nil
+}
+
+// Watch starts passing a game's updates to a new watcher
+func (s *BrokerService) Watch(gameId game.Id) *Watcher {
+	return s.watch(gameId, false)
+}
+
+// Spectate starts passing a game's updates to a new watcher that isn't
+// one of the game's players
+func (s *BrokerService) Spectate(gameId game.Id) *Watcher {
+	return s.watch(gameId, true)
+}
+
+// Spectators counts the watchers spectating a game
+func (s *BrokerService) Spectators(gameId game.Id) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	spectators := 0
+	for watcher := range s.watchers[gameId] {
+		if watcher.Spectator {
+			spectators += 1
+		}
+	}
+
+	return spectators
+}
+
+func (s *BrokerService) watch(gameId game.Id, spectator bool) *Watcher {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	watcher := &Watcher{
+		GameId:    gameId,
+		Spectator: spectator,
+		Updates:   make(chan Update, watcherBuffer),
+	}
+
+	if s.watchers[gameId] == nil {
+		s.watchers[gameId] = make(map[*Watcher]bool)
+	}
+	s.watchers[gameId][watcher] = true
+
+	return watcher
+}
+
+// WatchUser starts passing the updates to every game a user plays in to
+// a new watcher
+func (s *BrokerService) WatchUser(userId users.Id) *Watcher {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	watcher := &Watcher{
+		UserId:  userId,
+		Updates: make(chan Update, watcherBuffer),
+	}
+
+	if s.userWatchers[userId] == nil {
+		s.userWatchers[userId] = make(map[*Watcher]bool)
+	}
+	s.userWatchers[userId][watcher] = true
+
+	return watcher
+}
+
+// Missed returns the events in a user's games since the event with
+// lastId, for a user watcher that has reconnected
+func (s *BrokerService) Missed(userId users.Id, lastId int) []events.Event {
+	return s.Events.EventsForGamesSince(s.Queries.UserGames(userId), lastId)
+}
+
+// Unwatch stops passing updates to a watcher
+func (s *BrokerService) Unwatch(watcher *Watcher) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.remove(watcher)
+}
+
+func (s *BrokerService) Receive(event events.Event) error {
+	s.lock.Lock()
+	watchingUsers := len(s.userWatchers) > 0
+	s.lock.Unlock()
+
+	// an event may concern players the game doesn't yet have, like the
+	// invitee of a challenge
+	players := map[users.Id]bool{
+		event.WhiteId: true,
+		event.BlackId: true,
+	}
+	if watchingUsers {
+		for _, userId := range s.Queries.GamePlayers(event.GameId) {
+			players[userId] = true
+		}
+	}
+
+	recipients := s.recipients(event.GameId, players)
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	// describing the game is slow, so it is left until someone will be
+	// sent it and done without holding up the watchers
+	gameInfo, _ := s.Queries.GameInformation(event.GameId)
+	update := Update{Event: event, Game: gameInfo}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.send(recipients, update)
+	return nil
+}
+
+// recipients finds the watchers of a game and of its players
+func (s *BrokerService) recipients(gameId game.Id, players map[users.Id]bool) []*Watcher {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	recipients := []*Watcher{}
+	for watcher := range s.watchers[gameId] {
+		recipients = append(recipients, watcher)
+	}
+	for userId := range players {
+		if userId == "" {
+			continue
+		}
+		for watcher := range s.userWatchers[userId] {
+			recipients = append(recipients, watcher)
+		}
+	}
+
+	return recipients
+}
+
+// send passes an update on to watchers, which the caller holds the
+// lock for. Watchers unwatched since they were found are skipped.
+func (s *BrokerService) send(watchers []*Watcher, update Update) {
+	for _, watcher := range watchers {
+		if !s.watching(watcher) {
+			continue
+		}
+
+		select {
+		case watcher.Updates <- update:
+		default:
+			// a slow watcher mustn't hold up everyone else
+			s.log.Warning("Dropping watcher of game %v", update.Event.GameId)
+			s.remove(watcher)
+		}
+	}
+}
+
+// watching reports whether a watcher is still being sent updates, which
+// the caller holds the lock for
+func (s *BrokerService) watching(watcher *Watcher) bool {
+	if watcher.UserId != "" {
+		return s.userWatchers[watcher.UserId][watcher]
+	} else {
+		return s.watchers[watcher.GameId][watcher]
+	}
+}
+
+// remove drops a watcher, which the caller holds the lock for
+func (s *BrokerService) remove(watcher *Watcher) {
+	if watcher.UserId != "" {
+		watchers, ok := s.userWatchers[watcher.UserId]
+		if !ok || !watchers[watcher] {
+			return
+		}
+
+		delete(watchers, watcher)
+		if len(watchers) == 0 {
+			delete(s.userWatchers, watcher.UserId)
+		}
+	} else {
+		watchers, ok := s.watchers[watcher.GameId]
+		if !ok || !watchers[watcher] {
+			return
+		}
+
+		delete(watchers, watcher)
+		if len(watchers) == 0 {
+			delete(s.watchers, watcher.GameId)
+		}
+	}
+
+	close(watcher.Updates)
+}
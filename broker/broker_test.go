@@ -0,0 +1,217 @@
+package broker
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/queries"
+	"foodtastechess/users"
+)
+
+type BrokerTestSuite struct {
+	suite.Suite
+
+	service     *BrokerService
+	mockQueries *MockQueries
+	mockEvents  *MockEvents
+}
+
+func (suite *BrokerTestSuite) SetupTest() {
+	suite.mockQueries = new(MockQueries)
+	suite.mockEvents = new(MockEvents)
+
+	suite.service = New().(*BrokerService)
+	suite.service.Queries = suite.mockQueries
+	suite.service.Events = suite.mockEvents
+}
+
+func (suite *BrokerTestSuite) TestUpdates() {
+	var gameId game.Id = 3
+
+	gameInfo := queries.GameInformation{Id: gameId, TurnNumber: 1}
+	suite.mockQueries.On("GameInformation", gameId).Return(gameInfo, true)
+
+	first := suite.service.Watch(gameId)
+	second := suite.service.Watch(gameId)
+	other := suite.service.Watch(4)
+
+	move := events.NewMoveEvent(gameId, 1, "Pe2-e4")
+	suite.service.Receive(move)
+
+	assert := assert.New(suite.T())
+	for _, watcher := range []*Watcher{first, second} {
+		update := <-watcher.Updates
+		assert.Equal(move, update.Event)
+		assert.Equal(gameInfo, update.Game)
+	}
+	assert.Equal(0, len(other.Updates))
+
+	// unwatching closes the updates
+	suite.service.Unwatch(first)
+	_, open := <-first.Updates
+	assert.Equal(false, open)
+
+	suite.service.Receive(move)
+	assert.Equal(1, len(second.Updates))
+}
+
+// TestNoWatchers doesn't look up games nobody is watching, even while
+// other users are watched
+func (suite *BrokerTestSuite) TestNoWatchers() {
+	suite.service.Receive(events.NewMoveEvent(1, 1, "Pe2-e4"))
+
+	suite.service.WatchUser("other")
+	suite.mockQueries.On("GamePlayers", game.Id(1)).
+		Return(map[game.Color]users.Id{game.White: "white", game.Black: "black"})
+	suite.service.Receive(events.NewMoveEvent(1, 2, "Pe7-e5"))
+
+	suite.mockQueries.AssertNotCalled(suite.T(), "GameInformation", game.Id(1))
+}
+
+// TestSlowWatcher drops watchers that stop reading
+func (suite *BrokerTestSuite) TestSlowWatcher() {
+	var gameId game.Id = 3
+
+	suite.mockQueries.On("GameInformation", gameId).Return(queries.GameInformation{}, true)
+
+	watcher := suite.service.Watch(gameId)
+	for i := 0; i <= watcherBuffer; i++ {
+		suite.service.Receive(events.NewMoveEvent(gameId, game.TurnNumber(i+1), "Pe2-e4"))
+	}
+
+	received := 0
+	for range watcher.Updates {
+		received += 1
+	}
+
+	assert.Equal(suite.T(), watcherBuffer, received)
+}
+
+// TestSpectators counts the spectators of a game, but not its players
+func (suite *BrokerTestSuite) TestSpectators() {
+	var gameId game.Id = 3
+
+	suite.mockQueries.On("GameInformation", gameId).Return(queries.GameInformation{}, true)
+
+	player := suite.service.Watch(gameId)
+	first := suite.service.Spectate(gameId)
+	second := suite.service.Spectate(gameId)
+	suite.service.Spectate(4)
+
+	assert := assert.New(suite.T())
+	assert.Equal(2, suite.service.Spectators(gameId))
+
+	// spectators are sent updates like anyone else
+	suite.service.Receive(events.NewMoveEvent(gameId, 1, "Pe2-e4"))
+	for _, watcher := range []*Watcher{player, first, second} {
+		assert.Equal(1, len(watcher.Updates))
+	}
+
+	suite.service.Unwatch(first)
+	suite.service.Unwatch(player)
+	assert.Equal(1, suite.service.Spectators(gameId))
+	assert.Equal(0, suite.service.Spectators(5))
+}
+
+// TestUserUpdates passes a user the updates to the games they play in
+func (suite *BrokerTestSuite) TestUserUpdates() {
+	var (
+		white users.Id = "white"
+		black users.Id = "black"
+	)
+
+	gameInfo := queries.GameInformation{
+		Id:    3,
+		White: users.User{Uuid: white},
+		Black: users.User{Uuid: black},
+	}
+	suite.mockQueries.On("GameInformation", game.Id(3)).Return(gameInfo, true)
+	suite.mockQueries.On("GameInformation", game.Id(4)).Return(queries.GameInformation{Id: 4}, true)
+	suite.mockQueries.On("GamePlayers", game.Id(3)).
+		Return(map[game.Color]users.Id{game.White: white, game.Black: black})
+	suite.mockQueries.On("GamePlayers", game.Id(4)).
+		Return(map[game.Color]users.Id{game.White: "", game.Black: ""})
+
+	whiteWatcher := suite.service.WatchUser(white)
+	blackWatcher := suite.service.WatchUser(black)
+	other := suite.service.WatchUser("other")
+
+	move := events.NewMoveEvent(3, 1, "Pe2-e4")
+	suite.service.Receive(move)
+	suite.service.Receive(events.NewMoveEvent(4, 1, "Pe2-e4"))
+
+	assert := assert.New(suite.T())
+	for _, watcher := range []*Watcher{whiteWatcher, blackWatcher} {
+		assert.Equal(1, len(watcher.Updates))
+		update := <-watcher.Updates
+		assert.Equal(move, update.Event)
+	}
+	assert.Equal(0, len(other.Updates))
+
+	// a challenge reaches its invitee before they have joined
+	challenge := events.NewGameCreateEvent(5, white, "other")
+	suite.mockQueries.On("GameInformation", game.Id(5)).Return(queries.GameInformation{Id: 5}, true)
+	suite.mockQueries.On("GamePlayers", game.Id(5)).
+		Return(map[game.Color]users.Id{game.White: white, game.Black: ""})
+	suite.service.Receive(challenge)
+	assert.Equal(1, len(other.Updates))
+	update := <-other.Updates
+	assert.Equal(challenge, update.Event)
+
+	suite.service.Unwatch(other)
+	_, open := <-other.Updates
+	assert.Equal(false, open)
+}
+
+func (suite *BrokerTestSuite) TestMissed() {
+	var (
+		userId  users.Id  = "bob"
+		gameIds []game.Id = []game.Id{1, 2}
+		missed            = []events.Event{events.NewMoveEvent(2, 3, "Pe2-e4")}
+	)
+
+	suite.mockQueries.On("UserGames", userId).Return(gameIds)
+	suite.mockEvents.On("EventsForGamesSince", gameIds, 12).Return(missed)
+
+	assert.Equal(suite.T(), missed, suite.service.Missed(userId, 12))
+}
+
+func TestBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(BrokerTestSuite))
+}
+
+// MockQueries answers the queries the broker makes
+type MockQueries struct {
+	queries.ClientQueries
+	mock.Mock
+}
+
+func (m *MockQueries) GameInformation(gameId game.Id) (queries.GameInformation, bool) {
+	args := m.Called(gameId)
+	return args.Get(0).(queries.GameInformation), args.Bool(1)
+}
+
+func (m *MockQueries) GamePlayers(gameId game.Id) map[game.Color]users.Id {
+	args := m.Called(gameId)
+	return args.Get(0).(map[game.Color]users.Id)
+}
+
+func (m *MockQueries) UserGames(userId users.Id) []game.Id {
+	args := m.Called(userId)
+	return args.Get(0).([]game.Id)
+}
+
+// MockEvents looks up the events the broker replays
+type MockEvents struct {
+	events.Events
+	mock.Mock
+}
+
+func (m *MockEvents) EventsForGamesSince(gameIds []game.Id, lastId int) []events.Event {
+	args := m.Called(gameIds, lastId)
+	return args.Get(0).([]events.Event)
+}
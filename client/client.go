@@ -0,0 +1,413 @@
+// Package client is a typed Go client for the chess API. It sends and
+// receives the API's own types, and is checked against the API's OpenAPI
+// document so the two can't drift apart.
+//
+// Every route but the OpenAPI document needs a logged in session, so the
+// http.Client given should carry the session cookie, say through a
+// cookie jar.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"foodtastechess/commands"
+	"foodtastechess/conditionals"
+	"foodtastechess/game"
+	"foodtastechess/matchmaking"
+	"foodtastechess/queries"
+	"foodtastechess/ratings"
+	"foodtastechess/server/api"
+	"foodtastechess/tournaments"
+	"foodtastechess/users"
+)
+
+type Client struct {
+	// where the API is served, such as https://example.com/api/v1
+	BaseUrl string
+
+	HTTP *http.Client
+}
+
+func New(baseUrl string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{BaseUrl: baseUrl, HTTP: httpClient}
+}
+
+// Error is a request the API refused or couldn't find. Its code says
+// why, such as commands.NotYourTurn.
+type Error struct {
+	StatusCode int
+	Code       commands.Code
+	Message    string
+	Details    map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Spec fetches the API's OpenAPI document
+func (c *Client) Spec() (api.OpenAPI, error) {
+	spec := api.OpenAPI{}
+	err := c.do("GET", "/openapi.json", nil, nil, &spec)
+	return spec, err
+}
+
+// Games are the ids of the user's games
+func (c *Client) Games() ([]game.Id, error) {
+	gameIds := []game.Id{}
+	err := c.do("GET", "/games", nil, nil, &gameIds)
+	return gameIds, err
+}
+
+// Lobby lists the open games the user may join
+func (c *Client) Lobby(filter queries.LobbyFilter) ([]queries.LobbyEntry, error) {
+	query := url.Values{}
+	if filter.Category != "" {
+		query.Set("category", string(filter.Category))
+	}
+	if filter.Color != "" {
+		query.Set("color", string(filter.Color))
+	}
+
+	entries := []queries.LobbyEntry{}
+	err := c.do("GET", "/lobby", query, nil, &entries)
+	return entries, err
+}
+
+// Leaderboard lists the best rated players in a time category. A limit
+// of zero leaves it to the server.
+func (c *Client) Leaderboard(category game.TimeCategory, limit int) (queries.LeaderboardInformation, error) {
+	query := url.Values{}
+	query.Set("category", string(category))
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	leaderboard := queries.LeaderboardInformation{}
+	err := c.do("GET", "/leaderboard", query, nil, &leaderboard)
+	return leaderboard, err
+}
+
+func (c *Client) Seeks() ([]matchmaking.Seek, error) {
+	seeks := []matchmaking.Seek{}
+	err := c.do("GET", "/seeks", nil, nil, &seeks)
+	return seeks, err
+}
+
+// Seek looks for an opponent, replacing any seek the user has
+func (c *Client) Seek(seek api.SeekBody) error {
+	return c.do("POST", "/seeks", nil, seek, nil)
+}
+
+func (c *Client) CancelSeek() error {
+	return c.do("DELETE", "/seeks", nil, nil, nil)
+}
+
+func (c *Client) Tournaments() ([]tournaments.Tournament, error) {
+	all := []tournaments.Tournament{}
+	err := c.do("GET", "/tournaments", nil, nil, &all)
+	return all, err
+}
+
+func (c *Client) Tournament(id int) (tournaments.Tournament, error) {
+	tournament := tournaments.Tournament{}
+	err := c.do("GET", fmt.Sprintf("/tournaments/%d", id), nil, nil, &tournament)
+	return tournament, err
+}
+
+// CreateTournament creates a tournament organized by the user
+func (c *Client) CreateTournament(spec api.CreateTournamentBody) (tournaments.Tournament, error) {
+	tournament := tournaments.Tournament{}
+	err := c.do("POST", "/tournaments", nil, spec, &tournament)
+	return tournament, err
+}
+
+func (c *Client) StartTournament(id int) error {
+	return c.do("POST", fmt.Sprintf("/tournaments/%d/start", id), nil, nil, nil)
+}
+
+// LiveGames are the public games being played
+func (c *Client) LiveGames() ([]api.LiveGameResponse, error) {
+	liveGames := []api.LiveGameResponse{}
+	err := c.do("GET", "/games/live", nil, nil, &liveGames)
+	return liveGames, err
+}
+
+// Game is a game as the user sees it
+func (c *Client) Game(id game.Id) (api.GameInfoResponse, error) {
+	gameInfo := api.GameInfoResponse{}
+	err := c.do("GET", fmt.Sprintf("/games/%d", id), nil, nil, &gameInfo)
+	return gameInfo, err
+}
+
+// Wait waits up to a minute for a game to pass a turn, then gives the
+// game as it stands
+func (c *Client) Wait(id game.Id, afterTurn game.TurnNumber) (api.GameInfoResponse, error) {
+	query := url.Values{}
+	query.Set("afterTurn", strconv.Itoa(int(afterTurn)))
+
+	gameInfo := api.GameInfoResponse{}
+	err := c.do("GET", fmt.Sprintf("/games/%d/wait", id), query, nil, &gameInfo)
+	return gameInfo, err
+}
+
+// History is every move of a game, starting from the initial board
+func (c *Client) History(id game.Id) ([]game.MoveRecord, error) {
+	history := []game.MoveRecord{}
+	err := c.do("GET", fmt.Sprintf("/games/%d/history", id), nil, nil, &history)
+	return history, err
+}
+
+func (c *Client) ValidMoves(id game.Id) ([]game.MoveRecord, error) {
+	validMoves := []game.MoveRecord{}
+	err := c.do("GET", fmt.Sprintf("/games/%d/validmoves", id), nil, nil, &validMoves)
+	return validMoves, err
+}
+
+func (c *Client) Chat(id game.Id) ([]queries.ChatMessage, error) {
+	messages := []queries.ChatMessage{}
+	err := c.do("GET", fmt.Sprintf("/games/%d/chat", id), nil, nil, &messages)
+	return messages, err
+}
+
+// PGN exports a game and its annotations
+func (c *Client) PGN(id game.Id) (string, error) {
+	res, err := c.send("GET", fmt.Sprintf("/games/%d/pgn", id), nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	pgn, err := ioutil.ReadAll(res.Body)
+	return string(pgn), err
+}
+
+func (c *Client) Conditionals(id game.Id) ([]conditionals.Condition, error) {
+	conditions := []conditionals.Condition{}
+	err := c.do("GET", fmt.Sprintf("/games/%d/conditionals", id), nil, nil, &conditions)
+	return conditions, err
+}
+
+// SetConditionals replaces the user's conditional moves in a game. None
+// clears them.
+func (c *Client) SetConditionals(id game.Id, conditions []conditionals.Condition) error {
+	body := api.ConditionalsBody{Conditions: conditions}
+	return c.do("POST", fmt.Sprintf("/games/%d/conditionals", id), nil, body, nil)
+}
+
+// Premove is the move the user has queued in a game, empty if none
+func (c *Client) Premove(id game.Id) (game.AlgebraicMove, error) {
+	premove := api.PremoveBody{}
+	err := c.do("GET", fmt.Sprintf("/games/%d/premove", id), nil, nil, &premove)
+	return premove.Move, err
+}
+
+// SetPremove queues a move for once the opponent has moved. An empty
+// move clears it.
+func (c *Client) SetPremove(id game.Id, move game.AlgebraicMove) error {
+	body := api.PremoveBody{Move: move}
+	return c.do("POST", fmt.Sprintf("/games/%d/premove", id), nil, body, nil)
+}
+
+// User is a user, with their id
+func (c *Client) User(id users.Id) (api.UserResponse, error) {
+	user := api.UserResponse{}
+	err := c.do("GET", "/users/"+url.PathEscape(string(id)), nil, nil, &user)
+	return user, err
+}
+
+func (c *Client) RatingHistory(id users.Id, category game.TimeCategory) ([]ratings.HistoryEntry, error) {
+	path := fmt.Sprintf(
+		"/users/%s/ratings/%s", url.PathEscape(string(id)), url.PathEscape(string(category)),
+	)
+
+	history := []ratings.HistoryEntry{}
+	err := c.do("GET", path, nil, nil, &history)
+	return history, err
+}
+
+func (c *Client) PlayerStats(id users.Id) (queries.PlayerStats, error) {
+	stats := queries.PlayerStats{}
+	err := c.do("GET", "/users/"+url.PathEscape(string(id))+"/stats", nil, nil, &stats)
+	return stats, err
+}
+
+func (c *Client) HeadToHead(id, opponentId users.Id) (queries.HeadToHead, error) {
+	path := fmt.Sprintf(
+		"/users/%s/headtohead/%s", url.PathEscape(string(id)), url.PathEscape(string(opponentId)),
+	)
+
+	record := queries.HeadToHead{}
+	err := c.do("GET", path, nil, nil, &record)
+	return record, err
+}
+
+// CreateGame creates a game. A private game without an opponent gives
+// its invitation, and other games none.
+func (c *Client) CreateGame(body api.CreateGameBody) (*api.InvitationResponse, error) {
+	raw := json.RawMessage{}
+	err := c.do("POST", "/games/create", nil, body, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := new(api.InvitationResponse)
+	if json.Unmarshal(raw, invitation) != nil {
+		return nil, nil
+	}
+	return invitation, nil
+}
+
+// JoinGame joins a game, with the invite code a private game needs
+func (c *Client) JoinGame(id game.Id, inviteCode string) error {
+	body := api.JoinBody{InviteCode: inviteCode}
+	return c.do("POST", fmt.Sprintf("/games/%d/join", id), nil, body, nil)
+}
+
+// JoinInvitation joins the game an invitation is for
+func (c *Client) JoinInvitation(code string) (game.Id, error) {
+	joined := api.JoinedResponse{}
+	err := c.do("POST", "/invitations/"+url.PathEscape(code)+"/join", nil, nil, &joined)
+	return joined.GameId, err
+}
+
+func (c *Client) Move(id game.Id, move game.AlgebraicMove) error {
+	body := api.MoveBody{Move: move}
+	return c.do("POST", fmt.Sprintf("/games/%d/move", id), nil, body, nil)
+}
+
+func (c *Client) OfferDraw(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/offerdraw", id), nil, nil, nil)
+}
+
+func (c *Client) RespondDrawOffer(id game.Id, accept bool) error {
+	body := api.AcceptBody{Accept: accept}
+	return c.do("POST", fmt.Sprintf("/games/%d/respondoffer", id), nil, body, nil)
+}
+
+func (c *Client) WithdrawDrawOffer(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/withdrawoffer", id), nil, nil, nil)
+}
+
+func (c *Client) Concede(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/concede", id), nil, nil, nil)
+}
+
+func (c *Client) Abort(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/abort", id), nil, nil, nil)
+}
+
+func (c *Client) ClaimTimeout(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/claimtimeout", id), nil, nil, nil)
+}
+
+func (c *Client) Berserk(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/berserk", id), nil, nil, nil)
+}
+
+func (c *Client) SendChat(id game.Id, message string) error {
+	body := api.ChatBody{Message: message}
+	return c.do("POST", fmt.Sprintf("/games/%d/chat", id), nil, body, nil)
+}
+
+// Annotate comments on a move of a finished game
+func (c *Client) Annotate(id game.Id, annotation api.AnnotationBody) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/annotate", id), nil, annotation, nil)
+}
+
+func (c *Client) OfferRematch(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/rematch", id), nil, nil, nil)
+}
+
+func (c *Client) RespondRematch(id game.Id, accept bool) error {
+	body := api.AcceptBody{Accept: accept}
+	return c.do("POST", fmt.Sprintf("/games/%d/respondrematch", id), nil, body, nil)
+}
+
+func (c *Client) RequestTakeback(id game.Id) error {
+	return c.do("POST", fmt.Sprintf("/games/%d/requesttakeback", id), nil, nil, nil)
+}
+
+func (c *Client) RespondTakeback(id game.Id, accept bool) error {
+	body := api.AcceptBody{Accept: accept}
+	return c.do("POST", fmt.Sprintf("/games/%d/respondtakeback", id), nil, body, nil)
+}
+
+// do sends a request and decodes its JSON response into out, if given
+func (c *Client) do(method, path string, query url.Values, body, out interface{}) error {
+	res, err := c.send(method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
+
+// send sends a request, turning those the API doesn't carry out into
+// errors
+func (c *Client) send(method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	u := c.BaseUrl + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return c.roundTrip(req)
+}
+
+func (c *Client) roundTrip(req *http.Request) (*http.Response, error) {
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 400 {
+		defer res.Body.Close()
+
+		refusal := api.ErrorResponse{}
+		json.NewDecoder(res.Body).Decode(&refusal)
+		if refusal.Code == "" {
+			refusal.Code = commands.InvalidRequest
+			refusal.Message = http.StatusText(res.StatusCode)
+		}
+		return nil, &Error{
+			StatusCode: res.StatusCode,
+			Code:       refusal.Code,
+			Message:    refusal.Message,
+			Details:    refusal.Details,
+		}
+	}
+
+	return res, nil
+}
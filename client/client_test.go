@@ -0,0 +1,195 @@
+package client
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"foodtastechess/commands"
+	"foodtastechess/conditionals"
+	"foodtastechess/game"
+	"foodtastechess/queries"
+	"foodtastechess/server/api"
+)
+
+type ClientTestSuite struct {
+	suite.Suite
+
+	server   *httptest.Server
+	client   *Client
+	requests []*http.Request
+}
+
+func (suite *ClientTestSuite) SetupTest() {
+	suite.requests = []*http.Request{}
+	suite.server = httptest.NewServer(http.HandlerFunc(
+		func(res http.ResponseWriter, req *http.Request) {
+			suite.requests = append(suite.requests, req)
+
+			switch {
+			case strings.HasSuffix(req.URL.Path, "/pgn"):
+				res.Header().Set("Content-Type", "application/x-chess-pgn")
+				fmt.Fprint(res, "1. e4 *\n")
+			case strings.HasSuffix(req.URL.Path, "/events/stream"):
+				res.Header().Set("Content-Type", "text/event-stream")
+				fmt.Fprint(res, ": keepalive\n\n")
+				fmt.Fprint(res, "id: 7\nevent: move\ndata: {\"GameId\":3,\"TurnNumber\":1,\"Move\":\"Pe2-e4\"}\n\n")
+			case strings.HasSuffix(req.URL.Path, "/move"):
+				res.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(res, `{"code":"not_your_turn","message":"It is not your turn.","details":{"move":"Pe2-e4"}}`)
+			case req.Method == "POST":
+				res.WriteHeader(http.StatusAccepted)
+				fmt.Fprint(res, `"ok"`)
+			default:
+				fmt.Fprint(res, "null")
+			}
+		},
+	))
+	suite.client = New(suite.server.URL+api.Prefix, nil)
+}
+
+func (suite *ClientTestSuite) TearDownTest() {
+	suite.server.Close()
+}
+
+// TestMatchesSpec calls every route through the client and checks each
+// request against the OpenAPI document
+func (suite *ClientTestSuite) TestMatchesSpec() {
+	assert := assert.New(suite.T())
+	c := suite.client
+
+	c.Spec()
+	c.Games()
+	c.Lobby(queries.LobbyFilter{Category: game.Blitz, Color: game.White})
+	c.Leaderboard(game.Blitz, 10)
+	c.Seeks()
+	c.Seek(api.SeekBody{TimeControl: game.TimeControl{Initial: 300}})
+	c.CancelSeek()
+	c.Tournaments()
+	c.Tournament(2)
+	c.CreateTournament(api.CreateTournamentBody{Name: "Spring"})
+	c.StartTournament(2)
+	c.LiveGames()
+	c.Game(3)
+	c.Wait(3, 4)
+	c.History(3)
+	c.ValidMoves(3)
+	c.Chat(3)
+	c.PGN(3)
+	c.Conditionals(3)
+	c.SetConditionals(3, []conditionals.Condition{{Move: "Pe7-e5", Reply: "Ng1-f3"}})
+	c.Premove(3)
+	c.SetPremove(3, "Pe7-e5")
+	c.User("someone")
+	c.RatingHistory("someone", game.Blitz)
+	c.PlayerStats("someone")
+	c.HeadToHead("someone", "someone else")
+	c.CreateGame(api.CreateGameBody{Color: game.White})
+	c.JoinGame(3, "")
+	c.JoinInvitation("abc123")
+	c.Move(3, "Pe2-e4")
+	c.OfferDraw(3)
+	c.RespondDrawOffer(3, true)
+	c.WithdrawDrawOffer(3)
+	c.Concede(3)
+	c.Abort(3)
+	c.ClaimTimeout(3)
+	c.Berserk(3)
+	c.SendChat(3, "good luck")
+	c.Annotate(3, api.AnnotationBody{TurnNumber: 1, NAG: "!"})
+	c.OfferRematch(3)
+	c.RespondRematch(3, false)
+	c.RequestTakeback(3)
+	c.RespondTakeback(3, true)
+	stream, err := c.Events(0)
+	assert.Nil(err)
+	stream.Close()
+
+	spec := api.Spec()
+	called := map[string]bool{}
+
+	for _, req := range suite.requests {
+		path := strings.TrimPrefix(req.URL.Path, api.Prefix)
+		method := strings.ToLower(req.Method)
+
+		// the route the API's router would serve the request with
+		template, routed := api.Route(req.Method, path)
+		operation := spec.Paths[template][method]
+		if !assert.True(routed, "%s %s is not routed", req.Method, path) ||
+			!assert.NotNil(operation, "%s %s is not in the spec", req.Method, path) {
+			continue
+		}
+		called[method+" "+template] = true
+
+		for name := range req.URL.Query() {
+			documented := false
+			for _, param := range operation.Parameters {
+				documented = documented || (param.In == "query" && param.Name == name)
+			}
+			assert.True(documented, "%s %s sends undocumented %s", req.Method, path, name)
+		}
+
+		if operation.RequestBody != nil {
+			assert.Equal("application/json", req.Header.Get("Content-Type"), path)
+		}
+	}
+
+	// the socket is left to websocket libraries, and the trailing slash
+	// only repeats /games/{id}
+	skipped := map[string]bool{
+		"get /games/{id}/ws": true,
+		"get /games/{id}/":   true,
+	}
+
+	for template, item := range spec.Paths {
+		for method := range item {
+			key := method + " " + template
+			assert.True(called[key] || skipped[key], "the client has nothing for %s", key)
+		}
+	}
+}
+
+func (suite *ClientTestSuite) TestResponses() {
+	assert := assert.New(suite.T())
+	c := suite.client
+
+	err := c.Move(3, "Pe2-e4")
+	assert.Equal(&Error{
+		StatusCode: http.StatusBadRequest,
+		Code:       commands.NotYourTurn,
+		Message:    "It is not your turn.",
+		Details:    map[string]interface{}{"move": "Pe2-e4"},
+	}, err)
+
+	err = c.Concede(3)
+	assert.Nil(err)
+
+	invitation, err := c.CreateGame(api.CreateGameBody{})
+	assert.Nil(err)
+	assert.Nil(invitation)
+
+	pgn, err := c.PGN(3)
+	assert.Nil(err)
+	assert.Equal("1. e4 *\n", pgn)
+
+	stream, err := c.Events(5)
+	assert.Nil(err)
+	defer stream.Close()
+	assert.Equal("5", suite.requests[len(suite.requests)-1].Header.Get("Last-Event-ID"))
+
+	event, err := stream.Next()
+	assert.Nil(err)
+	assert.Equal(7, event.Id)
+	assert.Equal("move", event.Type)
+	assert.Equal(game.Id(3), event.Data.GameId)
+	assert.Equal(game.AlgebraicMove("Pe2-e4"), event.Data.Move)
+	assert.Equal(7, stream.LastId)
+}
+
+func TestClientTestSuite(t *testing.T) {
+	suite.Run(t, new(ClientTestSuite))
+}
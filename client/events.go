@@ -0,0 +1,84 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"foodtastechess/server/api"
+)
+
+// Event is one of the server-sent events from the user's games
+type Event struct {
+	Id   int
+	Type string
+	Data api.StreamEvent
+}
+
+// EventStream reads the events in the user's games as they happen
+type EventStream struct {
+	res    *http.Response
+	reader *bufio.Reader
+
+	// the last event read, to resume from
+	LastId int
+}
+
+// Events opens the user's event stream. Given the id of the last event
+// read from an earlier stream, it first catches up on those missed.
+func (c *Client) Events(lastId int) (*EventStream, error) {
+	req, err := http.NewRequest("GET", c.BaseUrl+"/events/stream", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastId > 0 {
+		req.Header.Set("Last-Event-ID", strconv.Itoa(lastId))
+	}
+
+	res, err := c.roundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EventStream{res: res, reader: bufio.NewReader(res.Body), LastId: lastId}, nil
+}
+
+// Next waits for the next event
+func (s *EventStream) Next() (Event, error) {
+	event := Event{}
+	data := ""
+
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return event, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			// keepalives end without an event
+			if data == "" {
+				continue
+			}
+			err = json.Unmarshal([]byte(data), &event.Data)
+			s.LastId = event.Id
+			return event, err
+		case strings.HasPrefix(line, ":"):
+			continue
+		case strings.HasPrefix(line, "id: "):
+			event.Id, _ = strconv.Atoi(strings.TrimPrefix(line, "id: "))
+		case strings.HasPrefix(line, "event: "):
+			event.Type = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data += strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func (s *EventStream) Close() error {
+	return s.res.Body.Close()
+}
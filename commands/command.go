@@ -1,9 +1,13 @@
 package commands
 
 import (
+	"strings"
+	"unicode/utf8"
+
 	"foodtastechess/events"
 	"foodtastechess/game"
 	"foodtastechess/queries"
+	"foodtastechess/users"
 )
 
 var (
@@ -15,7 +19,7 @@ type command struct {
 	gen        eventGenerator
 }
 
-type validator func(ctx context, commands Commands) (bool, string)
+type validator func(ctx context, commands Commands) (bool, Refusal)
 
 type eventGenerator func(ctx context, commands Commands) []events.Event
 
@@ -29,18 +33,52 @@ func makeCommand(name string, cmd command) command {
 const CreateGame = "create_game"
 
 var createGameCommand = makeCommand(CreateGame, command{
-	validators: []validator{},
+	validators: []validator{
+		gameIdFree,
+		opponentValid,
+		timeControlValid,
+		visibilityValid,
+	},
+
 	gen: func(ctx context, commands Commands) []events.Event {
-		gameId := commands.events().NextGameId()
+		// services creating games for others pick the id up front so
+		// they can follow up on the game
+		gameId := ctx.gameId
+		if gameId == 0 {
+			gameId = commands.events().NextGameId()
+		}
+
+		var (
+			whiteId, blackId users.Id
+			creator          game.Color
+		)
 
 		if ctx.colorChoice == game.White {
-			return []events.Event{
-				events.NewGameCreateEvent(gameId, ctx.userId, ""),
-			}
+			whiteId, blackId = ctx.userId, ctx.opponentId
+			creator = game.White
 		} else {
-			return []events.Event{
-				events.NewGameCreateEvent(gameId, "", ctx.userId),
-			}
+			whiteId, blackId = ctx.opponentId, ctx.userId
+			creator = game.Black
+		}
+
+		var event events.Event
+		if ctx.opponentId != "" {
+			event = events.NewChallengeCreateEvent(gameId, whiteId, blackId, creator)
+		} else if ctx.inviteCode != "" {
+			event = events.NewPrivateGameCreateEvent(gameId, whiteId, blackId, ctx.inviteCode)
+		} else {
+			event = events.NewGameCreateEvent(gameId, whiteId, blackId)
+		}
+
+		visibility := ctx.visibility
+		if visibility == "" {
+			visibility = game.Public
+		}
+
+		return []events.Event{
+			event.
+				WithTimeControl(ctx.timeControl).
+				WithVisibility(visibility),
 		}
 	},
 })
@@ -51,7 +89,7 @@ var joinGameCommand = makeCommand(JoinGame, command{
 	validators: []validator{
 		gameExists,
 		gameNotStarted,
-		userNotPlaying,
+		userMayJoin,
 	},
 
 	gen: func(ctx context, commands Commands) []events.Event {
@@ -60,9 +98,10 @@ var joinGameCommand = makeCommand(JoinGame, command{
 		whiteId := gameInfo.White.Uuid
 		blackId := gameInfo.Black.Uuid
 
+		// a challenged player joins the seat that is already theirs
 		if whiteId == "" {
 			whiteId = ctx.userId
-		} else {
+		} else if blackId == "" {
 			blackId = ctx.userId
 		}
 
@@ -77,18 +116,18 @@ const Move = "move"
 var moveCommand = makeCommand(Move, command{
 	validators: []validator{
 		gameExists,
+		gameStarted,
 		gameNotEnded,
 		userPlaying,
 		userActive,
-		gameHasNoDrawOffer,
+		userHasTime,
+		gameHasNoTakebackRequest,
 		validMove,
 	},
 
 	gen: func(ctx context, commands Commands) []events.Event {
 		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
-		es := []events.Event{
-			events.NewMoveEvent(ctx.gameId, gameInfo.TurnNumber+1, ctx.move),
-		}
+		es := []events.Event{}
 
 		whiteId := gameInfo.White.Uuid
 		blackId := gameInfo.Black.Uuid
@@ -101,6 +140,13 @@ var moveCommand = makeCommand(Move, command{
 			player = game.Black
 		}
 
+		// moving instead of answering a draw offer declines it
+		if gameInfo.OutstandingDrawOffer && gameInfo.DrawOfferer != player {
+			es = append(es, events.NewDrawOfferResponseEvent(ctx.gameId, false))
+		}
+
+		es = append(es, events.NewMoveEvent(ctx.gameId, gameInfo.TurnNumber+1, ctx.move))
+
 		var lastChar = string(ctx.move)[len(ctx.move)-1:]
 
 		if lastChar == "#" {
@@ -149,6 +195,28 @@ var concedeCommand = makeCommand(Concede, command{
 	},
 })
 
+const Abort = "abort"
+
+var abortCommand = makeCommand(Abort, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameNotEnded,
+		gameAbortable,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+		return []events.Event{
+			events.NewGameAbortEvent(
+				ctx.gameId, ctx.userId,
+				gameInfo.White.Uuid, gameInfo.Black.Uuid,
+			),
+		}
+	},
+})
+
 const OfferDraw = "offer_draw"
 
 var offerDrawCommand = makeCommand(OfferDraw, command{
@@ -206,118 +274,633 @@ var drawOfferRespondCommand = makeCommand(DrawOfferRespond, command{
 	},
 })
 
+const RequestTakeback = "request_takeback"
+
+var requestTakebackCommand = makeCommand(RequestTakeback, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameStarted,
+		gameNotEnded,
+		gameHasNoTakebackRequest,
+		userHasMoveToTakeBack,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+		var requester game.Color
+		if ctx.userId == gameInfo.White.Uuid {
+			requester = game.White
+		} else {
+			requester = game.Black
+		}
+
+		return []events.Event{
+			events.NewTakebackRequestEvent(ctx.gameId, requester),
+		}
+	},
+})
+
+const TakebackRespond = "respond_takeback"
+
+var takebackRespondCommand = makeCommand(TakebackRespond, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameStarted,
+		gameNotEnded,
+		opponentRequestedTakeback,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		es := []events.Event{
+			events.NewTakebackResponseEvent(ctx.gameId, ctx.accept),
+		}
+
+		if !ctx.accept {
+			return es
+		}
+
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+		// If the requester has just moved, only their move is taken
+		// back. Otherwise their opponent has replied since, and both
+		// moves are taken back so that it is the requester's turn again.
+		plies := 1
+		if gameInfo.ActiveColor == gameInfo.TakebackRequester {
+			plies = 2
+		}
+
+		for i := 0; i < plies; i++ {
+			turnNumber := gameInfo.TurnNumber - game.TurnNumber(i)
+			es = append(es, events.NewMoveRetractEvent(ctx.gameId, turnNumber))
+		}
+
+		return es
+	},
+})
+
+const WithdrawDrawOffer = "withdraw_draw"
+
+var withdrawDrawOfferCommand = makeCommand(WithdrawDrawOffer, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameStarted,
+		gameNotEnded,
+		userOfferedDraw,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		return []events.Event{
+			events.NewDrawOfferWithdrawEvent(ctx.gameId),
+		}
+	},
+})
+
+const Rematch = "rematch"
+
+var rematchCommand = makeCommand(Rematch, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameEnded,
+		gameHadOpponent,
+		gameHasNoRematch,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+		rematchId := commands.events().NextGameId()
+
+		var offerer game.Color
+		if ctx.userId == gameInfo.White.Uuid {
+			offerer = game.White
+		} else {
+			offerer = game.Black
+		}
+
+		// colours are swapped for the rematch
+		return []events.Event{
+			events.NewRematchCreateEvent(
+				rematchId, ctx.gameId,
+				gameInfo.Black.Uuid, gameInfo.White.Uuid,
+			).
+				WithTimeControl(gameInfo.TimeControl).
+				WithVisibility(gameInfo.Visibility),
+			events.NewRematchOfferEvent(ctx.gameId, rematchId, offerer),
+		}
+	},
+})
+
+const RematchRespond = "respond_rematch"
+
+var rematchRespondCommand = makeCommand(RematchRespond, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameEnded,
+		opponentOfferedRematch,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+		rematchInfo, _ := commands.queries().GameInformation(gameInfo.RematchGameId)
+
+		whiteId := rematchInfo.White.Uuid
+		blackId := rematchInfo.Black.Uuid
+
+		es := []events.Event{
+			events.NewRematchResponseEvent(ctx.gameId, ctx.accept),
+		}
+
+		if ctx.accept {
+			es = append(es, events.NewGameStartEvent(rematchInfo.Id, whiteId, blackId))
+		} else {
+			es = append(es, events.NewGameEndEvent(
+				rematchInfo.Id, game.GameEndAborted, game.NoOne,
+				whiteId, blackId,
+			))
+		}
+
+		return es
+	},
+})
+
+const ClaimTimeout = "claim_timeout"
+
+var claimTimeoutCommand = makeCommand(ClaimTimeout, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameStarted,
+		gameNotEnded,
+		opponentOutOfTime,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+		winner := game.White
+		if ctx.userId == gameInfo.Black.Uuid {
+			winner = game.Black
+		}
+
+		return []events.Event{
+			events.NewGameEndEvent(
+				ctx.gameId, game.GameEndTimeout, winner,
+				gameInfo.White.Uuid, gameInfo.Black.Uuid,
+			),
+		}
+	},
+})
+
+const Berserk = "berserk"
+
+var berserkCommand = makeCommand(Berserk, command{
+	validators: []validator{
+		gameExists,
+		userPlaying,
+		gameStarted,
+		gameNotEnded,
+		gameTimed,
+		userMayBerserk,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+		color := game.White
+		if ctx.userId == gameInfo.Black.Uuid {
+			color = game.Black
+		}
+
+		return []events.Event{
+			events.NewBerserkEvent(ctx.gameId, color),
+		}
+	},
+})
+
+const Chat = "chat"
+
+// the longest chat message that may be sent, in characters
+const maxChatLength = 200
+
+var chatCommand = makeCommand(Chat, command{
+	validators: []validator{
+		gameExists,
+		userMayChat,
+		messageValid,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+		message := strings.TrimSpace(ctx.message)
+
+		// spectators talk amongst themselves, so they can't help the
+		// players along
+		if ctx.userId == gameInfo.White.Uuid || ctx.userId == gameInfo.Black.Uuid {
+			return []events.Event{
+				events.NewChatEvent(ctx.gameId, ctx.userId, message),
+			}
+		} else {
+			return []events.Event{
+				events.NewSpectatorChatEvent(ctx.gameId, ctx.userId, message),
+			}
+		}
+	},
+})
+
+const Annotate = "annotate"
+
+// the longest comment a move may be given, in characters
+const maxCommentLength = 2000
+
+var annotateCommand = makeCommand(Annotate, command{
+	validators: []validator{
+		gameExists,
+		gameEnded,
+		userPlaying,
+		turnPlayed,
+		turnNotAnnotated,
+		annotationValid,
+	},
+
+	gen: func(ctx context, commands Commands) []events.Event {
+		return []events.Event{
+			events.NewAnnotationEvent(
+				ctx.gameId, ctx.turnNumber, ctx.userId,
+				strings.TrimSpace(ctx.comment), ctx.nag,
+			),
+		}
+	},
+})
+
 // Validators!
 
-func gameExists(ctx context, commands Commands) (bool, string) {
+func gameExists(ctx context, commands Commands) (bool, Refusal) {
 	_, exists := commands.queries().GameInformation(ctx.gameId)
 
 	if !exists {
-		return false, "Game does not exist."
+		return false, errGameNotFound
 	} else {
-		return true, ""
+		return true, Refusal{}
 	}
 }
 
-func gameDoesNotExist(ctx context, commands Commands) (bool, string) {
+func gameDoesNotExist(ctx context, commands Commands) (bool, Refusal) {
 	_, exists := commands.queries().GameInformation(ctx.gameId)
 
 	if exists {
-		return false, "Game already exists."
+		return false, errGameExists
+	} else {
+		return true, Refusal{}
+	}
+}
+
+// gameIdFree lets services pick a new game's id, so long as no game has
+// it already
+func gameIdFree(ctx context, commands Commands) (bool, Refusal) {
+	if ctx.gameId == 0 {
+		return true, Refusal{}
 	} else {
-		return true, ""
+		return gameDoesNotExist(ctx, commands)
 	}
 }
 
-func gameStarted(ctx context, commands Commands) (bool, string) {
+func gameStarted(ctx context, commands Commands) (bool, Refusal) {
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
 	if gameInfo.GameStatus == queries.GameStatusCreated {
-		return false, "Game must have already started."
+		return false, errGameNotStarted
 	} else {
-		return true, ""
+		return true, Refusal{}
 	}
 }
 
-func gameNotStarted(ctx context, commands Commands) (bool, string) {
+func gameNotStarted(ctx context, commands Commands) (bool, Refusal) {
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
 	if gameInfo.GameStatus != queries.GameStatusCreated {
-		return false, "Game cannot have been started."
+		return false, errGameStarted
 	} else {
-		return true, ""
+		return true, Refusal{}
 	}
 }
 
-func gameNotEnded(ctx context, commands Commands) (bool, string) {
+func gameNotEnded(ctx context, commands Commands) (bool, Refusal) {
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
 	if gameInfo.GameStatus == queries.GameStatusEnded {
-		return false, "Game cannot have ended."
+		return false, errGameEnded
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func gameAbortable(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	// Before anyone joins, the only player is the game's creator. Once
+	// started, either player may abort until both sides have moved.
+	if gameInfo.GameStatus == queries.GameStatusStarted && gameInfo.TurnNumber >= 2 {
+		return false, errGameNotAbortable
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func gameEnded(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+	if gameInfo.GameStatus != queries.GameStatusEnded {
+		return false, errGameNotEnded
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func gameHasOpenSeat(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+	if gameInfo.White.Uuid != "" && gameInfo.Black.Uuid != "" {
+		return false, errGameFull
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func gameHadOpponent(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+	if gameInfo.White.Uuid == "" || gameInfo.Black.Uuid == "" {
+		return false, errGameNotJoined
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func gameHasNoRematch(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+	if gameInfo.RematchGameId == 0 {
+		return true, Refusal{}
+	}
+
+	// a rematch that was withdrawn can be offered again
+	rematchInfo, _ := commands.queries().GameInformation(gameInfo.RematchGameId)
+	if rematchInfo.GameStatus == queries.GameStatusEnded && rematchInfo.GameEndReason == game.GameEndAborted {
+		return true, Refusal{}
 	} else {
-		return true, ""
+		return false, errRematchOffered
 	}
 }
 
-func userPlaying(ctx context, commands Commands) (bool, string) {
+func opponentOfferedRematch(ctx context, commands Commands) (bool, Refusal) {
+	refusal := errNoRematchOffer
+
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+	if gameInfo.RematchGameId == 0 {
+		return false, refusal
+	}
 
+	var userColor game.Color
 	if ctx.userId == gameInfo.White.Uuid {
-		return true, ""
+		userColor = game.White
+	} else {
+		userColor = game.Black
+	}
+
+	if gameInfo.RematchOfferer == userColor {
+		return false, refusal
+	}
+
+	rematchInfo, _ := commands.queries().GameInformation(gameInfo.RematchGameId)
+	if rematchInfo.GameStatus != queries.GameStatusCreated {
+		return false, refusal
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func userPlaying(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if ctx.userId == gameInfo.White.Uuid {
+		return true, Refusal{}
 	} else if ctx.userId == gameInfo.Black.Uuid {
-		return true, ""
+		return true, Refusal{}
 	} else {
-		return false, "You are not playing in the game."
+		return false, errNotAPlayer
 	}
 }
 
-func userNotPlaying(ctx context, commands Commands) (bool, string) {
+func userNotPlaying(ctx context, commands Commands) (bool, Refusal) {
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
 
 	if ctx.userId == gameInfo.White.Uuid || ctx.userId == gameInfo.Black.Uuid {
-		return false, "You are already playing this game."
+		return false, errAlreadyPlaying
 	} else {
-		return true, ""
+		return true, Refusal{}
 	}
 }
 
-func userActive(ctx context, commands Commands) (bool, string) {
+func userMayJoin(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if gameInfo.Invitee != game.NoOne {
+		invitee := gameInfo.White.Uuid
+		if gameInfo.Invitee == game.Black {
+			invitee = gameInfo.Black.Uuid
+		}
+
+		if ctx.userId == invitee {
+			return true, Refusal{}
+		} else {
+			return false, errNotInvited
+		}
+	}
+
+	if ok, refusal := gameHasOpenSeat(ctx, commands); !ok {
+		return ok, refusal
+	}
+
+	if ok, refusal := userNotPlaying(ctx, commands); !ok {
+		return ok, refusal
+	}
+
+	if gameInfo.InviteCode != "" && ctx.inviteCode != gameInfo.InviteCode {
+		return false, errInviteCodeRequired
+	} else if gameInfo.InviteCode == "" && gameInfo.Visibility == game.Private {
+		return false, errGamePrivate
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func timeControlValid(ctx context, commands Commands) (bool, Refusal) {
+	return CheckTimeControl(ctx.timeControl)
+}
+
+// CheckTimeControl refuses time controls that games can't be created
+// with, for services that take one on before creating their games
+func CheckTimeControl(tc game.TimeControl) (bool, Refusal) {
+	if tc.Initial < 0 || tc.Increment < 0 {
+		return false, errTimeControlNegative
+	} else if tc.Initial > 3*60*60 || tc.Increment > 3*60 {
+		return false, errTimeControlTooLong
+	} else if tc.Initial == 0 && tc.Increment != 0 {
+		return false, errTimeControlNoInitial
+	} else {
+		return true, Refusal{}
+	}
+}
+
+// visibilityValid also keeps private games for the players meant to be
+// in them: one kept from spectators but open to anyone who joins would
+// show in nobody's lobby yet let strangers in
+func visibilityValid(ctx context, commands Commands) (bool, Refusal) {
+	switch ctx.visibility {
+	case "", game.Public:
+		return true, Refusal{}
+	case game.Private:
+		if ctx.opponentId == "" && ctx.inviteCode == "" {
+			return false, errPrivateNotInvited
+		}
+		return true, Refusal{}
+	default:
+		return false, errVisibilityInvalid
+	}
+}
+
+func userMayChat(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if !gameInfo.VisibleTo(ctx.userId) {
+		return false, errGamePrivate
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func messageValid(ctx context, commands Commands) (bool, Refusal) {
+	message := strings.TrimSpace(ctx.message)
+
+	if message == "" {
+		return false, errMessageEmpty
+	} else if utf8.RuneCountInString(message) > maxChatLength {
+		return false, errMessageTooLong
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func turnPlayed(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if ctx.turnNumber < 1 || ctx.turnNumber > gameInfo.TurnNumber {
+		return false, errTurnNotPlayed
+	} else {
+		return true, Refusal{}
+	}
+}
+
+// turnNotAnnotated keeps each move's annotation to one author, so the
+// other player can't overwrite it. Once cleared it is free again.
+func turnNotAnnotated(ctx context, commands Commands) (bool, Refusal) {
+	for _, annotation := range commands.queries().Annotations(ctx.gameId) {
+		if annotation.TurnNumber == ctx.turnNumber && annotation.AuthorId != ctx.userId {
+			return false, errTurnAnnotated
+		}
+	}
+	return true, Refusal{}
+}
+
+func annotationValid(ctx context, commands Commands) (bool, Refusal) {
+	if !ctx.nag.Valid() {
+		return false, errUnknownNAG
+	} else if utf8.RuneCountInString(strings.TrimSpace(ctx.comment)) > maxCommentLength {
+		return false, errCommentTooLong
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func opponentValid(ctx context, commands Commands) (bool, Refusal) {
+	if ctx.opponentId == "" {
+		return true, Refusal{}
+	}
+
+	if ctx.opponentId == ctx.userId {
+		return false, errChallengeSelf
+	}
+
+	_, found := commands.users().Get(ctx.opponentId)
+	if !found {
+		return false, errOpponentNotFound
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func userActive(ctx context, commands Commands) (bool, Refusal) {
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
 
 	if gameInfo.ActiveColor == game.White && gameInfo.White.Uuid == ctx.userId {
-		return true, ""
+		return true, Refusal{}
 	} else if gameInfo.ActiveColor == game.Black && gameInfo.Black.Uuid == ctx.userId {
-		return true, ""
+		return true, Refusal{}
 	} else {
-		return false, "It is not your turn."
+		return false, errNotYourTurn
 	}
 }
 
-func validMove(ctx context, commands Commands) (bool, string) {
+func validMove(ctx context, commands Commands) (bool, Refusal) {
 	validMoves, _ := commands.queries().ValidMoves(ctx.gameId)
 
 	for _, validMove := range validMoves {
 		if ctx.move == validMove.Move {
-			return true, ""
+			return true, Refusal{}
 		}
 	}
 
-	return false, "Invalid move"
+	return false, errIllegalMove
 }
 
-func gameHasNoDrawOffer(ctx context, commands Commands) (bool, string) {
+func gameHasNoDrawOffer(ctx context, commands Commands) (bool, Refusal) {
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
 
 	if gameInfo.OutstandingDrawOffer {
-		return false, "There is an outstanding draw offer."
+		return false, errDrawOfferPending
 	} else {
-		return true, ""
+		return true, Refusal{}
 	}
 }
 
-func opponentOfferedDraw(ctx context, commands Commands) (bool, string) {
-	msg := "Your opponent must have offered draw."
+func userOfferedDraw(ctx context, commands Commands) (bool, Refusal) {
+	refusal := errNoDrawOfferMade
 
 	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
 
 	if !gameInfo.OutstandingDrawOffer {
-		return false, msg
+		return false, refusal
+	}
+
+	var userColor game.Color
+	if ctx.userId == gameInfo.White.Uuid {
+		userColor = game.White
+	} else {
+		userColor = game.Black
+	}
+
+	if gameInfo.DrawOfferer != userColor {
+		return false, refusal
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func opponentOfferedDraw(ctx context, commands Commands) (bool, Refusal) {
+	refusal := errNoDrawOffer
+
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if !gameInfo.OutstandingDrawOffer {
+		return false, refusal
 	}
 
 	var userColor game.Color
@@ -328,9 +911,136 @@ func opponentOfferedDraw(ctx context, commands Commands) (bool, string) {
 	}
 
 	if gameInfo.DrawOfferer == userColor {
-		return false, msg
+		return false, refusal
+	} else {
+		return true, Refusal{}
+	}
+
+}
+
+func gameHasNoTakebackRequest(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if gameInfo.OutstandingTakeback {
+		return false, errTakebackPending
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func userHasMoveToTakeBack(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	var userColor game.Color
+	if ctx.userId == gameInfo.White.Uuid {
+		userColor = game.White
+	} else {
+		userColor = game.Black
+	}
+
+	// the user's last move is either the last move of the game, or the
+	// one before it if their opponent has since replied
+	lastMoveTurn := gameInfo.TurnNumber
+	if gameInfo.ActiveColor == userColor {
+		lastMoveTurn = gameInfo.TurnNumber - 1
+	}
+
+	if lastMoveTurn < 1 {
+		return false, errNoMoveToTakeBack
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func opponentRequestedTakeback(ctx context, commands Commands) (bool, Refusal) {
+	refusal := errNoTakebackRequest
+
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if !gameInfo.OutstandingTakeback {
+		return false, refusal
+	}
+
+	var userColor game.Color
+	if ctx.userId == gameInfo.White.Uuid {
+		userColor = game.White
 	} else {
-		return true, ""
+		userColor = game.Black
 	}
 
+	if gameInfo.TakebackRequester == userColor {
+		return false, refusal
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func gameTimed(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if gameInfo.Clock == nil {
+		return false, errGameUntimed
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func userHasTime(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	if gameInfo.Clock == nil {
+		return true, Refusal{}
+	}
+
+	remaining := gameInfo.Clock.White
+	if ctx.userId == gameInfo.Black.Uuid {
+		remaining = gameInfo.Clock.Black
+	}
+
+	if remaining <= 0 {
+		return false, errOutOfTime
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func opponentOutOfTime(ctx context, commands Commands) (bool, Refusal) {
+	refusal := errOpponentHasTime
+
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+	if gameInfo.Clock == nil {
+		return false, refusal
+	}
+
+	remaining := gameInfo.Clock.Black
+	if ctx.userId == gameInfo.Black.Uuid {
+		remaining = gameInfo.Clock.White
+	}
+
+	if remaining > 0 {
+		return false, refusal
+	} else {
+		return true, Refusal{}
+	}
+}
+
+func userMayBerserk(ctx context, commands Commands) (bool, Refusal) {
+	gameInfo, _ := commands.queries().GameInformation(ctx.gameId)
+
+	// berserking is only allowed before making a first move, white's
+	// being turn 1 and black's turn 2
+	berserk := gameInfo.Clock.WhiteBerserk
+	firstMove := game.TurnNumber(1)
+	if ctx.userId == gameInfo.Black.Uuid {
+		berserk = gameInfo.Clock.BlackBerserk
+		firstMove = 2
+	}
+
+	if berserk {
+		return false, errAlreadyBerserk
+	} else if gameInfo.TurnNumber >= firstMove {
+		return false, errBerserkTooLate
+	} else {
+		return true, Refusal{}
+	}
 }
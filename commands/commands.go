@@ -2,6 +2,8 @@ package commands
 
 import (
 	"fmt"
+	"github.com/satori/go.uuid"
+	"strings"
 
 	"foodtastechess/events"
 	"foodtastechess/game"
@@ -9,59 +11,75 @@ import (
 	"foodtastechess/users"
 )
 
-type Commands interface {
+// Executor runs commands on behalf of a user. Services that issue
+// commands depend on it rather than on Commands, so they can be tested
+// with a mock. A refused command comes back with the Refusal saying
+// why.
+type Executor interface {
 	ExecCommand(
 		name string, userId users.Id, params map[string]interface{},
-	) (bool, string)
+	) (bool, Refusal)
+}
+
+type Commands interface {
+	Executor
 
 	events() events.Events
 	queries() queries.ClientQueries
+	users() users.Users
 }
 
 type CommandsService struct {
 	Queries queries.ClientQueries `inject:"clientQueries"`
 	Events  events.Events         `inject:"events"`
+	Users   users.Users           `inject:"users"`
 }
 
 func New() Commands {
 	return new(CommandsService)
 }
 
-func (s *CommandsService) ExecCommand(name string, userId users.Id, params map[string]interface{}) (bool, string) {
+// NewInviteCode generates a code for a private game that is long enough
+// not to be guessed
+func NewInviteCode() string {
+	return strings.Replace(uuid.NewV4().String(), "-", "", -1)
+}
+
+func (s *CommandsService) ExecCommand(name string, userId users.Id, params map[string]interface{}) (bool, Refusal) {
 	var (
-		ctx context
-		cmd command
-		ok  bool
-		msg string
+		ctx     context
+		cmd     command
+		ok      bool
+		refusal Refusal
 	)
-	ctx, ok, msg = makeContext(name, userId, params)
+	ctx, ok, refusal = makeContext(name, userId, params)
 	if !ok {
-		return false, msg
+		return false, refusal
 	}
 
 	cmd, ok = commandMap[ctx.name]
 	if !ok {
-		return false, "Unknown Command"
+		return false, errUnknownCommand
 	}
 
 	for _, validator := range cmd.validators {
-		ok, msg = validator(ctx, s)
+		ok, refusal = validator(ctx, s)
 		if !ok {
-			return false, msg
+			return false, refusal
 		}
 	}
 
 	for _, event := range cmd.gen(ctx, s) {
 		err := s.Events.Receive(event)
 		if err != nil {
-			return false, fmt.Sprintf("Event Generation Error: %v", err)
+			return false, Refuse(InternalError, fmt.Sprintf("%s: %v", msgEventFailed, err))
 		}
 	}
 
-	return true, ""
+	return true, Refusal{}
 }
 
-func makeContext(name string, userId users.Id, params map[string]interface{}) (context, bool, string) {
+func makeContext(name string, userId users.Id, params map[string]interface{}) (context, bool, Refusal) {
 	ctx := new(context)
 
 	ctx.name = name
@@ -70,36 +88,93 @@ func makeContext(name string, userId users.Id, params map[string]interface{}) (c
 	if iface, ok := params["gameId"]; ok {
 		ctx.gameId, ok = iface.(game.Id)
 		if !ok {
-			return *ctx, false, "Invalid Game Id"
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Game Id")
 		}
 	}
 
 	if iface, ok := params["move"]; ok {
 		ctx.move, ok = iface.(game.AlgebraicMove)
 		if !ok {
-			return *ctx, false, "Invalid Move"
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Move")
 		}
 	}
 
 	if iface, ok := params["accept"]; ok {
 		ctx.accept, ok = iface.(bool)
 		if !ok {
-			return *ctx, false, "Accept must be a boolean"
+			return *ctx, false, Refuse(InvalidRequest, "Accept must be a boolean")
 		}
 	}
 
 	if iface, ok := params["color"]; ok {
 		ctx.colorChoice, ok = iface.(game.Color)
 		if !ok {
-			return *ctx, false, fmt.Sprintf(
+			return *ctx, false, Refuse(InvalidRequest, fmt.Sprintf(
 				"Invalid color. Must be %v or %v",
 				game.White, game.Black,
-			)
+			))
+		}
+	}
+
+	if iface, ok := params["opponent"]; ok {
+		ctx.opponentId, ok = iface.(users.Id)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Opponent")
+		}
+	}
+
+	if iface, ok := params["inviteCode"]; ok {
+		ctx.inviteCode, ok = iface.(string)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Invite Code")
+		}
+	}
+
+	if iface, ok := params["timeControl"]; ok {
+		ctx.timeControl, ok = iface.(game.TimeControl)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Time Control")
+		}
+	}
+
+	if iface, ok := params["visibility"]; ok {
+		ctx.visibility, ok = iface.(game.Visibility)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Visibility")
+		}
+	}
+
+	if iface, ok := params["message"]; ok {
+		ctx.message, ok = iface.(string)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Message")
+		}
+	}
+
+	if iface, ok := params["turnNumber"]; ok {
+		ctx.turnNumber, ok = iface.(game.TurnNumber)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Turn Number")
+		}
+	}
+
+	if iface, ok := params["comment"]; ok {
+		ctx.comment, ok = iface.(string)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Comment")
+		}
+	}
+
+	if iface, ok := params["nag"]; ok {
+		ctx.nag, ok = iface.(game.NAG)
+		if !ok {
+			return *ctx, false, Refuse(InvalidRequest, "Invalid Annotation Symbol")
 		}
 	}
 
-	return *ctx, true, ""
+	return *ctx, true, Refusal{}
 }
 
 func (s *CommandsService) events() events.Events          { return s.Events }
 func (s *CommandsService) queries() queries.ClientQueries { return s.Queries }
+func (s *CommandsService) users() users.Users             { return s.Users }
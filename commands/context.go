@@ -12,4 +12,12 @@ type context struct {
 	move        game.AlgebraicMove
 	colorChoice game.Color
 	accept      bool
+	opponentId  users.Id
+	inviteCode  string
+	timeControl game.TimeControl
+	visibility  game.Visibility
+	message     string
+	turnNumber  game.TurnNumber
+	comment     string
+	nag         game.NAG
 }
@@ -0,0 +1,140 @@
+package commands
+
+// Code names why a request was refused, so that clients can act on it
+// without reading messages meant for people. Codes are never reworded;
+// messages may be.
+type Code string
+
+// why commands are refused
+const (
+	GameNotFound       Code = "game_not_found"
+	GameExists         Code = "game_exists"
+	GameNotStarted     Code = "game_not_started"
+	GameStarted        Code = "game_started"
+	GameEnded          Code = "game_ended"
+	GameNotAbortable   Code = "game_not_abortable"
+	GameNotEnded       Code = "game_not_ended"
+	GameFull           Code = "game_full"
+	GameNotJoined      Code = "game_not_joined"
+	RematchOffered     Code = "rematch_offered"
+	NoRematchOffer     Code = "no_rematch_offer"
+	NotAPlayer         Code = "not_a_player"
+	AlreadyPlaying     Code = "already_playing"
+	NotInvited         Code = "not_invited"
+	InviteCodeRequired Code = "invite_code_required"
+	InvalidTimeControl Code = "invalid_time_control"
+	InvalidVisibility  Code = "invalid_visibility"
+	GamePrivate        Code = "game_private"
+	InvalidMessage     Code = "invalid_message"
+	InvalidTurn        Code = "invalid_turn"
+	TurnAnnotated      Code = "turn_annotated"
+	InvalidAnnotation  Code = "invalid_annotation"
+	InvalidOpponent    Code = "invalid_opponent"
+	NotYourTurn        Code = "not_your_turn"
+	IllegalMove        Code = "illegal_move"
+	DrawOfferPending   Code = "draw_offer_pending"
+	NoDrawOffer        Code = "no_draw_offer"
+	TakebackPending    Code = "takeback_pending"
+	NoMoveToTakeBack   Code = "no_move_to_take_back"
+	NoTakebackRequest  Code = "no_takeback_request"
+	GameUntimed        Code = "game_untimed"
+	OutOfTime          Code = "out_of_time"
+	OpponentHasTime    Code = "opponent_has_time"
+	AlreadyBerserk     Code = "already_berserk"
+	BerserkTooLate     Code = "berserk_too_late"
+	UnknownCommand     Code = "unknown_command"
+)
+
+// why other requests are refused
+const (
+	InvalidRequest     Code = "invalid_request"
+	NotFound           Code = "not_found"
+	UserNotFound       Code = "user_not_found"
+	TournamentNotFound Code = "tournament_not_found"
+	InternalError      Code = "internal_error"
+	UnsupportedVariant Code = "unsupported_variant"
+	InvalidSeek        Code = "invalid_seek"
+	InvalidTournament  Code = "invalid_tournament"
+	NotOrganizer       Code = "not_organizer"
+	TournamentStarted  Code = "tournament_started"
+	GameNotInProgress  Code = "game_not_in_progress"
+	YourTurn           Code = "your_turn"
+	NotCorrespondence  Code = "not_correspondence"
+	NotLive            Code = "not_live"
+	TooManyConditions  Code = "too_many_conditions"
+)
+
+// what validators refuse commands with
+var (
+	errGameNotFound         = Refuse(GameNotFound, "Game does not exist.")
+	errGameExists           = Refuse(GameExists, "Game already exists.")
+	errGameNotStarted       = Refuse(GameNotStarted, "Game must have already started.")
+	errGameStarted          = Refuse(GameStarted, "Game cannot have been started.")
+	errGameEnded            = Refuse(GameEnded, "Game cannot have ended.")
+	errGameNotAbortable     = Refuse(GameNotAbortable, "Game can only be aborted before each side has moved.")
+	errGameNotEnded         = Refuse(GameNotEnded, "Game must have ended.")
+	errGameFull             = Refuse(GameFull, "Game has no open seat.")
+	errGameNotJoined        = Refuse(GameNotJoined, "Game was never joined.")
+	errRematchOffered       = Refuse(RematchOffered, "A rematch has already been offered.")
+	errNoRematchOffer       = Refuse(NoRematchOffer, "Your opponent must have offered a rematch.")
+	errNotAPlayer           = Refuse(NotAPlayer, "You are not playing in the game.")
+	errAlreadyPlaying       = Refuse(AlreadyPlaying, "You are already playing this game.")
+	errNotInvited           = Refuse(NotInvited, "You were not invited to this game.")
+	errInviteCodeRequired   = Refuse(InviteCodeRequired, "This game can only be joined with its invite code.")
+	errTimeControlNegative  = Refuse(InvalidTimeControl, "Time control cannot be negative.")
+	errTimeControlTooLong   = Refuse(InvalidTimeControl, "Time control is too long.")
+	errTimeControlNoInitial = Refuse(InvalidTimeControl, "Timed games need some initial time.")
+	errVisibilityInvalid    = Refuse(InvalidVisibility, "Visibility must be public or private.")
+	errPrivateNotInvited    = Refuse(InvalidVisibility, "Private games need an opponent or an invite code.")
+	errGamePrivate          = Refuse(GamePrivate, "This game is private.")
+	errMessageEmpty         = Refuse(InvalidMessage, "Message cannot be empty.")
+	errMessageTooLong       = Refuse(InvalidMessage, "Message is too long.")
+	errTurnNotPlayed        = Refuse(InvalidTurn, "No move was made at that turn.")
+	errTurnAnnotated        = Refuse(TurnAnnotated, "Your opponent has already annotated that move.")
+	errUnknownNAG           = Refuse(InvalidAnnotation, "Unknown annotation symbol.")
+	errCommentTooLong       = Refuse(InvalidAnnotation, "Comment is too long.")
+	errChallengeSelf        = Refuse(InvalidOpponent, "You cannot challenge yourself.")
+	errOpponentNotFound     = Refuse(InvalidOpponent, "Opponent does not exist.")
+	errNotYourTurn          = Refuse(NotYourTurn, "It is not your turn.")
+	errIllegalMove          = Refuse(IllegalMove, "Invalid move")
+	errDrawOfferPending     = Refuse(DrawOfferPending, "There is an outstanding draw offer.")
+	errNoDrawOfferMade      = Refuse(NoDrawOffer, "You must have offered draw.")
+	errNoDrawOffer          = Refuse(NoDrawOffer, "Your opponent must have offered draw.")
+	errTakebackPending      = Refuse(TakebackPending, "There is an outstanding takeback request.")
+	errNoMoveToTakeBack     = Refuse(NoMoveToTakeBack, "You have no move to take back.")
+	errNoTakebackRequest    = Refuse(NoTakebackRequest, "Your opponent must have requested a takeback.")
+	errGameUntimed          = Refuse(GameUntimed, "Game is not timed.")
+	errOutOfTime            = Refuse(OutOfTime, "Your time has run out.")
+	errOpponentHasTime      = Refuse(OpponentHasTime, "Your opponent still has time.")
+	errAlreadyBerserk       = Refuse(AlreadyBerserk, "You have already gone berserk.")
+	errBerserkTooLate       = Refuse(BerserkTooLate, "You can only go berserk before your first move.")
+	errUnknownCommand       = Refuse(UnknownCommand, "Unknown Command")
+)
+
+// followed by what went wrong when events can't be stored
+const msgEventFailed = "Event Generation Error"
+
+// Refusal is an error with a code, for services refusing requests
+type Refusal struct {
+	Code    Code
+	Message string
+	Details map[string]interface{}
+}
+
+func (r Refusal) Error() string {
+	return r.Message
+}
+
+func Refuse(code Code, message string) Refusal {
+	return Refusal{Code: code, Message: message}
+}
+
+// WithDetail adds something that helps explain the refusal
+func (r Refusal) WithDetail(name string, value interface{}) Refusal {
+	details := map[string]interface{}{name: value}
+	for k, v := range r.Details {
+		details[k] = v
+	}
+	r.Details = details
+	return r
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type RefusalTestSuite struct {
+	suite.Suite
+}
+
+func TestRefusalTestSuite(t *testing.T) {
+	suite.Run(t, new(RefusalTestSuite))
+}
+
+// TestExecCommand tests that refused commands come back with their code
+func (s *RefusalTestSuite) TestExecCommand() {
+	assert := assert.New(s.T())
+	commands := New()
+
+	ok, refusal := commands.ExecCommand(CreateGame, "alice", map[string]interface{}{
+		"opponent": users.Id("alice"),
+	})
+	assert.Equal(false, ok)
+	assert.Equal(InvalidOpponent, refusal.Code)
+	assert.Equal("You cannot challenge yourself.", refusal.Message)
+
+	ok, refusal = commands.ExecCommand("castle", "alice", map[string]interface{}{})
+	assert.Equal(false, ok)
+	assert.Equal(UnknownCommand, refusal.Code)
+
+	// bad parameters are the caller's fault
+	ok, refusal = commands.ExecCommand(Move, "alice", map[string]interface{}{
+		"gameId": "five",
+	})
+	assert.Equal(false, ok)
+	assert.Equal(InvalidRequest, refusal.Code)
+	assert.Equal("Invalid Game Id", refusal.Message)
+}
+
+func (s *RefusalTestSuite) TestCheckTimeControl() {
+	assert := assert.New(s.T())
+
+	ok, _ := CheckTimeControl(game.TimeControl{Initial: 300, Increment: 2})
+	assert.Equal(true, ok)
+	ok, _ = CheckTimeControl(game.TimeControl{})
+	assert.Equal(true, ok)
+
+	for _, tc := range []game.TimeControl{
+		{Initial: -1},
+		{Initial: 4 * 60 * 60},
+		{Initial: 300, Increment: 4 * 60},
+		{Increment: 2},
+	} {
+		ok, refusal := CheckTimeControl(tc)
+		assert.Equal(false, ok)
+		assert.Equal(InvalidTimeControl, refusal.Code)
+	}
+}
+
+func (s *RefusalTestSuite) TestRefusal() {
+	assert := assert.New(s.T())
+
+	var err error = Refuse(IllegalMove, "Pe2-e5 is not a legal move.").
+		WithDetail("move", "Pe2-e5")
+
+	refusal, ok := err.(Refusal)
+	assert.True(ok)
+	assert.Equal(IllegalMove, refusal.Code)
+	assert.Equal("Pe2-e5 is not a legal move.", err.Error())
+	assert.Equal(map[string]interface{}{"move": "Pe2-e5"}, refusal.Details)
+}
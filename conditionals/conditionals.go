@@ -0,0 +1,389 @@
+package conditionals
+
+import (
+	"fmt"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/op/go-logging"
+	"sync"
+
+	"foodtastechess/commands"
+	"foodtastechess/config"
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/logger"
+	"foodtastechess/queries"
+	"foodtastechess/users"
+)
+
+var tablePrefix string = ""
+
+// the most conditions a plan may hold
+const maxConditions = 200
+
+// Conditionals plays the moves players have planned in correspondence
+// games: once the opponent makes a move the player has a condition for,
+// its reply is made for them. A plan is dropped as soon as the opponent
+// plays a move it doesn't answer.
+//
+// In live games a player may instead queue a single premove, played
+// straight after the opponent's move if it is legal then.
+type Conditionals interface {
+	events.EventSubscriber
+
+	Submit(gameId game.Id, userId users.Id, conditions []Condition) error
+	Conditions(gameId game.Id, userId users.Id) []Condition
+
+	Premove(gameId game.Id, userId users.Id, move game.AlgebraicMove) error
+	QueuedPremove(gameId game.Id, userId users.Id) (game.AlgebraicMove, bool)
+}
+
+type ConditionalsService struct {
+	Config     config.DatabaseConfig `inject:"databaseConfig"`
+	Commands   commands.Executor     `inject:"commands"`
+	Queries    queries.ClientQueries `inject:"clientQueries"`
+	Calculator game.GameCalculator   `inject:"gameCalculator"`
+	Publisher  events.EventPublisher `inject:"eventSubscriber"`
+
+	log      *logging.Logger
+	db       gorm.DB
+	events   *events.Queue
+	stopChan chan bool
+
+	// held while plans are changing
+	lock sync.Mutex
+}
+
+func New() Conditionals {
+	s := new(ConditionalsService)
+	s.log = logger.Log("conditionals")
+	s.events = events.NewQueue()
+	s.stopChan = make(chan bool, 1)
+	return s
+}
+
+func (s *ConditionalsService) PostPopulate() error {
+	// hook for test-suite, make a global table prefix if our config
+	// defines it
+	tablePrefix = s.Config.Prefix
+
+	dsn := fmt.Sprintf(
+		"%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True",
+		s.Config.Username, s.Config.Password,
+		s.Config.HostAddr, s.Config.Port,
+		s.Config.Database,
+	)
+
+	db, err := gorm.Open("mysql", dsn)
+
+	db.LogMode(true)
+	db.AutoMigrate(&Plan{})
+
+	s.db = db
+
+	// hear about moves once queries have caught up with them, so the
+	// replies made to them are checked against the right board
+	s.Publisher.Subscribe(s)
+
+	return err
+}
+
+func (s *ConditionalsService) Start() error {
+	s.log.Notice("Running conditional moves")
+	go s.Process()
+	return nil
+}
+
+// Process handles events away from the query buffer, since replying to
+// a move issues a command whose event goes back through it. Events are
+// handled one at a time as they arrive, so the moves of a game are
+// answered in the order they were made.
+func (s *ConditionalsService) Process() {
+	for {
+		select {
+		case <-s.events.Ready():
+			for _, event := range s.events.Pop() {
+				s.handle(event)
+			}
+		case <-s.stopChan:
+			s.log.Info("Conditional moves stopped")
+			return
+		}
+	}
+}
+
+func (s *ConditionalsService) Stop() error {
+	s.log.Notice("Stopping conditional moves")
+	s.stopChan <- true
+	return nil
+}
+
+func (s *ConditionalsService) Receive(event events.Event) error {
+	switch event.Type {
+	case events.MoveType, events.MoveRetractType, events.GameEndType:
+		s.events.Push(event)
+	}
+	return nil
+}
+
+// Submit replaces the conditions a player has left in a game. Each must
+// be legal from the board as it stands, and no conditions clears them.
+func (s *ConditionalsService) Submit(gameId game.Id, userId users.Id, conditions []Condition) error {
+	gameInfo, err := s.waiting(gameId, userId)
+	if err != nil {
+		return err
+	}
+
+	if !gameInfo.TimeControl.Untimed() {
+		return commands.Refuse(commands.NotCorrespondence, "Conditional moves are only for correspondence games.")
+	}
+
+	if count(conditions) > maxConditions {
+		return commands.Refuse(
+			commands.TooManyConditions,
+			fmt.Sprintf("A plan can hold at most %d conditions.", maxConditions),
+		)
+	}
+
+	checked, err := s.check(gameInfo.BoardState, conditions)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	plan := s.plan(gameId, userId)
+	if len(checked) == 0 {
+		s.drop(plan)
+		return nil
+	}
+
+	plan.GameId = gameId
+	plan.UserId = userId
+	plan.TurnNumber = gameInfo.TurnNumber + 1
+	plan.SetConditions(checked)
+	s.db.Save(&plan)
+
+	return nil
+}
+
+// Premove queues the move a player will make once their opponent has
+// moved, replacing any already queued. An empty move clears it.
+func (s *ConditionalsService) Premove(gameId game.Id, userId users.Id, move game.AlgebraicMove) error {
+	gameInfo, err := s.waiting(gameId, userId)
+	if err != nil {
+		return err
+	}
+
+	if gameInfo.TimeControl.Untimed() {
+		return commands.Refuse(commands.NotLive, "Premoves are only for live games.")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	plan := s.plan(gameId, userId)
+	if move == "" {
+		s.drop(plan)
+		return nil
+	}
+
+	plan.GameId = gameId
+	plan.UserId = userId
+	plan.TurnNumber = gameInfo.TurnNumber + 1
+	plan.Premove = move
+	s.db.Save(&plan)
+
+	return nil
+}
+
+// QueuedPremove is the move a player will make once their opponent has
+// moved, if they have queued one
+func (s *ConditionalsService) QueuedPremove(gameId game.Id, userId users.Id) (game.AlgebraicMove, bool) {
+	plan := s.plan(gameId, userId)
+	return plan.Premove, plan.Premove != ""
+}
+
+// waiting finds a game the user is playing in, where it is their
+// opponent's move
+func (s *ConditionalsService) waiting(gameId game.Id, userId users.Id) (queries.GameInformation, error) {
+	gameInfo, found := s.Queries.GameInformation(gameId)
+	if !found {
+		return gameInfo, commands.Refuse(commands.GameNotFound, "Game does not exist.")
+	}
+
+	var color game.Color
+	if userId == gameInfo.White.Uuid {
+		color = game.White
+	} else if userId == gameInfo.Black.Uuid {
+		color = game.Black
+	} else {
+		return gameInfo, commands.Refuse(commands.NotAPlayer, "You are not playing in the game.")
+	}
+
+	if gameInfo.GameStatus != queries.GameStatusStarted {
+		return gameInfo, commands.Refuse(commands.GameNotInProgress, "Game must be in progress.")
+	}
+
+	if gameInfo.ActiveColor == color {
+		return gameInfo, commands.Refuse(commands.YourTurn, "It is your move, so make it now.")
+	}
+
+	return gameInfo, nil
+}
+
+// check walks the conditions from a board, returning them with their
+// moves as they would be stored
+func (s *ConditionalsService) check(board game.FEN, conditions []Condition) ([]Condition, error) {
+	var checked []Condition
+
+	for _, condition := range conditions {
+		if _, seen := answer(checked, condition.Move); seen {
+			return nil, commands.Refuse(
+				commands.InvalidRequest,
+				fmt.Sprintf("%v is answered more than once.", condition.Move),
+			).WithDetail("move", condition.Move)
+		}
+
+		move, ok := legal(s.Calculator.ValidMoves(board), condition.Move)
+		if !ok {
+			return nil, commands.Refuse(
+				commands.IllegalMove,
+				fmt.Sprintf("%v is not a legal move.", condition.Move),
+			).WithDetail("move", condition.Move)
+		}
+
+		afterMove := s.Calculator.AfterMove(board, move)
+		reply, ok := legal(s.Calculator.ValidMoves(afterMove), condition.Reply)
+		if !ok {
+			return nil, commands.Refuse(
+				commands.IllegalMove,
+				fmt.Sprintf("%v is not a legal reply to %v.", condition.Reply, condition.Move),
+			).WithDetail("move", condition.Reply)
+		}
+
+		then, err := s.check(s.Calculator.AfterMove(afterMove, reply), condition.Then)
+		if err != nil {
+			return nil, err
+		}
+
+		checked = append(checked, Condition{Move: move, Reply: reply, Then: then})
+	}
+
+	return checked, nil
+}
+
+// Conditions are what a player has left in a game
+func (s *ConditionalsService) Conditions(gameId game.Id, userId users.Id) []Condition {
+	plan := s.plan(gameId, userId)
+	if plan.Id == 0 || plan.Premove != "" {
+		return []Condition{}
+	}
+	return plan.Conditions()
+}
+
+func (s *ConditionalsService) handle(event events.Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	plans := []Plan{}
+	s.db.Where(&Plan{GameId: event.GameId}).Find(&plans)
+
+	for _, plan := range plans {
+		// a takeback or the end of the game leaves nothing to answer
+		if event.Type != events.MoveType || plan.TurnNumber < event.TurnNumber {
+			s.drop(plan)
+			continue
+		}
+
+		if plan.TurnNumber != event.TurnNumber {
+			continue
+		}
+
+		if plan.Premove != "" {
+			s.drop(plan)
+			s.premove(plan)
+			continue
+		}
+
+		condition, ok := answer(plan.Conditions(), event.Move)
+		if !ok {
+			s.log.Info("Opponent deviated from plan in game %v", event.GameId)
+			s.drop(plan)
+			continue
+		}
+
+		if len(condition.Then) == 0 {
+			s.drop(plan)
+		} else {
+			plan.TurnNumber = event.TurnNumber + 2
+			plan.SetConditions(condition.Then)
+			s.db.Save(&plan)
+		}
+
+		ok, refusal := s.Commands.ExecCommand(
+			commands.Move, plan.UserId, map[string]interface{}{
+				"gameId": plan.GameId,
+				"move":   condition.Reply,
+			},
+		)
+		if !ok {
+			s.log.Warning("Could not make conditional move in game %v: %s", plan.GameId, refusal)
+			s.drop(plan)
+		}
+	}
+}
+
+// premove plays a queued premove, if it is legal now the opponent has
+// moved
+func (s *ConditionalsService) premove(plan Plan) {
+	validMoves, found := s.Queries.ValidMoves(plan.GameId)
+	if !found {
+		return
+	}
+
+	valid := []game.AlgebraicMove{}
+	for _, record := range validMoves {
+		valid = append(valid, record.Move)
+	}
+
+	move, ok := legal(valid, plan.Premove)
+	if !ok {
+		s.log.Info("Discarding illegal premove %v in game %v", plan.Premove, plan.GameId)
+		return
+	}
+
+	ok, refusal := s.Commands.ExecCommand(
+		commands.Move, plan.UserId, map[string]interface{}{
+			"gameId": plan.GameId,
+			"move":   move,
+		},
+	)
+	if !ok {
+		s.log.Warning("Could not make premove in game %v: %s", plan.GameId, refusal)
+	}
+}
+
+func (s *ConditionalsService) plan(gameId game.Id, userId users.Id) Plan {
+	plan := Plan{}
+	s.db.Where(&Plan{GameId: gameId, UserId: userId}).First(&plan)
+	return plan
+}
+
+func (s *ConditionalsService) drop(plan Plan) {
+	if plan.Id != 0 {
+		s.db.Delete(&plan)
+	}
+}
+
+func (s *ConditionalsService) ResetTestDB() {
+	if tablePrefix != "test_" {
+		s.log.Error(
+			"Cannot reset a database not configured with ConfigTestProvider",
+		)
+		return
+	}
+	s.db.DropTable(&Plan{})
+	s.db.AutoMigrate(&Plan{})
+}
@@ -0,0 +1,129 @@
+package conditionals
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/game"
+)
+
+type ConditionalsTestSuite struct {
+	suite.Suite
+
+	service *ConditionalsService
+	board   game.FEN
+}
+
+func (suite *ConditionalsTestSuite) SetupTest() {
+	suite.service = New().(*ConditionalsService)
+	suite.service.Calculator = game.NewGameCalculator()
+
+	// white has opened and waits on black
+	suite.board = game.AfterMove("Pe2-e4", game.InitializeFEN())
+}
+
+func (suite *ConditionalsTestSuite) TestCheck() {
+	conditions := []Condition{
+		{
+			Move:  "Pe7-e5",
+			Reply: "Ng1-f3",
+			Then: []Condition{
+				{Move: "Nb8-c6", Reply: "Bf1-b5"},
+			},
+		},
+		{Move: "Pc7-c5", Reply: "Ng1-f3"},
+	}
+
+	checked, err := suite.service.check(suite.board, conditions)
+
+	assert := assert.New(suite.T())
+	assert.Nil(err)
+	assert.Equal(conditions, checked)
+}
+
+func (suite *ConditionalsTestSuite) TestCheckIllegal() {
+	assert := assert.New(suite.T())
+
+	_, err := suite.service.check(suite.board, []Condition{
+		{Move: "Pe7-e4", Reply: "Ng1-f3"},
+	})
+	assert.Equal("Pe7-e4 is not a legal move.", err.Error())
+
+	_, err = suite.service.check(suite.board, []Condition{
+		{Move: "Pe7-e5", Reply: "Pe4-e5"},
+	})
+	assert.Equal("Pe4-e5 is not a legal reply to Pe7-e5.", err.Error())
+
+	// replies further down are checked against the board they'd be
+	// made on
+	_, err = suite.service.check(suite.board, []Condition{
+		{
+			Move:  "Pe7-e5",
+			Reply: "Ng1-f3",
+			Then:  []Condition{{Move: "Nb8-c6", Reply: "Ng1-f3"}},
+		},
+	})
+	assert.Equal("Ng1-f3 is not a legal reply to Nb8-c6.", err.Error())
+
+	_, err = suite.service.check(suite.board, []Condition{
+		{Move: "Pe7-e5", Reply: "Ng1-f3"},
+		{Move: "Pe7-e5", Reply: "Bf1-c4"},
+	})
+	assert.Equal("Pe7-e5 is answered more than once.", err.Error())
+}
+
+func (suite *ConditionalsTestSuite) TestAnswer() {
+	conditions := []Condition{
+		{Move: "Pe7-e5", Reply: "Ng1-f3"},
+		{Move: "Qd8-h4+", Reply: "Pg2-g3"},
+	}
+
+	assert := assert.New(suite.T())
+
+	condition, ok := answer(conditions, "Pe7-e5")
+	assert.Equal(true, ok)
+	assert.Equal(game.AlgebraicMove("Ng1-f3"), condition.Reply)
+
+	// markers for check don't need to agree
+	condition, ok = answer(conditions, "Qd8-h4")
+	assert.Equal(true, ok)
+	assert.Equal(game.AlgebraicMove("Pg2-g3"), condition.Reply)
+
+	_, ok = answer(conditions, "Pd7-d5")
+	assert.Equal(false, ok)
+}
+
+func (suite *ConditionalsTestSuite) TestLegal() {
+	valid := []game.AlgebraicMove{"Pe2-e4", "Qd1xf7#"}
+
+	assert := assert.New(suite.T())
+
+	move, ok := legal(valid, "Qd1xf7")
+	assert.Equal(true, ok)
+	assert.Equal(game.AlgebraicMove("Qd1xf7#"), move)
+
+	_, ok = legal(valid, "Pe2-e3")
+	assert.Equal(false, ok)
+}
+
+func (suite *ConditionalsTestSuite) TestPlanConditions() {
+	conditions := []Condition{
+		{
+			Move:  "Pe7-e5",
+			Reply: "Ng1-f3",
+			Then:  []Condition{{Move: "Nb8-c6", Reply: "Bf1-b5"}},
+		},
+	}
+
+	plan := Plan{}
+	plan.SetConditions(conditions)
+
+	assert := assert.New(suite.T())
+	assert.Equal(conditions, plan.Conditions())
+	assert.Equal(2, count(conditions))
+}
+
+func TestConditionalsTestSuite(t *testing.T) {
+	suite.Run(t, new(ConditionalsTestSuite))
+}
@@ -0,0 +1,87 @@
+package conditionals
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+// Condition answers the opponent playing Move with Reply, after which
+// the conditions in Then may answer their next move
+type Condition struct {
+	Move  game.AlgebraicMove
+	Reply game.AlgebraicMove
+	Then  []Condition `json:",omitempty"`
+}
+
+// Plan is the conditions a player has left in a game, or the premove
+// they have queued, for the opponent's move at TurnNumber
+type Plan struct {
+	Id         int
+	GameId     game.Id  `sql:"index"`
+	UserId     users.Id `sql:"index"`
+	TurnNumber game.TurnNumber
+
+	// the conditions, kept as JSON
+	Tree string `sql:"type:text"`
+
+	// played whatever the opponent's move, if it is still legal
+	Premove game.AlgebraicMove
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (p Plan) TableName() string {
+	return fmt.Sprintf("%sconditional_moves", tablePrefix)
+}
+
+func (p Plan) Conditions() []Condition {
+	conditions := []Condition{}
+	json.Unmarshal([]byte(p.Tree), &conditions)
+	return conditions
+}
+
+func (p *Plan) SetConditions(conditions []Condition) {
+	tree, _ := json.Marshal(conditions)
+	p.Tree = string(tree)
+}
+
+// answer finds the condition answering a move
+func answer(conditions []Condition, move game.AlgebraicMove) (Condition, bool) {
+	for _, condition := range conditions {
+		if bare(condition.Move) == bare(move) {
+			return condition, true
+		}
+	}
+	return Condition{}, false
+}
+
+// count is how many conditions there are, all the way down
+func count(conditions []Condition) int {
+	n := len(conditions)
+	for _, condition := range conditions {
+		n += count(condition.Then)
+	}
+	return n
+}
+
+// bare strips the check, mate and stalemate markers moves are stored
+// with, so players needn't work them out
+func bare(move game.AlgebraicMove) game.AlgebraicMove {
+	return game.AlgebraicMove(strings.TrimRight(string(move), "+#S"))
+}
+
+// legal finds a move amongst the valid ones, as it would be stored
+func legal(valid []game.AlgebraicMove, move game.AlgebraicMove) (game.AlgebraicMove, bool) {
+	for _, v := range valid {
+		if bare(v) == bare(move) {
+			return v, true
+		}
+	}
+	return "", false
+}
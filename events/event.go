@@ -22,6 +22,30 @@ type Event struct {
 	Reason      game.GameEndReason
 	Winner      game.Color
 
+	// LinkedGameId refers a rematch offer to the game created for it,
+	// and the rematch game back to the game it follows
+	LinkedGameId game.Id `sql:"index"`
+
+	// InviteCode restricts who may join a private game to those who
+	// were given the code
+	InviteCode string `sql:"index"`
+
+	// the clock a created game is played with, in seconds
+	TimeInitial   int
+	TimeIncrement int
+
+	// the user who sent a chat message, annotated a move or aborted a
+	// game, and what they said
+	AuthorId users.Id
+	Message  string `sql:"type:text"`
+
+	// the judgement an annotation makes of a move
+	Glyph game.NAG
+
+	// who may view a created game. Games created before visibility
+	// existed leave it empty, and are public.
+	Visibility game.Visibility
+
 	CreatedAt time.Time
 }
 
@@ -29,6 +53,29 @@ func (e Event) TableName() string {
 	return fmt.Sprintf("%sevents", tablePrefix)
 }
 
+// TimeControl is the clock a game was created with
+func (e Event) TimeControl() game.TimeControl {
+	return game.TimeControl{
+		Initial:   e.TimeInitial,
+		Increment: e.TimeIncrement,
+	}
+}
+
+// WithTimeControl returns a copy of a game create event that sets the
+// game's clock
+func (e Event) WithTimeControl(tc game.TimeControl) Event {
+	e.TimeInitial = tc.Initial
+	e.TimeIncrement = tc.Increment
+	return e
+}
+
+// WithVisibility returns a copy of a game create event that sets who may
+// view the game
+func (e Event) WithVisibility(visibility game.Visibility) Event {
+	e.Visibility = visibility
+	return e
+}
+
 type EventType string
 
 func (u *EventType) Scan(value interface{}) error {
@@ -47,6 +94,16 @@ const (
 	GameEndType           EventType = "game:end"
 	DrawOfferType         EventType = "offer:create"
 	DrawOfferResponseType EventType = "offer:respond"
+	DrawOfferWithdrawType EventType = "offer:withdraw"
+	TakebackRequestType   EventType = "takeback:create"
+	TakebackResponseType  EventType = "takeback:respond"
+	MoveRetractType       EventType = "move:retract"
+	RematchOfferType      EventType = "rematch:create"
+	RematchResponseType   EventType = "rematch:respond"
+	BerserkType           EventType = "berserk"
+	ChatType              EventType = "chat"
+	SpectatorChatType     EventType = "chat:spectator"
+	AnnotationType        EventType = "annotation"
 	// don't forget to add to queries/buffer.go if necessary
 )
 
@@ -68,6 +125,32 @@ func NewGameCreateEvent(gameId game.Id, whiteId, blackId users.Id) Event {
 	return *event
 }
 
+// NewRematchCreateEvent creates a game that follows previousGameId. Both
+// players are already known, so the game starts once the rematch is
+// accepted.
+func NewRematchCreateEvent(gameId, previousGameId game.Id, whiteId, blackId users.Id) Event {
+	event := NewGameCreateEvent(gameId, whiteId, blackId)
+	event.LinkedGameId = previousGameId
+	return event
+}
+
+// NewChallengeCreateEvent creates a game against a specific opponent. The
+// challenger is the colour of the player that created the game, the other
+// seat is reserved for the player being challenged.
+func NewChallengeCreateEvent(gameId game.Id, whiteId, blackId users.Id, challenger game.Color) Event {
+	event := NewGameCreateEvent(gameId, whiteId, blackId)
+	event.Offerer = challenger
+	return event
+}
+
+// NewPrivateGameCreateEvent creates a game that can only be joined by
+// presenting its invite code.
+func NewPrivateGameCreateEvent(gameId game.Id, whiteId, blackId users.Id, inviteCode string) Event {
+	event := NewGameCreateEvent(gameId, whiteId, blackId)
+	event.InviteCode = inviteCode
+	return event
+}
+
 func NewGameStartEvent(gameId game.Id, whiteId, blackId users.Id) Event {
 	event := new(Event)
 	event.Type = GameStartType
@@ -93,6 +176,108 @@ func NewDrawOfferResponseEvent(gameId game.Id, accept bool) Event {
 	return *event
 }
 
+func NewDrawOfferWithdrawEvent(gameId game.Id) Event {
+	event := new(Event)
+	event.Type = DrawOfferWithdrawType
+	event.GameId = gameId
+	return *event
+}
+
+func NewTakebackRequestEvent(gameId game.Id, color game.Color) Event {
+	event := new(Event)
+	event.Type = TakebackRequestType
+	event.GameId = gameId
+	event.Offerer = color
+	return *event
+}
+
+func NewTakebackResponseEvent(gameId game.Id, accept bool) Event {
+	event := new(Event)
+	event.Type = TakebackResponseType
+	event.GameId = gameId
+	event.OfferAccept = accept
+	return *event
+}
+
+// NewMoveRetractEvent marks the move made at turnNumber as taken back.
+// A later move event for the same turn replaces it.
+func NewMoveRetractEvent(gameId game.Id, turnNumber game.TurnNumber) Event {
+	event := new(Event)
+	event.Type = MoveRetractType
+	event.GameId = gameId
+	event.TurnNumber = turnNumber
+	return *event
+}
+
+func NewRematchOfferEvent(gameId, rematchGameId game.Id, color game.Color) Event {
+	event := new(Event)
+	event.Type = RematchOfferType
+	event.GameId = gameId
+	event.LinkedGameId = rematchGameId
+	event.Offerer = color
+	return *event
+}
+
+func NewRematchResponseEvent(gameId game.Id, accept bool) Event {
+	event := new(Event)
+	event.Type = RematchResponseType
+	event.GameId = gameId
+	event.OfferAccept = accept
+	return *event
+}
+
+// NewBerserkEvent records a player giving up half their time, with the
+// player as the Offerer
+func NewBerserkEvent(gameId game.Id, color game.Color) Event {
+	event := new(Event)
+	event.Type = BerserkType
+	event.GameId = gameId
+	event.Offerer = color
+	return *event
+}
+
+// NewChatEvent records a message sent by one of a game's players
+func NewChatEvent(gameId game.Id, authorId users.Id, message string) Event {
+	event := new(Event)
+	event.Type = ChatType
+	event.GameId = gameId
+	event.AuthorId = authorId
+	event.Message = message
+	return *event
+}
+
+// NewSpectatorChatEvent records a message sent by someone watching a
+// game, which its players aren't shown
+func NewSpectatorChatEvent(gameId game.Id, authorId users.Id, message string) Event {
+	event := new(Event)
+	event.Type = SpectatorChatType
+	event.GameId = gameId
+	event.AuthorId = authorId
+	event.Message = message
+	return *event
+}
+
+// NewAnnotationEvent records a comment and judgement of the move made at
+// a turn, replacing any made before
+func NewAnnotationEvent(gameId game.Id, turnNumber game.TurnNumber, authorId users.Id, comment string, nag game.NAG) Event {
+	event := new(Event)
+	event.Type = AnnotationType
+	event.GameId = gameId
+	event.TurnNumber = turnNumber
+	event.AuthorId = authorId
+	event.Message = comment
+	event.Glyph = nag
+	return *event
+}
+
+// NewGameAbortEvent ends a game before it got going, recording who
+// called it off
+func NewGameAbortEvent(gameId game.Id, abortedBy, whiteId, blackId users.Id) Event {
+	event := NewGameEndEvent(gameId, game.GameEndAborted, game.NoOne, whiteId, blackId)
+	event.AuthorId = abortedBy
+	return event
+}
+
 func NewGameEndEvent(gameId game.Id, reason game.GameEndReason, winner game.Color, whiteId, blackId users.Id) Event {
 	event := new(Event)
 	event.Type = GameEndType
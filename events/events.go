@@ -23,15 +23,23 @@ type Events interface {
 	NextGameId() game.Id
 
 	EventsForGame(gameId game.Id) []Event
+	EventsOfType(eventType EventType) []Event
 	EventsOfTypeForGame(gameId game.Id, eventType EventType) []Event
 	EventsOfTypeForPlayer(userId users.Id, eventType EventType) []Event
+	EventsForGamesSince(gameIds []game.Id, lastId int) []Event
 	MoveEventForGameAtTurn(gameId game.Id, turnNumber game.TurnNumber) Event
+	GameCreateEventForInviteCode(inviteCode string) Event
 }
 
 type EventSubscriber interface {
 	Receive(event Event) error
 }
 
+// EventPublisher passes the events it receives on to other subscribers
+type EventPublisher interface {
+	Subscribe(subscriber EventSubscriber)
+}
+
 type EventsService struct {
 	Config     config.DatabaseConfig `inject:"databaseConfig"`
 	Subscriber EventSubscriber       `inject:"eventSubscriber"`
@@ -103,12 +111,12 @@ func (s *EventsService) NextGameId() game.Id {
 	return <-s.gameIdChan
 }
 
+// Receive stores the event and passes it on, along with the Id and
+// creation time it was stored with, to the subscriber
 func (s *EventsService) Receive(event Event) error {
-	var partial Event = event
-
 	s.db.Create(&event)
 
-	s.Subscriber.Receive(partial)
+	s.Subscriber.Receive(event)
 
 	return nil
 }
@@ -119,6 +127,13 @@ func (s *EventsService) EventsForGame(gameId game.Id) []Event {
 	return events
 }
 
+// EventsOfType returns every event of eventType, oldest first
+func (s *EventsService) EventsOfType(eventType EventType) []Event {
+	var events []Event
+	s.db.Where(&Event{Type: eventType}).Order("id asc").Find(&events)
+	return events
+}
+
 func (s *EventsService) EventsOfTypeForGame(gameId game.Id, eventType EventType) []Event {
 	var events []Event
 	s.db.Where(&Event{GameId: gameId, Type: eventType}).Find(&events)
@@ -134,6 +149,22 @@ func (s *EventsService) EventsOfTypeForPlayer(userId users.Id, eventType EventTy
 	return events
 }
 
+// EventsForGamesSince returns, oldest first, the events in any of gameIds
+// that were stored after the event with lastId
+func (s *EventsService) EventsForGamesSince(gameIds []game.Id, lastId int) []Event {
+	var events []Event
+	if len(gameIds) == 0 {
+		return events
+	}
+	s.db.
+		Where("game_id IN (?) AND id > ?", gameIds, lastId).
+		Order("id asc").
+		Find(&events)
+	return events
+}
+
+// MoveEventForGameAtTurn returns the most recent move made at turnNumber,
+// since a move that was taken back is superseded by the one replacing it
 func (s *EventsService) MoveEventForGameAtTurn(gameId game.Id, turnNumber game.TurnNumber) Event {
 	var event Event
 	s.db.
@@ -143,6 +174,18 @@ func (s *EventsService) MoveEventForGameAtTurn(gameId game.Id, turnNumber game.T
 			GameId:     gameId,
 			TurnNumber: turnNumber,
 		}).
+		Last(&event)
+	return event
+}
+
+func (s *EventsService) GameCreateEventForInviteCode(inviteCode string) Event {
+	var event Event
+	s.db.
+		Where(
+		&Event{
+			Type:       GameCreateType,
+			InviteCode: inviteCode,
+		}).
 		First(&event)
 	return event
 }
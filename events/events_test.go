@@ -61,17 +61,49 @@ func (suite *EventsTestSuite) TestReceive() {
 	event := NewMoveEvent(gameId, turnNumber, move)
 
 	suite.mockSubscriber.
-		On("Receive", event).
+		On("Receive", mock.AnythingOfType("events.Event")).
 		Return(nil)
 
 	suite.events.Receive(event)
 
-	suite.mockSubscriber.AssertCalled(suite.T(), "Receive", event)
-
 	events := suite.events.EventsForGame(gameId)
 
 	assert := assert.New(suite.T())
 	assert.Equal(1, len(events))
+
+	// subscribers are passed the event as it was stored
+	received := suite.mockSubscriber.Calls[0].Arguments.Get(0).(Event)
+	assert.Equal(events[0].Id, received.Id)
+	assert.Equal(move, received.Move)
+}
+
+func (suite *EventsTestSuite) TestEventsForGamesSince() {
+	var (
+		player1 users.Id = "bob"
+		player2 users.Id = "frank"
+	)
+
+	suite.mockSubscriber.
+		On("Receive", mock.AnythingOfType("events.Event")).
+		Return(nil)
+
+	suite.events.Receive(NewGameStartEvent(1, player1, player2))
+	suite.events.Receive(NewGameStartEvent(2, player2, player1))
+	suite.events.Receive(NewMoveEvent(1, 1, "e4"))
+	suite.events.Receive(NewMoveEvent(2, 1, "d4"))
+	suite.events.Receive(NewMoveEvent(1, 2, "e5"))
+
+	assert := assert.New(suite.T())
+
+	all := suite.events.EventsForGamesSince([]game.Id{1}, 0)
+	assert.Equal(3, len(all))
+
+	since := suite.events.EventsForGamesSince([]game.Id{1}, all[0].Id)
+	assert.Equal(2, len(since))
+	assert.Equal(game.AlgebraicMove("e4"), since[0].Move)
+	assert.Equal(game.AlgebraicMove("e5"), since[1].Move)
+
+	assert.Equal(0, len(suite.events.EventsForGamesSince([]game.Id{}, 0)))
 }
 
 func (suite *EventsTestSuite) TestEventsOfTypeForPlayer() {
@@ -88,7 +120,10 @@ func (suite *EventsTestSuite) TestEventsOfTypeForPlayer() {
 	)
 
 	for _, event := range events {
-		suite.mockSubscriber.On("Receive", event).Return(nil).Once()
+		suite.mockSubscriber.
+			On("Receive", mock.AnythingOfType("events.Event")).
+			Return(nil).
+			Once()
 		suite.events.Receive(event)
 	}
 
@@ -0,0 +1,46 @@
+package events
+
+import (
+	"sync"
+)
+
+// Queue holds events for a subscriber to handle on its own goroutine.
+// Pushing never blocks, so a subscriber whose handling sends events back
+// through its publisher can't hold the publisher up, however many events
+// arrive meanwhile.
+type Queue struct {
+	lock    sync.Mutex
+	pending []Event
+	ready   chan bool
+}
+
+func NewQueue() *Queue {
+	return &Queue{ready: make(chan bool, 1)}
+}
+
+// Push adds an event to the back of the queue
+func (q *Queue) Push(event Event) {
+	q.lock.Lock()
+	q.pending = append(q.pending, event)
+	q.lock.Unlock()
+
+	select {
+	case q.ready <- true:
+	default:
+	}
+}
+
+// Ready receives once events have been pushed since the last Pop
+func (q *Queue) Ready() <-chan bool {
+	return q.ready
+}
+
+// Pop empties the queue, giving its events oldest first
+func (q *Queue) Pop() []Event {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	pending := q.pending
+	q.pending = nil
+	return pending
+}
@@ -0,0 +1,31 @@
+package events
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+
+	"foodtastechess/game"
+)
+
+func TestQueue(t *testing.T) {
+	assert := assert.New(t)
+	queue := NewQueue()
+
+	// far more events than anyone is waiting for
+	for i := 1; i <= 1000; i++ {
+		queue.Push(NewGameCreateEvent(game.Id(i), "white", "black"))
+	}
+
+	<-queue.Ready()
+	pending := queue.Pop()
+	assert.Equal(1000, len(pending))
+	assert.Equal(game.Id(1), pending[0].GameId)
+	assert.Equal(game.Id(1000), pending[999].GameId)
+
+	assert.Equal(0, len(queue.Pop()))
+	select {
+	case <-queue.Ready():
+		assert.Fail("nothing was pushed since the last pop")
+	default:
+	}
+}
@@ -0,0 +1,58 @@
+package game
+
+// NAG is a Numeric Annotation Glyph, the PGN standard's judgement of a
+// move. The zero NAG makes no judgement.
+type NAG int
+
+const (
+	NoNAG       NAG = 0
+	GoodMove    NAG = 1
+	Mistake     NAG = 2
+	Brilliant   NAG = 3
+	Blunder     NAG = 4
+	Interesting NAG = 5
+	Dubious     NAG = 6
+)
+
+var nagSymbols = map[NAG]string{
+	GoodMove:    "!",
+	Mistake:     "?",
+	Brilliant:   "!!",
+	Blunder:     "??",
+	Interesting: "!?",
+	Dubious:     "?!",
+}
+
+// ParseNAG reads a NAG from the symbol it is written as, like "!?"
+func ParseNAG(symbol string) (NAG, bool) {
+	if symbol == "" {
+		return NoNAG, true
+	}
+
+	for nag, s := range nagSymbols {
+		if s == symbol {
+			return nag, true
+		}
+	}
+
+	return NoNAG, false
+}
+
+// Valid reports whether the NAG is one of the move judgements
+func (n NAG) Valid() bool {
+	_, ok := nagSymbols[n]
+	return ok || n == NoNAG
+}
+
+// Symbol is how the NAG is written alongside a move, like "!?"
+func (n NAG) Symbol() string {
+	return nagSymbols[n]
+}
+
+// Annotation is a comment and judgement attached to the move made at a
+// turn
+type Annotation struct {
+	TurnNumber TurnNumber
+	Comment    string
+	NAG        NAG
+}
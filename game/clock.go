@@ -0,0 +1,124 @@
+package game
+
+import (
+	"time"
+)
+
+// Clock is the time each player has left in a timed game. The running
+// side's time counts down from Since, and is only charged when the
+// clock is pressed or stopped.
+type Clock struct {
+	White          time.Duration
+	Black          time.Duration
+	WhiteIncrement time.Duration
+	BlackIncrement time.Duration
+	WhiteBerserk   bool
+	BlackBerserk   bool
+	Running        Color
+	Since          time.Time
+}
+
+func NewClock(tc TimeControl) Clock {
+	initial := time.Duration(tc.Initial) * time.Second
+	increment := time.Duration(tc.Increment) * time.Second
+
+	return Clock{
+		White:          initial,
+		Black:          initial,
+		WhiteIncrement: increment,
+		BlackIncrement: increment,
+		Running:        NoOne,
+	}
+}
+
+// Start sets white's clock running
+func (c Clock) Start(at time.Time) Clock {
+	c.Running = White
+	c.Since = at
+	return c
+}
+
+// Press ends the running side's turn, adding their increment, and sets
+// their opponent's clock running
+func (c Clock) Press(at time.Time) Clock {
+	switch c.Running {
+	case White:
+		c = c.Switch(at)
+		c.White += c.WhiteIncrement
+	case Black:
+		c = c.Switch(at)
+		c.Black += c.BlackIncrement
+	}
+	return c
+}
+
+// Switch hands the turn to the other side without an increment, as when
+// a move is taken back
+func (c Clock) Switch(at time.Time) Clock {
+	running := c.Running
+	c = c.Stop(at)
+
+	switch running {
+	case White:
+		c.Running = Black
+	case Black:
+		c.Running = White
+	}
+	c.Since = at
+
+	return c
+}
+
+// Stop charges the running side for their time and stops the clock
+func (c Clock) Stop(at time.Time) Clock {
+	switch c.Running {
+	case White:
+		c.White -= at.Sub(c.Since)
+	case Black:
+		c.Black -= at.Sub(c.Since)
+	}
+	c.Running = NoOne
+	return c
+}
+
+// Berserk halves a player's time and takes away their increment
+func (c Clock) Berserk(color Color) Clock {
+	switch color {
+	case White:
+		c.White /= 2
+		c.WhiteIncrement = 0
+		c.WhiteBerserk = true
+	case Black:
+		c.Black /= 2
+		c.BlackIncrement = 0
+		c.BlackBerserk = true
+	}
+	return c
+}
+
+// Remaining is the time a player has left at now, which is never less
+// than nothing
+func (c Clock) Remaining(color Color, now time.Time) time.Duration {
+	remaining := c.White
+	if color == Black {
+		remaining = c.Black
+	}
+
+	if color == c.Running {
+		remaining -= now.Sub(c.Since)
+	}
+
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining
+}
+
+// Flagged is the player whose time has run out at now, if any
+func (c Clock) Flagged(now time.Time) Color {
+	if c.Running != NoOne && c.Remaining(c.Running, now) == 0 {
+		return c.Running
+	} else {
+		return NoOne
+	}
+}
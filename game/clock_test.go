@@ -0,0 +1,74 @@
+package game
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type ClockTestSuite struct {
+	suite.Suite
+}
+
+func TestClockTestSuite(t *testing.T) {
+	suite.Run(t, new(ClockTestSuite))
+}
+
+func (s *ClockTestSuite) TestPress() {
+	assert := assert.New(s.T())
+
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewClock(TimeControl{Initial: 180, Increment: 2}).Start(start)
+
+	assert.Equal(White, clock.Running)
+	assert.Equal(170*time.Second, clock.Remaining(White, start.Add(10*time.Second)))
+	assert.Equal(180*time.Second, clock.Remaining(Black, start.Add(10*time.Second)))
+
+	clock = clock.Press(start.Add(10 * time.Second))
+	assert.Equal(Black, clock.Running)
+	assert.Equal(172*time.Second, clock.White)
+
+	clock = clock.Press(start.Add(40 * time.Second))
+	assert.Equal(White, clock.Running)
+	assert.Equal(152*time.Second, clock.Black)
+
+	// takebacks switch sides without an increment
+	clock = clock.Switch(start.Add(50 * time.Second))
+	assert.Equal(Black, clock.Running)
+	assert.Equal(162*time.Second, clock.White)
+
+	clock = clock.Stop(start.Add(60 * time.Second))
+	assert.Equal(NoOne, clock.Running)
+	assert.Equal(142*time.Second, clock.Remaining(Black, start.Add(time.Hour)))
+}
+
+func (s *ClockTestSuite) TestFlagged() {
+	assert := assert.New(s.T())
+
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewClock(TimeControl{Initial: 60}).Start(start)
+
+	assert.Equal(NoOne, clock.Flagged(start.Add(59*time.Second)))
+	assert.Equal(White, clock.Flagged(start.Add(61*time.Second)))
+	assert.Equal(time.Duration(0), clock.Remaining(White, start.Add(61*time.Second)))
+
+	// nobody flags while the clock is stopped
+	assert.Equal(NoOne, NewClock(TimeControl{Initial: 60}).Flagged(start.Add(time.Hour)))
+}
+
+func (s *ClockTestSuite) TestBerserk() {
+	assert := assert.New(s.T())
+
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewClock(TimeControl{Initial: 180, Increment: 2}).Start(start)
+
+	clock = clock.Berserk(Black)
+	assert.Equal(true, clock.BlackBerserk)
+	assert.Equal(90*time.Second, clock.Black)
+
+	clock = clock.Press(start.Add(5 * time.Second))
+	clock = clock.Press(start.Add(15 * time.Second))
+	assert.Equal(80*time.Second, clock.Black)
+	assert.Equal(177*time.Second, clock.White)
+}
@@ -73,6 +73,10 @@ func (u Color) Value() (driver.Value, error) {
 type MoveRecord struct {
 	Move                AlgebraicMove
 	ResultingBoardState FEN
+
+	// what has been said about the move, once the game has ended
+	Comment string `json:",omitempty"`
+	NAG     NAG    `json:",omitempty"`
 }
 
 type GameEndReason string
@@ -81,6 +85,8 @@ const (
 	GameEndConcede   GameEndReason = "concede"
 	GameEndDraw      GameEndReason = "stalemate"
 	GameEndCheckmate GameEndReason = "checkmate"
+	GameEndAborted   GameEndReason = "aborted"
+	GameEndTimeout   GameEndReason = "timeout"
 )
 
 func (u *GameEndReason) Scan(value interface{}) error {
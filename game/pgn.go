@@ -0,0 +1,161 @@
+package game
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// PGNTag is a tag pair in a PGN export's header, like [White "alice"]
+type PGNTag struct {
+	Name  string
+	Value string
+}
+
+// the longest line a PGN export's movetext is wrapped at
+const pgnLineLength = 79
+
+// SAN converts a move to the standard algebraic notation PGN uses,
+// given the board it was made on, so that "Ng1-f3" becomes "Nf3"
+func SAN(fen FEN, move AlgebraicMove) string {
+	m := string(move)
+
+	// moves are stored with a check, mate or stalemate marker; PGN has
+	// no marker for stalemate
+	suffix := ""
+	if strings.HasSuffix(m, "+") || strings.HasSuffix(m, "#") {
+		suffix = m[len(m)-1:]
+		m = m[:len(m)-1]
+	} else if strings.HasSuffix(m, "S") {
+		m = m[:len(m)-1]
+	}
+
+	if m == "0-0" {
+		return "O-O" + suffix
+	} else if m == "0-0-0" {
+		return "O-O-O" + suffix
+	} else if len(m) < 6 {
+		return string(move)
+	}
+
+	piece, from, capture, to, rest := m[:1], m[1:3], m[3:4] == "x", m[4:6], m[6:]
+
+	san := ""
+	if piece == "P" {
+		if capture {
+			san = from[:1] + "x"
+		}
+		san += to
+
+		if strings.HasPrefix(rest, "=") {
+			san += rest
+		}
+
+		return san + suffix
+	}
+
+	// name the origin file, rank or square when another piece of the
+	// same kind could also have moved there
+	others := []string{}
+	for _, valid := range AllValidMovesWithoutExtraNotation(fen) {
+		v := string(valid)
+		if len(v) >= 6 && v[:1] == piece && v[4:6] == to && v[1:3] != from {
+			others = append(others, v[1:3])
+		}
+	}
+
+	disambiguation := ""
+	if len(others) > 0 {
+		sameFile, sameRank := false, false
+		for _, other := range others {
+			sameFile = sameFile || other[:1] == from[:1]
+			sameRank = sameRank || other[1:] == from[1:]
+		}
+
+		if !sameFile {
+			disambiguation = from[:1]
+		} else if !sameRank {
+			disambiguation = from[1:]
+		} else {
+			disambiguation = from
+		}
+	}
+
+	san = piece + disambiguation
+	if capture {
+		san += "x"
+	}
+
+	return san + to + suffix
+}
+
+// WritePGN exports a game in PGN. The history starts with the board
+// before the first move, as GameHistory returns it, and its moves are
+// written with their annotations.
+func WritePGN(w io.Writer, tags []PGNTag, history []MoveRecord, result string) error {
+	for _, tag := range tags {
+		value := strings.Replace(tag.Value, `\`, `\\`, -1)
+		value = strings.Replace(value, `"`, `\"`, -1)
+
+		_, err := fmt.Fprintf(w, "[%s \"%s\"]\n", tag.Name, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	tokens := []string{}
+	annotated := false
+
+	for i := 1; i < len(history); i++ {
+		record := history[i]
+		white := i%2 == 1
+
+		// black's move is renumbered when something comes between it
+		// and white's
+		if white {
+			tokens = append(tokens, fmt.Sprintf("%d.", (i+1)/2))
+		} else if annotated {
+			tokens = append(tokens, fmt.Sprintf("%d...", i/2))
+		}
+
+		tokens = append(tokens, SAN(history[i-1].ResultingBoardState, record.Move))
+
+		annotated = false
+		if record.NAG != NoNAG {
+			tokens = append(tokens, fmt.Sprintf("$%d", record.NAG))
+			annotated = true
+		}
+		if record.Comment != "" {
+			// comments can't contain the brace that ends them
+			comment := strings.Replace(record.Comment, "}", ")", -1)
+			tokens = append(tokens, "{"+strings.Join(strings.Fields(comment), " ")+"}")
+			annotated = true
+		}
+	}
+
+	tokens = append(tokens, result)
+
+	_, err := fmt.Fprintf(w, "\n%s\n", wrap(tokens, pgnLineLength))
+	return err
+}
+
+// wrap joins tokens with spaces, breaking lines before they grow longer
+// than length
+func wrap(tokens []string, length int) string {
+	lines := []string{}
+	line := ""
+
+	for _, token := range tokens {
+		if line != "" && len(line)+1+len(token) > length {
+			lines = append(lines, line)
+			line = ""
+		}
+
+		if line != "" {
+			line += " "
+		}
+		line += token
+	}
+
+	return strings.Join(append(lines, line), "\n")
+}
@@ -0,0 +1,102 @@
+package game
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type PGNTestSuite struct {
+	suite.Suite
+}
+
+func TestPGNTestSuite(t *testing.T) {
+	suite.Run(t, new(PGNTestSuite))
+}
+
+func (s *PGNTestSuite) TestSAN() {
+	assert := assert.New(s.T())
+
+	start := InitializeFEN()
+	assert.Equal("e4", SAN(start, "Pe2-e4"))
+	assert.Equal("Nf3", SAN(start, "Ng1-f3"))
+	assert.Equal("exd5", SAN(start, "Pe4xd5"))
+	assert.Equal("exd6", SAN(start, "Pe5xd6.ep"))
+	assert.Equal("e8=Q+", SAN(start, "Pe7-e8=Q+"))
+	assert.Equal("Qxf7#", SAN(start, "Qd1xf7#"))
+	assert.Equal("Kc6", SAN(start, "Kb6-c6S"))
+	assert.Equal("O-O", SAN(start, "0-0"))
+	assert.Equal("O-O-O+", SAN(start, "0-0-0+"))
+
+	// knights on a1 and c1 can both reach b3
+	knights := FEN("4k3/8/8/8/8/8/8/N1N1K3 w - - 0 1")
+	assert.Equal("Nab3", SAN(knights, "Na1-b3"))
+	assert.Equal("Ncb3", SAN(knights, "Nc1-b3"))
+	assert.Equal("Ne2", SAN(knights, "Nc1-e2"))
+
+	// rooks on a1 and a5 can both reach a3
+	rooks := FEN("4k3/8/8/R7/8/8/8/R3K3 w - - 0 1")
+	assert.Equal("R1a3", SAN(rooks, "Ra1-a3"))
+	assert.Equal("R5a3", SAN(rooks, "Ra5-a3"))
+}
+
+func (s *PGNTestSuite) TestNAG() {
+	assert := assert.New(s.T())
+
+	nag, ok := ParseNAG("!?")
+	assert.Equal(true, ok)
+	assert.Equal(Interesting, nag)
+	assert.Equal("??", Blunder.Symbol())
+
+	_, ok = ParseNAG("!!!")
+	assert.Equal(false, ok)
+
+	assert.Equal(true, NoNAG.Valid())
+	assert.Equal(true, Dubious.Valid())
+	assert.Equal(false, NAG(7).Valid())
+}
+
+func (s *PGNTestSuite) TestWritePGN() {
+	assert := assert.New(s.T())
+
+	start := InitializeFEN()
+	afterE4 := AfterMove("Pe2-e4", start)
+	afterE5 := AfterMove("Pe7-e5", afterE4)
+	afterNf3 := AfterMove("Ng1-f3", afterE5)
+
+	history := []MoveRecord{
+		{ResultingBoardState: start},
+		{Move: "Pe2-e4", ResultingBoardState: afterE4, NAG: GoodMove, Comment: "best {by} test"},
+		{Move: "Pe7-e5", ResultingBoardState: afterE5},
+		{Move: "Ng1-f3", ResultingBoardState: afterNf3},
+	}
+	tags := []PGNTag{
+		{"White", "alice"},
+		{"Black", `bob "the rook"`},
+	}
+
+	var buf bytes.Buffer
+	err := WritePGN(&buf, tags, history, "*")
+
+	assert.Nil(err)
+	assert.Equal(strings.Join([]string{
+		`[White "alice"]`,
+		`[Black "bob \"the rook\""]`,
+		``,
+		`1. e4 $1 {best {by) test} 1... e5 2. Nf3 *`,
+		``,
+	}, "\n"), buf.String())
+}
+
+func (s *PGNTestSuite) TestWrap() {
+	tokens := strings.Fields(strings.Repeat("1. e4 e5 ", 12))
+	lines := strings.Split(wrap(tokens, pgnLineLength), "\n")
+
+	assert := assert.New(s.T())
+	assert.Equal(2, len(lines))
+	for _, line := range lines {
+		assert.True(len(line) <= pgnLineLength)
+	}
+}
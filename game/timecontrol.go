@@ -0,0 +1,63 @@
+package game
+
+import (
+	"database/sql/driver"
+)
+
+// TimeControl is the clock a game is played with. Initial is the time
+// each player starts with and Increment the time added after each of
+// their moves, both in seconds. The zero TimeControl is an untimed,
+// correspondence game.
+type TimeControl struct {
+	Initial   int
+	Increment int
+}
+
+type TimeCategory string
+
+const (
+	Bullet         TimeCategory = "bullet"
+	Blitz          TimeCategory = "blitz"
+	Rapid          TimeCategory = "rapid"
+	Classical      TimeCategory = "classical"
+	Correspondence TimeCategory = "correspondence"
+)
+
+var TimeCategories = []TimeCategory{
+	Bullet, Blitz, Rapid, Classical, Correspondence,
+}
+
+func (u *TimeCategory) Scan(value interface{}) error {
+	*u = TimeCategory(value.([]byte))
+	return nil
+}
+
+func (u TimeCategory) Value() (driver.Value, error) {
+	return string(u), nil
+}
+
+// Untimed reports whether the game is played without a clock
+func (tc TimeControl) Untimed() bool {
+	return tc.Initial == 0 && tc.Increment == 0
+}
+
+// Category buckets a time control by the expected length of a game,
+// taken to be the initial time plus the increment for 40 moves
+func (tc TimeControl) Category() TimeCategory {
+	if tc.Untimed() {
+		return Correspondence
+	}
+
+	estimate := tc.Initial + 40*tc.Increment
+
+	switch {
+	case estimate < 3*60:
+		return Bullet
+	case estimate < 8*60:
+		return Blitz
+	case estimate < 25*60:
+		return Rapid
+	default:
+		return Classical
+	}
+}
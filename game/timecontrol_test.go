@@ -0,0 +1,27 @@
+package game
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type TimeControlTestSuite struct {
+	suite.Suite
+}
+
+func TestTimeControlTestSuite(t *testing.T) {
+	suite.Run(t, new(TimeControlTestSuite))
+}
+
+func (s *TimeControlTestSuite) TestCategory() {
+	assert := assert.New(s.T())
+
+	assert.Equal(Correspondence, TimeControl{}.Category())
+	assert.Equal(Bullet, TimeControl{Initial: 60}.Category())
+	assert.Equal(Bullet, TimeControl{Initial: 120, Increment: 1}.Category())
+	assert.Equal(Blitz, TimeControl{Initial: 180, Increment: 2}.Category())
+	assert.Equal(Blitz, TimeControl{Initial: 300}.Category())
+	assert.Equal(Rapid, TimeControl{Initial: 600, Increment: 5}.Category())
+	assert.Equal(Classical, TimeControl{Initial: 1800, Increment: 20}.Category())
+}
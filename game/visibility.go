@@ -0,0 +1,23 @@
+package game
+
+import (
+	"database/sql/driver"
+)
+
+// Visibility decides who may view a game. Anyone may watch a public
+// game, while a private game is only shown to its players.
+type Visibility string
+
+const (
+	Public  Visibility = "public"
+	Private Visibility = "private"
+)
+
+func (u *Visibility) Scan(value interface{}) error {
+	*u = Visibility(value.([]byte))
+	return nil
+}
+
+func (u Visibility) Value() (driver.Value, error) {
+	return string(u), nil
+}
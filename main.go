@@ -8,15 +8,20 @@ import (
 	"os/signal"
 	"syscall"
 
+	"foodtastechess/broker"
 	"foodtastechess/commands"
+	"foodtastechess/conditionals"
 	"foodtastechess/config"
 	"foodtastechess/directory"
 	"foodtastechess/events"
 	"foodtastechess/fixtures"
 	"foodtastechess/game"
 	"foodtastechess/logger"
+	"foodtastechess/matchmaking"
 	"foodtastechess/queries"
+	"foodtastechess/ratings"
 	"foodtastechess/server"
+	"foodtastechess/tournaments"
 	"foodtastechess/users"
 )
 
@@ -63,6 +68,11 @@ func (app *App) LoadServices() error {
 		"gameCalculator":  game.NewGameCalculator(),
 		"eventSubscriber": queries.NewQueryBuffer(),
 		"fixtures":        fixtures.NewFixtures(),
+		"matchmaker":      matchmaking.New(),
+		"ratings":         ratings.New(),
+		"tournaments":     tournaments.New(),
+		"broker":          broker.New(),
+		"conditionals":    conditionals.New(),
 
 		"stopChan": app.StopChan,
 	}
@@ -100,6 +110,27 @@ func (app *App) Start() {
 		return
 	}
 
+	err = app.directory.Start("matchmaker")
+	if err != nil {
+		msg := fmt.Sprintf("Could not start matchmaker: %v", err)
+		log.Error(msg)
+		return
+	}
+
+	err = app.directory.Start("tournaments")
+	if err != nil {
+		msg := fmt.Sprintf("Could not start tournaments: %v", err)
+		log.Error(msg)
+		return
+	}
+
+	err = app.directory.Start("conditionals")
+	if err != nil {
+		msg := fmt.Sprintf("Could not start conditional moves: %v", err)
+		log.Error(msg)
+		return
+	}
+
 	if *app.runFixtures {
 		err = app.directory.Start("fixtures")
 		if err != nil {
@@ -125,6 +156,27 @@ func (app *App) Stop() {
 		log.Error(msg)
 		return
 	}
+
+	err = app.directory.Stop("matchmaker")
+	if err != nil {
+		msg := fmt.Sprintf("Could not stop matchmaker: %v", err)
+		log.Error(msg)
+		return
+	}
+
+	err = app.directory.Stop("tournaments")
+	if err != nil {
+		msg := fmt.Sprintf("Could not stop tournaments: %v", err)
+		log.Error(msg)
+		return
+	}
+
+	err = app.directory.Stop("conditionals")
+	if err != nil {
+		msg := fmt.Sprintf("Could not stop conditional moves: %v", err)
+		log.Error(msg)
+		return
+	}
 }
 
 func main() {
@@ -5,16 +5,20 @@ import (
 	"github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"strings"
 	"testing"
 	"time"
 
 	"foodtastechess/commands"
+	"foodtastechess/conditionals"
 	"foodtastechess/config"
 	"foodtastechess/directory"
 	"foodtastechess/events"
 	"foodtastechess/game"
 	"foodtastechess/logger"
 	"foodtastechess/queries"
+	"foodtastechess/ratings"
+	"foodtastechess/tournaments"
 	"foodtastechess/users"
 )
 
@@ -27,6 +31,10 @@ type IntegrationTestSuite struct {
 	Queries  queries.ClientQueries
 	Events   events.Events
 
+	Tournaments  tournaments.Tournaments
+	Conditionals conditionals.Conditionals
+	users        users.Users
+
 	whiteId users.Id
 	blackId users.Id
 }
@@ -42,6 +50,9 @@ func (suite *IntegrationTestSuite) SetupTest() {
 	systemQueries := queries.NewSystemQueryService().(*queries.SystemQueryService)
 	eventsService := events.NewEvents().(*events.EventsService)
 	usersService := users.NewUsers().(*users.UsersService)
+	ratingsService := ratings.New().(*ratings.RatingsService)
+	tournamentsService := tournaments.New().(*tournaments.TournamentsService)
+	conditionalsService := conditionals.New().(*conditionals.ConditionalsService)
 
 	d := directory.New()
 	d.AddService("configProvider", configProvider)
@@ -51,6 +62,9 @@ func (suite *IntegrationTestSuite) SetupTest() {
 	d.AddService("systemQueries", systemQueries)
 	d.AddService("events", eventsService)
 	d.AddService("users", usersService)
+	d.AddService("ratings", ratingsService)
+	d.AddService("tournaments", tournamentsService)
+	d.AddService("conditionals", conditionalsService)
 
 	d.AddService("commands", suite.Commands)
 	d.AddService("clientQueries", suite.Queries)
@@ -67,8 +81,25 @@ func (suite *IntegrationTestSuite) SetupTest() {
 		return
 	}
 
+	err = d.Start("tournaments")
+	if err != nil {
+		msg := fmt.Sprintf("Could not start tournaments: %v", err)
+		log.Error(msg)
+		return
+	}
+
+	err = d.Start("conditionals")
+	if err != nil {
+		msg := fmt.Sprintf("Could not start conditional moves: %v", err)
+		log.Error(msg)
+		return
+	}
+
 	usersService.ResetTestDB()
 	eventsService.ResetTestDB()
+	ratingsService.ResetTestDB()
+	tournamentsService.ResetTestDB()
+	conditionalsService.ResetTestDB()
 	systemQueries.Cache.Flush()
 
 	time.Sleep(1 * time.Second)
@@ -91,23 +122,27 @@ func (suite *IntegrationTestSuite) SetupTest() {
 	suite.log.Info("black UUID: %s", black.Uuid)
 	suite.whiteId = white.Uuid
 	suite.blackId = black.Uuid
+
+	suite.Tournaments = tournamentsService
+	suite.Conditionals = conditionalsService
+	suite.users = usersService
 }
 
 func (suite *IntegrationTestSuite) TestGameFlow() {
 	assert := assert.New(suite.T())
 	var (
-		ok     bool
-		msg    string
-		gameId game.Id
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
 	)
 
 	// Create Game
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.CreateGame, suite.whiteId, map[string]interface{}{
 			"color": game.White,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -116,13 +151,23 @@ func (suite *IntegrationTestSuite) TestGameFlow() {
 
 	gameId = userGames[0]
 
+	// a game can't be created over one that exists
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+			"color":  game.White,
+		},
+	)
+	assert.Equal(false, ok)
+	assert.Equal(commands.GameExists, refusal.Code)
+
 	// Join Game
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.JoinGame, suite.blackId, map[string]interface{}{
 			"gameId": gameId,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -133,13 +178,13 @@ func (suite *IntegrationTestSuite) TestGameFlow() {
 	assert.Equal(queries.GameStatusStarted, gameInfo.GameStatus)
 
 	// Make Move
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.Move, suite.whiteId, map[string]interface{}{
 			"gameId": gameId,
 			"move":   game.AlgebraicMove("Pb2-b4"),
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -152,18 +197,18 @@ func (suite *IntegrationTestSuite) TestGameFlow() {
 func (suite *IntegrationTestSuite) TestDrawOffer() {
 	assert := assert.New(suite.T())
 	var (
-		ok     bool
-		msg    string
-		gameId game.Id
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
 	)
 
 	// Create Game
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.CreateGame, suite.whiteId, map[string]interface{}{
 			"color": game.White,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -173,12 +218,12 @@ func (suite *IntegrationTestSuite) TestDrawOffer() {
 	gameId = userGames[0]
 
 	// Join Game
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.JoinGame, suite.blackId, map[string]interface{}{
 			"gameId": gameId,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -191,12 +236,12 @@ func (suite *IntegrationTestSuite) TestDrawOffer() {
 	assert.Equal(false, gameInfo.OutstandingDrawOffer)
 
 	// Draw Offer
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.OfferDraw, suite.whiteId, map[string]interface{}{
 			"gameId": gameId,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -207,13 +252,13 @@ func (suite *IntegrationTestSuite) TestDrawOffer() {
 	assert.Equal(game.White, gameInfo.DrawOfferer)
 
 	// Draw Offer Reject
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.DrawOfferRespond, suite.blackId, map[string]interface{}{
 			"gameId": gameId,
 			"accept": false,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -223,12 +268,12 @@ func (suite *IntegrationTestSuite) TestDrawOffer() {
 	assert.Equal(queries.GameStatusStarted, gameInfo.GameStatus)
 
 	// Draw Offer 2
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.OfferDraw, suite.blackId, map[string]interface{}{
 			"gameId": gameId,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -239,13 +284,13 @@ func (suite *IntegrationTestSuite) TestDrawOffer() {
 	assert.Equal(game.Black, gameInfo.DrawOfferer)
 
 	// Draw Offer 2 Accept
-	ok, msg = suite.Commands.ExecCommand(
+	ok, refusal = suite.Commands.ExecCommand(
 		commands.DrawOfferRespond, suite.whiteId, map[string]interface{}{
 			"gameId": gameId,
 			"accept": true,
 		},
 	)
-	assert.Equal(true, ok, msg)
+	assert.Equal(true, ok, refusal.Message)
 
 	time.Sleep(100 * time.Millisecond)
 
@@ -258,6 +303,1175 @@ func (suite *IntegrationTestSuite) TestDrawOffer() {
 	assert.Equal(game.NoOne, gameInfo.Winner)
 }
 
+func (suite *IntegrationTestSuite) TestDrawOfferWithdrawAndLapse() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
+	)
+
+	// Create Game
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId = suite.Queries.UserGames(suite.whiteId)[0]
+
+	// Join Game
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Draw Offer, then Withdraw
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.OfferDraw, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	ok, _ = suite.Commands.ExecCommand(
+		commands.WithdrawDrawOffer, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(false, ok)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.WithdrawDrawOffer, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(false, gameInfo.OutstandingDrawOffer)
+
+	// Draw Offer, then the offerer moves
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.OfferDraw, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Move, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+			"move":   game.AlgebraicMove("Pb2-b4"),
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok = suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(true, gameInfo.OutstandingDrawOffer)
+
+	// Opponent moves instead of answering, offer lapses
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Move, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+			"move":   game.AlgebraicMove("Pb7-b5"),
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok = suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(false, gameInfo.OutstandingDrawOffer)
+	assert.Equal(queries.GameStatusStarted, gameInfo.GameStatus)
+}
+
+func (suite *IntegrationTestSuite) TestTakeback() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
+	)
+
+	// Create Game
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId = suite.Queries.UserGames(suite.whiteId)[0]
+
+	// Join Game
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Make Move
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Move, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+			"move":   game.AlgebraicMove("Pb2-b4"),
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Black has nothing to take back yet
+	ok, _ = suite.Commands.ExecCommand(
+		commands.RequestTakeback, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(false, ok)
+
+	// Takeback Request
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.RequestTakeback, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(true, gameInfo.OutstandingTakeback)
+	assert.Equal(game.White, gameInfo.TakebackRequester)
+
+	// Takeback Accept
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.TakebackRespond, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+			"accept": true,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok = suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(false, gameInfo.OutstandingTakeback)
+	assert.Equal(game.TurnNumber(0), gameInfo.TurnNumber)
+	assert.Equal(game.White, gameInfo.ActiveColor)
+
+	// Replay a different first move
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Move, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+			"move":   game.AlgebraicMove("Pe2-e4"),
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameHistory, ok := suite.Queries.GameHistory(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(2, len(gameHistory))
+	assert.Equal(game.AlgebraicMove("Pe2-e4"), gameHistory[1].Move)
+}
+
+func (suite *IntegrationTestSuite) TestAbort() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
+	)
+
+	// Create Game
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId = suite.Queries.UserGames(suite.whiteId)[0]
+
+	// Only the creator may abort before anyone joins
+	ok, _ = suite.Commands.ExecCommand(
+		commands.Abort, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(false, ok)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Abort, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(queries.GameStatusEnded, gameInfo.GameStatus)
+	assert.Equal(game.GameEndAborted, gameInfo.GameEndReason)
+	assert.Equal(game.NoOne, gameInfo.Winner)
+
+	assert.Equal(0, len(suite.Queries.UserGames(suite.whiteId)))
+}
+
+func (suite *IntegrationTestSuite) TestRematch() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
+	)
+
+	// Create Game
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId = suite.Queries.UserGames(suite.whiteId)[0]
+
+	// Join Game
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Concede
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Concede, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Rematch Offer
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Rematch, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(game.Black, gameInfo.RematchOfferer)
+
+	rematchId := gameInfo.RematchGameId
+
+	rematchInfo, ok := suite.Queries.GameInformation(rematchId)
+	assert.Equal(true, ok)
+	assert.Equal(queries.GameStatusCreated, rematchInfo.GameStatus)
+	assert.Equal(gameId, rematchInfo.PreviousGameId)
+	assert.Equal(suite.blackId, rematchInfo.White.Uuid)
+	assert.Equal(suite.whiteId, rematchInfo.Black.Uuid)
+
+	// No moves until the rematch is accepted
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Move, suite.blackId, map[string]interface{}{
+			"gameId": rematchId,
+			"move":   game.AlgebraicMove("Pe2-e4"),
+		},
+	)
+	assert.Equal(false, ok)
+	assert.Equal(commands.GameNotStarted, refusal.Code)
+
+	// Rematch Accept
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.RematchRespond, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+			"accept": true,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	rematchInfo, ok = suite.Queries.GameInformation(rematchId)
+	assert.Equal(true, ok)
+	assert.Equal(queries.GameStatusStarted, rematchInfo.GameStatus)
+	assert.Equal(1, rematchInfo.Series.Games)
+	assert.Equal(float64(1), rematchInfo.Series.White)
+}
+
+func (suite *IntegrationTestSuite) TestChallenge() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
+	)
+
+	// Cannot challenge yourself
+	ok, _ = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":    game.White,
+			"opponent": suite.whiteId,
+		},
+	)
+	assert.Equal(false, ok)
+
+	// White challenges black
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":    game.White,
+			"opponent": suite.blackId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId = suite.Queries.UserGames(suite.blackId)[0]
+
+	gameInfo, ok := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(queries.GameStatusCreated, gameInfo.GameStatus)
+	assert.Equal(game.Black, gameInfo.Invitee)
+	assert.Equal(true, gameInfo.Private)
+
+	// The challenger cannot accept their own challenge
+	ok, _ = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(false, ok)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok = suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(queries.GameStatusStarted, gameInfo.GameStatus)
+	assert.Equal(suite.whiteId, gameInfo.White.Uuid)
+	assert.Equal(suite.blackId, gameInfo.Black.Uuid)
+}
+
+func (suite *IntegrationTestSuite) TestPrivateGame() {
+	assert := assert.New(suite.T())
+	var (
+		ok         bool
+		refusal    commands.Refusal
+		gameId     game.Id
+		inviteCode string = commands.NewInviteCode()
+	)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":      game.White,
+			"inviteCode": inviteCode,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId, ok = suite.Queries.InvitedGame(inviteCode)
+	assert.Equal(true, ok)
+	assert.Equal(suite.Queries.UserGames(suite.whiteId)[0], gameId)
+
+	// Joining needs the code
+	ok, _ = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(false, ok)
+
+	ok, _ = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId":     gameId,
+			"inviteCode": "wrong",
+		},
+	)
+	assert.Equal(false, ok)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId":     gameId,
+			"inviteCode": inviteCode,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(queries.GameStatusStarted, gameInfo.GameStatus)
+	assert.Equal(true, gameInfo.Private)
+}
+
+func (suite *IntegrationTestSuite) TestLobby() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		blitz   game.TimeControl = game.TimeControl{Initial: 300, Increment: 2}
+	)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":       game.White,
+			"timeControl": blitz,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	// private games stay out of the lobby
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":      game.White,
+			"inviteCode": commands.NewInviteCode(),
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(0, len(suite.Queries.Lobby(suite.whiteId, queries.LobbyFilter{})))
+
+	lobby := suite.Queries.Lobby(suite.blackId, queries.LobbyFilter{})
+	assert.Equal(1, len(lobby))
+	assert.Equal(suite.whiteId, lobby[0].Creator.Uuid)
+	assert.Equal(game.White, lobby[0].CreatorColor)
+	assert.Equal(blitz, lobby[0].TimeControl)
+	assert.Equal(game.Blitz, lobby[0].TimeCategory)
+
+	assert.Equal(0, len(suite.Queries.Lobby(
+		suite.blackId, queries.LobbyFilter{Category: game.Bullet},
+	)))
+	assert.Equal(0, len(suite.Queries.Lobby(
+		suite.blackId, queries.LobbyFilter{Color: game.White},
+	)))
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": lobby[0].GameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Equal(0, len(suite.Queries.Lobby(suite.blackId, queries.LobbyFilter{})))
+}
+
+func (suite *IntegrationTestSuite) TestVisibility() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+	)
+
+	ok, _ = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":      game.White,
+			"visibility": game.Visibility("friends"),
+		},
+	)
+	assert.Equal(false, ok)
+
+	// a private game nobody is invited to would be open to strangers
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":      game.White,
+			"visibility": game.Private,
+		},
+	)
+	assert.Equal(false, ok)
+	assert.Equal(commands.InvalidVisibility, refusal.Code)
+
+	inviteCode := commands.NewInviteCode()
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":      game.White,
+			"visibility": game.Private,
+			"inviteCode": inviteCode,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameIds := suite.Queries.UserGames(suite.whiteId)
+	assert.Equal(2, len(gameIds))
+
+	// user games come in no particular order
+	var privateId, publicId game.Id
+	for _, gameId := range gameIds {
+		gameInfo, _ := suite.Queries.GameInformation(gameId)
+		if gameInfo.Visibility == game.Private {
+			privateId = gameId
+		} else {
+			publicId = gameId
+		}
+	}
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": privateId,
+		},
+	)
+	assert.Equal(false, ok)
+	assert.Equal(commands.InviteCodeRequired, refusal.Code)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId":     privateId,
+			"inviteCode": inviteCode,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": publicId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	private, _ := suite.Queries.GameInformation(privateId)
+	assert.Equal(game.Private, private.Visibility)
+	assert.Equal(true, private.VisibleTo(suite.blackId))
+	assert.Equal(false, private.VisibleTo("spectator"))
+
+	public, _ := suite.Queries.GameInformation(publicId)
+	assert.Equal(game.Public, public.Visibility)
+	assert.Equal(true, public.VisibleTo("spectator"))
+
+	// only the public game is listed as being played
+	live := suite.Queries.LiveGames()
+	assert.Equal(1, len(live))
+	assert.Equal(publicId, live[0].GameId)
+}
+
+func (suite *IntegrationTestSuite) TestChat() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+	)
+
+	spectator := users.User{
+		Uuid:           users.NewId(),
+		Name:           "spectator",
+		AuthIdentifier: "spectatorAuthId",
+	}
+	suite.users.Save(&spectator)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId := suite.Queries.UserGames(suite.whiteId)[0]
+
+	chat := func(userId users.Id, message string) (bool, commands.Refusal) {
+		return suite.Commands.ExecCommand(
+			commands.Chat, userId, map[string]interface{}{
+				"gameId":  gameId,
+				"message": message,
+			},
+		)
+	}
+
+	ok, refusal = chat(suite.whiteId, "good luck")
+	assert.Equal(true, ok, refusal.Message)
+	ok, refusal = chat(spectator.Uuid, "  here we go  ")
+	assert.Equal(true, ok, refusal.Message)
+
+	ok, _ = chat(suite.whiteId, "   ")
+	assert.Equal(false, ok)
+	ok, _ = chat(suite.whiteId, strings.Repeat("a", 201))
+	assert.Equal(false, ok)
+
+	time.Sleep(100 * time.Millisecond)
+
+	messages, found := suite.Queries.Chat(gameId)
+	assert.Equal(true, found)
+	assert.Equal(2, len(messages))
+
+	assert.Equal(queries.PlayerChat, messages[0].Channel)
+	assert.Equal("whitePlayer", messages[0].Author.Name)
+	assert.Equal("good luck", messages[0].Message)
+
+	assert.Equal(queries.SpectatorChat, messages[1].Channel)
+	assert.Equal("spectator", messages[1].Author.Name)
+	assert.Equal("here we go", messages[1].Message)
+}
+
+func (suite *IntegrationTestSuite) TestAnnotations() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+	)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId := suite.Queries.UserGames(suite.whiteId)[0]
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Move, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+			"move":   game.AlgebraicMove("Pe2-e4"),
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	annotate := func(userId users.Id, turnNumber game.TurnNumber, comment string, nag game.NAG) (bool, commands.Refusal) {
+		return suite.Commands.ExecCommand(
+			commands.Annotate, userId, map[string]interface{}{
+				"gameId":     gameId,
+				"turnNumber": turnNumber,
+				"comment":    comment,
+				"nag":        nag,
+			},
+		)
+	}
+
+	// moves are only annotated once the game is over
+	ok, _ = annotate(suite.whiteId, 1, "best by test", game.GoodMove)
+	assert.Equal(false, ok)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Concede, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	ok, _ = annotate(suite.whiteId, 2, "never played", game.NoNAG)
+	assert.Equal(false, ok)
+	ok, _ = annotate(suite.whiteId, 1, "", game.NAG(9))
+	assert.Equal(false, ok)
+
+	ok, refusal = annotate(suite.whiteId, 1, "best by test", game.GoodMove)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// the move is white's to annotate until they clear it
+	ok, refusal = annotate(suite.blackId, 1, "dubious", game.Dubious)
+	assert.Equal(false, ok)
+	assert.Equal(commands.TurnAnnotated, refusal.Code)
+
+	history, ok := suite.Queries.GameHistory(gameId)
+	assert.Equal(true, ok)
+	assert.Equal(2, len(history))
+	assert.Equal("best by test", history[1].Comment)
+	assert.Equal(game.GoodMove, history[1].NAG)
+}
+
+func (suite *IntegrationTestSuite) TestConditionalMoves() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+	)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color": game.White,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId := suite.Queries.UserGames(suite.whiteId)[0]
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	move := func(userId users.Id, move game.AlgebraicMove) {
+		ok, refusal := suite.Commands.ExecCommand(
+			commands.Move, userId, map[string]interface{}{
+				"gameId": gameId,
+				"move":   move,
+			},
+		)
+		assert.Equal(true, ok, refusal.Message)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	plan := []conditionals.Condition{
+		{
+			Move:  "Pe7-e5",
+			Reply: "Ng1-f3",
+			Then: []conditionals.Condition{
+				{Move: "Nb8-c6", Reply: "Bf1-b5"},
+			},
+		},
+	}
+
+	// plans are only left while waiting on the opponent
+	err := suite.Conditionals.Submit(gameId, suite.whiteId, plan)
+	assert.NotNil(err)
+
+	move(suite.whiteId, "Pe2-e4")
+
+	err = suite.Conditionals.Submit(gameId, suite.whiteId, plan)
+	assert.Nil(err)
+	assert.Equal(plan, suite.Conditionals.Conditions(gameId, suite.whiteId))
+
+	// both of black's moves are answered
+	move(suite.blackId, "Pe7-e5")
+	time.Sleep(200 * time.Millisecond)
+
+	gameInfo, _ := suite.Queries.GameInformation(gameId)
+	assert.Equal(game.TurnNumber(3), gameInfo.TurnNumber)
+	assert.Equal(1, len(suite.Conditionals.Conditions(gameId, suite.whiteId)))
+
+	move(suite.blackId, "Nb8-c6")
+	time.Sleep(200 * time.Millisecond)
+
+	history, _ := suite.Queries.GameHistory(gameId)
+	assert.Equal(6, len(history))
+	assert.Equal(game.AlgebraicMove("Ng1-f3"), history[3].Move)
+	assert.Equal(game.AlgebraicMove("Bf1-b5"), history[5].Move)
+	assert.Equal(0, len(suite.Conditionals.Conditions(gameId, suite.whiteId)))
+
+	// a plan the opponent strays from is dropped
+	err = suite.Conditionals.Submit(gameId, suite.whiteId, []conditionals.Condition{
+		{Move: "Pa7-a6", Reply: "Bb5xc6"},
+	})
+	assert.Nil(err)
+
+	move(suite.blackId, "Ng8-f6")
+	time.Sleep(200 * time.Millisecond)
+
+	gameInfo, _ = suite.Queries.GameInformation(gameId)
+	assert.Equal(game.TurnNumber(6), gameInfo.TurnNumber)
+	assert.Equal(0, len(suite.Conditionals.Conditions(gameId, suite.whiteId)))
+}
+
+func (suite *IntegrationTestSuite) TestPremoves() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		blitz   game.TimeControl = game.TimeControl{Initial: 300}
+	)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":       game.White,
+			"timeControl": blitz,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId := suite.Queries.UserGames(suite.whiteId)[0]
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	move := func(userId users.Id, move game.AlgebraicMove) {
+		ok, refusal := suite.Commands.ExecCommand(
+			commands.Move, userId, map[string]interface{}{
+				"gameId": gameId,
+				"move":   move,
+			},
+		)
+		assert.Equal(true, ok, refusal.Message)
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// only the player waiting on their opponent may premove
+	err := suite.Conditionals.Premove(gameId, suite.whiteId, "Pe2-e4")
+	assert.NotNil(err)
+
+	err = suite.Conditionals.Premove(gameId, suite.blackId, "Pd7-d5")
+	assert.Nil(err)
+	queued, ok := suite.Conditionals.QueuedPremove(gameId, suite.blackId)
+	assert.Equal(true, ok)
+	assert.Equal(game.AlgebraicMove("Pd7-d5"), queued)
+
+	move(suite.whiteId, "Pe2-e4")
+	time.Sleep(200 * time.Millisecond)
+
+	history, _ := suite.Queries.GameHistory(gameId)
+	assert.Equal(3, len(history))
+	assert.Equal(game.AlgebraicMove("Pd7-d5"), history[2].Move)
+	_, ok = suite.Conditionals.QueuedPremove(gameId, suite.blackId)
+	assert.Equal(false, ok)
+
+	// a premove the opponent's move makes illegal is discarded
+	move(suite.whiteId, "Nb1-c3")
+
+	err = suite.Conditionals.Premove(gameId, suite.whiteId, "Pe4xd5")
+	assert.Nil(err)
+
+	move(suite.blackId, "Pd5xe4")
+	time.Sleep(200 * time.Millisecond)
+
+	gameInfo, _ := suite.Queries.GameInformation(gameId)
+	assert.Equal(game.TurnNumber(4), gameInfo.TurnNumber)
+	_, ok = suite.Conditionals.QueuedPremove(gameId, suite.whiteId)
+	assert.Equal(false, ok)
+}
+
+func (suite *IntegrationTestSuite) TestRatings() {
+	assert := assert.New(suite.T())
+	var (
+		ok      bool
+		refusal commands.Refusal
+		gameId  game.Id
+		blitz   game.TimeControl = game.TimeControl{Initial: 300}
+	)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":       game.White,
+			"opponent":    suite.blackId,
+			"timeControl": blitz,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId = suite.Queries.UserGames(suite.whiteId)[0]
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Concede, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, ok := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, ok)
+	assert.True(gameInfo.WhiteRating > 1500)
+	assert.True(gameInfo.BlackRating < 1500)
+	assert.Equal(gameInfo.WhiteRating, gameInfo.White.Ratings[game.Blitz])
+
+	history := suite.Queries.RatingHistory(suite.whiteId, game.Blitz)
+	assert.Equal(1, len(history))
+	assert.Equal(gameId, history[0].GameId)
+
+	// nothing was played in other categories
+	_, rated := gameInfo.White.Ratings[game.Bullet]
+	assert.Equal(false, rated)
+}
+
+func (suite *IntegrationTestSuite) TestRoundRobin() {
+	assert := assert.New(suite.T())
+
+	third := users.User{
+		Uuid:           users.NewId(),
+		Name:           "thirdPlayer",
+		AuthIdentifier: "thirdAuthId",
+	}
+	suite.users.Save(&third)
+
+	tournament, err := suite.Tournaments.Create(suite.whiteId, tournaments.Spec{
+		Name:    "Club Championship",
+		Format:  tournaments.RoundRobin,
+		Players: []users.Id{suite.whiteId, suite.blackId, third.Uuid},
+	})
+	assert.Nil(err)
+	assert.Equal(3, tournament.Rounds)
+
+	// games could never be created with a control this long
+	_, err = suite.Tournaments.Create(suite.whiteId, tournaments.Spec{
+		Name:        "Marathon",
+		Format:      tournaments.RoundRobin,
+		Players:     []users.Id{suite.whiteId, suite.blackId},
+		TimeControl: game.TimeControl{Initial: 4 * 60 * 60},
+	})
+	assert.NotNil(err)
+
+	// only the organizer may start it
+	assert.NotNil(suite.Tournaments.Begin(tournament.Id, suite.blackId))
+	assert.Nil(suite.Tournaments.Begin(tournament.Id, suite.whiteId))
+
+	time.Sleep(200 * time.Millisecond)
+
+	// the first seed has a bye, the others play
+	info, found := suite.Tournaments.Tournament(tournament.Id)
+	assert.Equal(true, found)
+	assert.Equal(1, info.CurrentRound)
+	assert.Equal(2, len(info.Results))
+
+	var gameId game.Id
+	for _, result := range info.Results {
+		if result.BlackId != "" {
+			gameId = result.GameId
+		}
+	}
+
+	gameInfo, found := suite.Queries.GameInformation(gameId)
+	assert.Equal(true, found)
+	assert.Equal(queries.GameStatusStarted, gameInfo.GameStatus)
+
+	ok, refusal := suite.Commands.ExecCommand(
+		commands.Concede, third.Uuid, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(200 * time.Millisecond)
+
+	info, _ = suite.Tournaments.Tournament(tournament.Id)
+	assert.Equal(2, info.CurrentRound)
+	assert.Equal(4, len(info.Results))
+	assert.Equal(suite.blackId, info.Standings[0].UserId)
+	assert.Equal(1.0, info.Standings[0].Score)
+}
+
+func (suite *IntegrationTestSuite) TestClaimTimeout() {
+	assert := assert.New(suite.T())
+
+	ok, refusal := suite.Commands.ExecCommand(
+		commands.CreateGame, suite.whiteId, map[string]interface{}{
+			"color":       game.White,
+			"opponent":    suite.blackId,
+			"timeControl": game.TimeControl{Initial: 1},
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameId := suite.Queries.UserGames(suite.whiteId)[0]
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.JoinGame, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	// white still has time
+	ok, _ = suite.Commands.ExecCommand(
+		commands.ClaimTimeout, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(false, ok)
+
+	time.Sleep(1 * time.Second)
+
+	ok, _ = suite.Commands.ExecCommand(
+		commands.Move, suite.whiteId, map[string]interface{}{
+			"gameId": gameId,
+			"move":   game.AlgebraicMove("Pe2-e4"),
+		},
+	)
+	assert.Equal(false, ok)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.ClaimTimeout, suite.blackId, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	gameInfo, _ := suite.Queries.GameInformation(gameId)
+	assert.Equal(queries.GameStatusEnded, gameInfo.GameStatus)
+	assert.Equal(game.GameEndTimeout, gameInfo.GameEndReason)
+	assert.Equal(game.Black, gameInfo.Winner)
+}
+
+func (suite *IntegrationTestSuite) TestArena() {
+	assert := assert.New(suite.T())
+
+	tournament, err := suite.Tournaments.Create(suite.whiteId, tournaments.Spec{
+		Name:        "Lunchtime Arena",
+		Format:      tournaments.Arena,
+		Players:     []users.Id{suite.whiteId, suite.blackId},
+		TimeControl: game.TimeControl{Initial: 180},
+		Minutes:     30,
+	})
+	assert.Nil(err)
+	assert.Nil(suite.Tournaments.Begin(tournament.Id, suite.whiteId))
+
+	time.Sleep(200 * time.Millisecond)
+
+	info, _ := suite.Tournaments.Tournament(tournament.Id)
+	assert.Equal(1, len(info.Results))
+	gameId := info.Results[0].GameId
+	winner := info.Results[0].WhiteId
+	loser := info.Results[0].BlackId
+
+	ok, refusal := suite.Commands.ExecCommand(
+		commands.Berserk, winner, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(100 * time.Millisecond)
+
+	ok, refusal = suite.Commands.ExecCommand(
+		commands.Concede, loser, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(200 * time.Millisecond)
+
+	// the players are paired again straight away
+	info, _ = suite.Tournaments.Tournament(tournament.Id)
+	assert.Equal(2, len(info.Results))
+	assert.Equal(winner, info.Standings[0].UserId)
+	assert.Equal(3.0, info.Standings[0].Score)
+}
+
+func (suite *IntegrationTestSuite) TestTeamMatch() {
+	assert := assert.New(suite.T())
+
+	tournament, err := suite.Tournaments.Create(suite.whiteId, tournaments.Spec{
+		Name:   "Inter-office Match",
+		Format: tournaments.TeamMatch,
+		Teams: []tournaments.Team{
+			{Name: "London", Players: []users.Id{suite.whiteId}},
+			{Name: "Leeds", Players: []users.Id{suite.blackId}},
+		},
+	})
+	assert.Nil(err)
+	assert.Nil(suite.Tournaments.Begin(tournament.Id, suite.whiteId))
+
+	time.Sleep(200 * time.Millisecond)
+
+	info, _ := suite.Tournaments.Tournament(tournament.Id)
+	assert.Equal(1, len(info.Results))
+	assert.Equal(suite.whiteId, info.Results[0].WhiteId)
+
+	ok, refusal := suite.Commands.ExecCommand(
+		commands.Concede, suite.blackId, map[string]interface{}{
+			"gameId": info.Results[0].GameId,
+		},
+	)
+	assert.Equal(true, ok, refusal.Message)
+
+	time.Sleep(200 * time.Millisecond)
+
+	info, _ = suite.Tournaments.Tournament(tournament.Id)
+	assert.Equal(tournaments.StatusFinished, info.Status)
+	assert.Equal(1.0, info.Teams[0].Score)
+	assert.Equal(0.0, info.Teams[1].Score)
+}
+
 func TestIntegration(t *testing.T) {
 	suite.Run(t, new(IntegrationTestSuite))
 }
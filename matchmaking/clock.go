@@ -0,0 +1,22 @@
+package matchmaking
+
+import (
+	"time"
+)
+
+// Clock is the matchmaker's source of time, so that tests can control
+// when seeks expire
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (c realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (c realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,239 @@
+package matchmaking
+
+import (
+	"github.com/op/go-logging"
+	"time"
+
+	"foodtastechess/commands"
+	"foodtastechess/game"
+	"foodtastechess/logger"
+	"foodtastechess/users"
+)
+
+const (
+	// how often waiting seeks are checked on
+	tickInterval = 250 * time.Millisecond
+
+	// how long a seek waits for an opponent before it is dropped
+	seekTimeout = 10 * time.Minute
+
+	// how many times joining a paired game is tried before giving up
+	maxJoinAttempts = 20
+)
+
+// Matchmaker pairs players looking for a game with compatible
+// opponents, and creates the game for them
+type Matchmaker interface {
+	Seek(seek Seek) error
+	Cancel(userId users.Id)
+	Seeks() []Seek
+}
+
+// GameIdSource hands out ids for new games
+type GameIdSource interface {
+	NextGameId() game.Id
+}
+
+// RatingSource looks up a player's rating for a category of game
+type RatingSource interface {
+	Rating(userId users.Id, category game.TimeCategory) float64
+}
+
+type request struct {
+	seek   *Seek
+	cancel users.Id
+	list   chan []Seek
+}
+
+// match is a paired game that still needs to be joined by black
+type match struct {
+	gameId   game.Id
+	blackId  users.Id
+	attempts int
+}
+
+type MatchmakingService struct {
+	log      *logging.Logger
+	Commands commands.Executor `inject:"commands"`
+	Events   GameIdSource      `inject:"events"`
+	Ratings  RatingSource      `inject:"ratings"`
+	Clock    Clock
+
+	requests chan request
+	stopChan chan bool
+
+	// only touched by the processing goroutine
+	seeks   []Seek
+	pending []match
+}
+
+func New() Matchmaker {
+	s := new(MatchmakingService)
+	s.log = logger.Log("matchmaking")
+	s.Clock = realClock{}
+	s.requests = make(chan request, 100)
+	s.stopChan = make(chan bool, 1)
+	s.seeks = []Seek{}
+	s.pending = []match{}
+	return s
+}
+
+func (s *MatchmakingService) Start() error {
+	s.log.Notice("Matching seeks")
+	go s.Process()
+	return nil
+}
+
+func (s *MatchmakingService) Process() {
+	for {
+		select {
+		case req := <-s.requests:
+			s.handle(req)
+		case <-s.Clock.After(tickInterval):
+			s.tick()
+		case <-s.stopChan:
+			s.log.Info("Matchmaking stopped")
+			return
+		}
+	}
+}
+
+func (s *MatchmakingService) Stop() error {
+	s.log.Notice("Stopping matchmaking")
+	s.stopChan <- true
+	return nil
+}
+
+// Seek queues a seek, replacing any seek the user already has waiting
+func (s *MatchmakingService) Seek(seek Seek) error {
+	if seek.Variant == "" {
+		seek.Variant = Standard
+	}
+
+	if seek.Variant != Standard {
+		return commands.Refuse(commands.UnsupportedVariant, "Only standard chess is supported.")
+	}
+
+	if seek.MinRating != 0 && seek.MaxRating != 0 && seek.MinRating > seek.MaxRating {
+		return commands.Refuse(commands.InvalidSeek, "Rating range is empty.")
+	}
+
+	seek.Rating = s.Ratings.Rating(seek.UserId, seek.TimeControl.Category())
+	seek.CreatedAt = s.Clock.Now()
+
+	s.requests <- request{seek: &seek}
+	return nil
+}
+
+// Cancel drops a user's waiting seek
+func (s *MatchmakingService) Cancel(userId users.Id) {
+	s.requests <- request{cancel: userId}
+}
+
+// Seeks lists the seeks waiting for an opponent
+func (s *MatchmakingService) Seeks() []Seek {
+	list := make(chan []Seek, 1)
+	s.requests <- request{list: list}
+	return <-list
+}
+
+func (s *MatchmakingService) handle(req request) {
+	switch {
+	case req.seek != nil:
+		s.remove(req.seek.UserId)
+		s.add(*req.seek)
+	case req.cancel != "":
+		s.remove(req.cancel)
+	case req.list != nil:
+		seeks := make([]Seek, len(s.seeks))
+		copy(seeks, s.seeks)
+		req.list <- seeks
+	}
+}
+
+// add pairs a seek with the longest waiting compatible seek, or leaves
+// it waiting if there is none
+func (s *MatchmakingService) add(seek Seek) {
+	for i, waiting := range s.seeks {
+		if compatible(waiting, seek) {
+			s.seeks = append(s.seeks[:i], s.seeks[i+1:]...)
+			s.pair(waiting, seek)
+			return
+		}
+	}
+
+	s.seeks = append(s.seeks, seek)
+}
+
+func (s *MatchmakingService) remove(userId users.Id) {
+	seeks := []Seek{}
+	for _, seek := range s.seeks {
+		if seek.UserId != userId {
+			seeks = append(seeks, seek)
+		}
+	}
+	s.seeks = seeks
+}
+
+// pair creates a game between two seekers as a challenge from white to
+// black, which black then joins
+func (s *MatchmakingService) pair(older, newer Seek) {
+	white, black := colors(older, newer)
+	gameId := s.Events.NextGameId()
+
+	ok, refusal := s.Commands.ExecCommand(
+		commands.CreateGame, white.UserId, map[string]interface{}{
+			"gameId":      gameId,
+			"color":       game.White,
+			"opponent":    black.UserId,
+			"timeControl": older.TimeControl,
+		},
+	)
+	if !ok {
+		s.log.Error("Could not create game for %s and %s: %s", white.UserId, black.UserId, refusal)
+		return
+	}
+
+	s.pending = append(s.pending, match{gameId: gameId, blackId: black.UserId})
+	s.joinPending()
+}
+
+// joinPending has black join each paired game. Queries catch up with
+// new games asynchronously, so joins that fail are tried again later.
+func (s *MatchmakingService) joinPending() {
+	pending := []match{}
+
+	for _, m := range s.pending {
+		ok, refusal := s.Commands.ExecCommand(
+			commands.JoinGame, m.blackId, map[string]interface{}{
+				"gameId": m.gameId,
+			},
+		)
+		if ok {
+			continue
+		}
+
+		m.attempts += 1
+		if m.attempts >= maxJoinAttempts {
+			s.log.Error("Could not start game %v: %s", m.gameId, refusal)
+			continue
+		}
+
+		pending = append(pending, m)
+	}
+
+	s.pending = pending
+}
+
+func (s *MatchmakingService) tick() {
+	s.joinPending()
+
+	now := s.Clock.Now()
+	seeks := []Seek{}
+	for _, seek := range s.seeks {
+		if now.Sub(seek.CreatedAt) < seekTimeout {
+			seeks = append(seeks, seek)
+		}
+	}
+	s.seeks = seeks
+}
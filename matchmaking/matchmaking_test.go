@@ -0,0 +1,214 @@
+package matchmaking
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+
+	"foodtastechess/commands"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type MatchmakingTestSuite struct {
+	suite.Suite
+
+	service      *MatchmakingService
+	mockCommands *MockCommands
+	mockIds      *MockGameIds
+	clock        *fakeClock
+}
+
+func (suite *MatchmakingTestSuite) SetupTest() {
+	suite.mockCommands = new(MockCommands)
+	suite.mockIds = new(MockGameIds)
+	suite.clock = &fakeClock{now: time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC)}
+
+	suite.service = New().(*MatchmakingService)
+	suite.service.Commands = suite.mockCommands
+	suite.service.Events = suite.mockIds
+	suite.service.Ratings = ratingsTable{"alice": 1500, "bob": 1550, "carol": 2200}
+	suite.service.Clock = suite.clock
+}
+
+// seek queues a seek and handles it straight away, as the processing
+// loop would
+func (suite *MatchmakingTestSuite) seek(seek Seek) {
+	err := suite.service.Seek(seek)
+	assert.Nil(suite.T(), err)
+	suite.service.handle(<-suite.service.requests)
+}
+
+func (suite *MatchmakingTestSuite) TestPairing() {
+	var (
+		blitz  game.TimeControl = game.TimeControl{Initial: 300}
+		gameId game.Id          = 7
+	)
+
+	suite.mockIds.On("NextGameId").Return(gameId)
+	suite.mockCommands.
+		On("ExecCommand", commands.CreateGame, users.Id("alice"), map[string]interface{}{
+			"gameId":      gameId,
+			"color":       game.White,
+			"opponent":    users.Id("bob"),
+			"timeControl": blitz,
+		}).
+		Return(true, commands.Refusal{})
+	suite.mockCommands.
+		On("ExecCommand", commands.JoinGame, users.Id("bob"), map[string]interface{}{
+			"gameId": gameId,
+		}).
+		Return(true, commands.Refusal{})
+
+	assert := assert.New(suite.T())
+
+	// different time controls don't match
+	suite.seek(Seek{UserId: "alice", TimeControl: blitz})
+	suite.seek(Seek{UserId: "bob", TimeControl: game.TimeControl{Initial: 60}})
+	assert.Equal(2, len(suite.service.seeks))
+
+	// bob's new seek replaces his old one and matches alice, who has
+	// waited longer and so plays white
+	suite.seek(Seek{UserId: "bob", TimeControl: blitz})
+	assert.Equal(0, len(suite.service.seeks))
+	assert.Equal(0, len(suite.service.pending))
+	suite.mockCommands.AssertExpectations(suite.T())
+}
+
+func (suite *MatchmakingTestSuite) TestRatingRange() {
+	assert := assert.New(suite.T())
+
+	// carol is out of alice's range, and neither is in bob's
+	suite.seek(Seek{UserId: "alice", MaxRating: 1800})
+	suite.seek(Seek{UserId: "carol"})
+	suite.seek(Seek{UserId: "bob", MinRating: 1520, MaxRating: 1600})
+	assert.Equal(3, len(suite.service.seeks))
+
+	suite.mockCommands.AssertNotCalled(suite.T(), "ExecCommand", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *MatchmakingTestSuite) TestColorPreference() {
+	var gameId game.Id = 3
+
+	suite.mockIds.On("NextGameId").Return(gameId)
+	suite.mockCommands.
+		On("ExecCommand", commands.CreateGame, users.Id("bob"), mock.Anything).
+		Return(true, commands.Refusal{})
+	suite.mockCommands.
+		On("ExecCommand", commands.JoinGame, users.Id("alice"), mock.Anything).
+		Return(true, commands.Refusal{})
+
+	assert := assert.New(suite.T())
+
+	// two players wanting white can't play each other
+	suite.seek(Seek{UserId: "alice", Color: game.White})
+	suite.seek(Seek{UserId: "carol", Color: game.White})
+	assert.Equal(2, len(suite.service.seeks))
+
+	suite.service.Cancel("alice")
+	suite.service.handle(<-suite.service.requests)
+	suite.service.Cancel("carol")
+	suite.service.handle(<-suite.service.requests)
+
+	suite.seek(Seek{UserId: "alice", Color: game.Black})
+	suite.seek(Seek{UserId: "bob"})
+	assert.Equal(0, len(suite.service.seeks))
+	suite.mockCommands.AssertExpectations(suite.T())
+}
+
+func (suite *MatchmakingTestSuite) TestJoinRetry() {
+	var gameId game.Id = 4
+
+	suite.mockIds.On("NextGameId").Return(gameId)
+	suite.mockCommands.
+		On("ExecCommand", commands.CreateGame, users.Id("alice"), mock.Anything).
+		Return(true, commands.Refusal{})
+	suite.mockCommands.
+		On("ExecCommand", commands.JoinGame, users.Id("bob"), mock.Anything).
+		Return(false, commands.Refuse(commands.GameNotFound, "Game does not exist.")).Once()
+	suite.mockCommands.
+		On("ExecCommand", commands.JoinGame, users.Id("bob"), mock.Anything).
+		Return(true, commands.Refusal{})
+
+	assert := assert.New(suite.T())
+
+	suite.seek(Seek{UserId: "alice"})
+	suite.seek(Seek{UserId: "bob"})
+	assert.Equal(1, len(suite.service.pending))
+
+	suite.service.tick()
+	assert.Equal(0, len(suite.service.pending))
+}
+
+func (suite *MatchmakingTestSuite) TestExpiry() {
+	assert := assert.New(suite.T())
+
+	suite.seek(Seek{UserId: "alice"})
+	suite.clock.advance(seekTimeout / 2)
+	suite.seek(Seek{UserId: "carol", MinRating: 2000})
+
+	suite.service.tick()
+	assert.Equal(2, len(suite.service.seeks))
+
+	suite.clock.advance(seekTimeout / 2)
+	suite.service.tick()
+	assert.Equal(1, len(suite.service.seeks))
+	assert.Equal(users.Id("carol"), suite.service.seeks[0].UserId)
+}
+
+func (suite *MatchmakingTestSuite) TestInvalidSeek() {
+	assert := assert.New(suite.T())
+
+	assert.NotNil(suite.service.Seek(Seek{UserId: "alice", Variant: "chess960"}))
+	assert.NotNil(suite.service.Seek(Seek{UserId: "alice", MinRating: 1800, MaxRating: 1600}))
+	assert.Equal(0, len(suite.service.requests))
+}
+
+func TestMatchmakingTestSuite(t *testing.T) {
+	suite.Run(t, new(MatchmakingTestSuite))
+}
+
+// fakeClock only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+type ratingsTable map[users.Id]float64
+
+func (r ratingsTable) Rating(userId users.Id, category game.TimeCategory) float64 {
+	return r[userId]
+}
+
+// MockCommands is a mock for the commands service
+type MockCommands struct {
+	mock.Mock
+}
+
+func (m *MockCommands) ExecCommand(name string, userId users.Id, params map[string]interface{}) (bool, commands.Refusal) {
+	args := m.Called(name, userId, params)
+	return args.Bool(0), args.Get(1).(commands.Refusal)
+}
+
+// MockGameIds is a mock for the events service's id generator
+type MockGameIds struct {
+	mock.Mock
+}
+
+func (m *MockGameIds) NextGameId() game.Id {
+	args := m.Called()
+	return args.Get(0).(game.Id)
+}
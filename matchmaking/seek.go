@@ -0,0 +1,65 @@
+package matchmaking
+
+import (
+	"time"
+
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+const (
+	Standard = "standard"
+)
+
+// Seek is a request to be paired with any compatible opponent. The
+// rating bounds apply to the opponent's rating, and are ignored when 0.
+type Seek struct {
+	UserId      users.Id
+	TimeControl game.TimeControl
+	Variant     string
+	Color       game.Color
+	MinRating   float64
+	MaxRating   float64
+
+	Rating    float64
+	CreatedAt time.Time
+}
+
+// accepts reports whether a seeker would play an opponent with the
+// given rating
+func (s Seek) accepts(rating float64) bool {
+	if s.MinRating != 0 && rating < s.MinRating {
+		return false
+	} else if s.MaxRating != 0 && rating > s.MaxRating {
+		return false
+	} else {
+		return true
+	}
+}
+
+// compatible reports whether two seeks can be paired with each other
+func compatible(a, b Seek) bool {
+	if a.UserId == b.UserId {
+		return false
+	}
+
+	if a.TimeControl != b.TimeControl || a.Variant != b.Variant {
+		return false
+	}
+
+	if a.Color != game.NoOne && a.Color == b.Color {
+		return false
+	}
+
+	return a.accepts(b.Rating) && b.accepts(a.Rating)
+}
+
+// colors decides who plays white, honouring either player's preference
+// and otherwise giving white to the player who has waited longest
+func colors(older, newer Seek) (white, black Seek) {
+	if older.Color == game.Black || newer.Color == game.White {
+		return newer, older
+	} else {
+		return older, newer
+	}
+}
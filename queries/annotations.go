@@ -0,0 +1,77 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+// Annotation is a move's annotation and the player who wrote it
+type Annotation struct {
+	game.Annotation `bson:",inline"`
+	AuthorId        users.Id
+}
+
+// annotationsQuery finds the annotations of a game's moves, in turn
+// order. A move annotated more than once keeps its latest annotation,
+// and one whose annotation was cleared is left out.
+type annotationsQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   []Annotation
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *annotationsQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *annotationsQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *annotationsQuery) computeResult(queries SystemQueries) {
+	latest := make(map[game.TurnNumber]Annotation)
+	lastTurn := game.TurnNumber(0)
+
+	annotationEvents := queries.getEvents().
+		EventsOfTypeForGame(q.GameId, events.AnnotationType)
+
+	for _, event := range annotationEvents {
+		latest[event.TurnNumber] = Annotation{
+			Annotation: game.Annotation{
+				TurnNumber: event.TurnNumber,
+				Comment:    event.Message,
+				NAG:        event.Glyph,
+			},
+			AuthorId: event.AuthorId,
+		}
+
+		if event.TurnNumber > lastTurn {
+			lastTurn = event.TurnNumber
+		}
+	}
+
+	q.Result = []Annotation{}
+	for turn := game.TurnNumber(1); turn <= lastTurn; turn++ {
+		annotation, ok := latest[turn]
+		if ok && (annotation.Comment != "" || annotation.NAG != game.NoNAG) {
+			q.Result = append(q.Result, annotation)
+		}
+	}
+
+	q.Answered = true
+}
+
+func (q *annotationsQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *annotationsQuery) hash() string {
+	return fmt.Sprintf("annotations:%v", q.GameId)
+}
@@ -0,0 +1,69 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type AnnotationsQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *AnnotationsQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *annotationsQuery
+	)
+
+	hasResult = AnnotationsQuery(gameId).(*annotationsQuery)
+	hasResult.Answered = true
+
+	noResult = AnnotationsQuery(gameId).(*annotationsQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *AnnotationsQueryTestSuite) TestComputeResult() {
+	var (
+		gameId game.Id  = 5
+		coach  users.Id = "coach"
+		player users.Id = "player"
+		query  *annotationsQuery
+	)
+
+	// turn 3 is annotated twice, turn 2's annotation is cleared and the
+	// player annotates turn 4
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.AnnotationType).
+		Return([]events.Event{
+			events.NewAnnotationEvent(gameId, 3, coach, "", game.Mistake),
+			events.NewAnnotationEvent(gameId, 2, coach, "develops", game.NoNAG),
+			events.NewAnnotationEvent(gameId, 1, coach, "solid", game.GoodMove),
+			events.NewAnnotationEvent(gameId, 3, coach, "loses a pawn", game.Blunder),
+			events.NewAnnotationEvent(gameId, 2, coach, "", game.NoNAG),
+			events.NewAnnotationEvent(gameId, 4, player, "only move", game.NoNAG),
+		})
+
+	query = AnnotationsQuery(gameId).(*annotationsQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, query.Answered)
+	assert.Equal([]Annotation{
+		{game.Annotation{TurnNumber: 1, Comment: "solid", NAG: game.GoodMove}, coach},
+		{game.Annotation{TurnNumber: 3, Comment: "loses a pawn", NAG: game.Blunder}, coach},
+		{game.Annotation{TurnNumber: 4, Comment: "only move", NAG: game.NoNAG}, player},
+	}, query.Result)
+}
+
+func TestAnnotationsQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(AnnotationsQueryTestSuite))
+}
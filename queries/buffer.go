@@ -2,23 +2,32 @@ package queries
 
 import (
 	"github.com/op/go-logging"
+	"sync"
 
 	"foodtastechess/events"
+	"foodtastechess/game"
 	"foodtastechess/logger"
 )
 
+// QueryBuffer recomputes the queries affected by each event it receives,
+// then passes the event on to its own subscribers, who can rely on
+// queries reflecting the event by the time they hear about it.
 type QueryBuffer struct {
 	log           *logging.Logger
-	queries       chan Query
+	events        chan events.Event
 	SystemQueries SystemQueries `inject:"systemQueries"`
 	stopChan      chan bool
+
+	subscribersLock sync.Mutex
+	subscribers     []events.EventSubscriber
 }
 
 func NewQueryBuffer() events.EventSubscriber {
 	buffer := new(QueryBuffer)
 	buffer.log = logger.Log("querybuffer")
-	buffer.queries = make(chan Query, 100)
+	buffer.events = make(chan events.Event, 100)
 	buffer.stopChan = make(chan bool, 1)
+	buffer.subscribers = []events.EventSubscriber{}
 	return buffer
 }
 
@@ -31,9 +40,12 @@ func (b *QueryBuffer) Start() error {
 func (b *QueryBuffer) Process() {
 	for {
 		select {
-		case query := <-b.queries:
-			b.log.Info("Got query")
-			b.SystemQueries.computeAnswer(query, false)
+		case event := <-b.events:
+			b.log.Info("Got event")
+			for _, query := range translateEvent(event, b.SystemQueries) {
+				b.SystemQueries.computeAnswer(query, false)
+			}
+			b.publish(event)
 		case <-b.stopChan:
 			b.log.Info("QueryBuffer stopped")
 			return
@@ -48,24 +60,62 @@ func (b *QueryBuffer) Stop() error {
 }
 
 func (b *QueryBuffer) Receive(event events.Event) error {
-	queries := translateEvent(event)
+	b.events <- event
+	return nil
+}
 
-	for _, query := range queries {
-		b.queries <- query
-	}
+// Subscribe registers a subscriber to hear about events once their
+// queries have been recomputed
+func (b *QueryBuffer) Subscribe(subscriber events.EventSubscriber) {
+	b.subscribersLock.Lock()
+	defer b.subscribersLock.Unlock()
 
-	return nil
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+func (b *QueryBuffer) publish(event events.Event) {
+	b.subscribersLock.Lock()
+	subscribers := b.subscribers
+	b.subscribersLock.Unlock()
+
+	for _, subscriber := range subscribers {
+		err := subscriber.Receive(event)
+		if err != nil {
+			b.log.Error("Subscriber could not receive event: %v", err)
+		}
+	}
 }
 
-func translateEvent(event events.Event) []Query {
+func translateEvent(event events.Event, systemQueries SystemQueries) []Query {
 	switch event.Type {
 	case events.MoveType:
+		// a move may replace one that was taken back, so anything
+		// computed for its turn is stale
+		return []Query{
+			TurnNumberQuery(event.GameId),
+			MoveAtTurnQuery(event.GameId, event.TurnNumber),
+			BoardAtTurnQuery(event.GameId, event.TurnNumber),
+			ValidMovesAtTurnQuery(event.GameId, event.TurnNumber),
+			ClockQuery(event.GameId),
+		}
+	case events.MoveRetractType:
 		return []Query{
 			TurnNumberQuery(event.GameId),
+			ClockQuery(event.GameId),
 		}
 	case events.GameCreateType:
 		queries := []Query{
 			GameQuery(event.GameId),
+			PreviousGameQuery(event.GameId),
+			InvitationQuery(event.GameId),
+			TimeControlQuery(event.GameId),
+			VisibilityQuery(event.GameId),
+			ClockQuery(event.GameId),
+			OpenGamesQuery(),
+		}
+
+		if event.InviteCode != "" {
+			queries = append(queries, InviteCodeQuery(event.InviteCode))
 		}
 
 		if event.WhiteId != "" {
@@ -83,14 +133,45 @@ func translateEvent(event events.Event) []Query {
 			GamePlayersQuery(event.GameId),
 			UserGamesQuery(event.WhiteId),
 			UserGamesQuery(event.BlackId),
+			OpenGamesQuery(),
+			LiveGamesQuery(),
+			HeadToHeadQuery(event.WhiteId, event.BlackId),
+			ClockQuery(event.GameId),
 		}
 	case events.GameEndType:
-		return []Query{
+		queries := []Query{
 			GameQuery(event.GameId),
 			GameEndQuery(event.GameId),
-			UserGamesQuery(event.WhiteId),
-			UserGamesQuery(event.BlackId),
+			ClockQuery(event.GameId),
+			OpenGamesQuery(),
+			LiveGamesQuery(),
+		}
+
+		// the event doesn't say which category the game was in, but
+		// its time control is never recomputed
+		timeControl := systemQueries.AnswerQuery(TimeControlQuery(event.GameId)).(game.TimeControl)
+		queries = append(queries, LeaderboardQuery(timeControl.Category()))
+
+		// games aborted before anyone joined only have one player
+		if event.WhiteId != "" {
+			queries = append(queries,
+				UserGamesQuery(event.WhiteId),
+				PlayerStatsQuery(event.WhiteId),
+			)
 		}
+
+		if event.BlackId != "" {
+			queries = append(queries,
+				UserGamesQuery(event.BlackId),
+				PlayerStatsQuery(event.BlackId),
+			)
+		}
+
+		if event.WhiteId != "" && event.BlackId != "" {
+			queries = append(queries, HeadToHeadQuery(event.WhiteId, event.BlackId))
+		}
+
+		return queries
 	case events.DrawOfferType:
 		return []Query{
 			DrawOfferStateQuery(event.GameId),
@@ -99,6 +180,38 @@ func translateEvent(event events.Event) []Query {
 		return []Query{
 			DrawOfferStateQuery(event.GameId),
 		}
+	case events.DrawOfferWithdrawType:
+		return []Query{
+			DrawOfferStateQuery(event.GameId),
+		}
+	case events.RematchOfferType:
+		return []Query{
+			RematchQuery(event.GameId),
+		}
+	case events.RematchResponseType:
+		return []Query{
+			RematchQuery(event.GameId),
+		}
+	case events.TakebackRequestType:
+		return []Query{
+			TakebackStateQuery(event.GameId),
+		}
+	case events.TakebackResponseType:
+		return []Query{
+			TakebackStateQuery(event.GameId),
+		}
+	case events.BerserkType:
+		return []Query{
+			ClockQuery(event.GameId),
+		}
+	case events.ChatType, events.SpectatorChatType:
+		return []Query{
+			ChatQuery(event.GameId),
+		}
+	case events.AnnotationType:
+		return []Query{
+			AnnotationsQuery(event.GameId),
+		}
 	default:
 		return []Query{}
 	}
@@ -0,0 +1,83 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+// ChatChannel separates what a game's players say from what its
+// spectators say
+type ChatChannel string
+
+const (
+	PlayerChat    ChatChannel = "player"
+	SpectatorChat ChatChannel = "spectator"
+)
+
+// ChatMessage is something said in a game's chat
+type ChatMessage struct {
+	Channel  ChatChannel
+	AuthorId users.Id `json:"-"`
+	Author   users.User
+	Message  string
+	SentAt   time.Time
+}
+
+// NewChatMessage reads the message out of a chat event, leaving the
+// author to be looked up
+func NewChatMessage(event events.Event) ChatMessage {
+	channel := PlayerChat
+	if event.Type == events.SpectatorChatType {
+		channel = SpectatorChat
+	}
+
+	return ChatMessage{
+		Channel:  channel,
+		AuthorId: event.AuthorId,
+		Message:  event.Message,
+		SentAt:   event.CreatedAt,
+	}
+}
+
+// chatQuery lists the messages sent in a game's chat, oldest first
+type chatQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   []ChatMessage
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *chatQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *chatQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *chatQuery) computeResult(queries SystemQueries) {
+	q.Result = []ChatMessage{}
+
+	for _, event := range queries.getEvents().EventsForGame(q.GameId) {
+		if event.Type == events.ChatType || event.Type == events.SpectatorChatType {
+			q.Result = append(q.Result, NewChatMessage(event))
+		}
+	}
+
+	q.Answered = true
+}
+
+func (q *chatQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *chatQuery) hash() string {
+	return fmt.Sprintf("chat:%v", q.GameId)
+}
@@ -0,0 +1,73 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type ChatQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *ChatQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *chatQuery
+	)
+
+	hasResult = ChatQuery(gameId).(*chatQuery)
+	hasResult.Answered = true
+
+	noResult = ChatQuery(gameId).(*chatQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *ChatQueryTestSuite) TestComputeResult() {
+	var (
+		gameId    game.Id  = 5
+		whiteId   users.Id = "alice"
+		blackId   users.Id = "bob"
+		spectator users.Id = "carol"
+		query     *chatQuery
+	)
+
+	suite.mockEvents.
+		On("EventsForGame", gameId).
+		Return([]events.Event{
+			events.NewGameStartEvent(gameId, whiteId, blackId),
+			events.NewChatEvent(gameId, whiteId, "good luck"),
+			events.NewMoveEvent(gameId, 1, "Pe2-e4"),
+			events.NewSpectatorChatEvent(gameId, spectator, "bold"),
+			events.NewChatEvent(gameId, blackId, "you too"),
+		})
+
+	query = ChatQuery(gameId).(*chatQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, query.Answered)
+	assert.Equal(3, len(query.Result))
+
+	assert.Equal(PlayerChat, query.Result[0].Channel)
+	assert.Equal(whiteId, query.Result[0].AuthorId)
+	assert.Equal("good luck", query.Result[0].Message)
+
+	assert.Equal(SpectatorChat, query.Result[1].Channel)
+	assert.Equal(spectator, query.Result[1].AuthorId)
+
+	assert.Equal(PlayerChat, query.Result[2].Channel)
+	assert.Equal(blackId, query.Result[2].AuthorId)
+}
+
+func TestChatQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(ChatQueryTestSuite))
+}
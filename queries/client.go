@@ -3,9 +3,11 @@ package queries
 import (
 	"github.com/op/go-logging"
 	"strings"
+	"time"
 
 	"foodtastechess/game"
 	"foodtastechess/logger"
+	"foodtastechess/ratings"
 	"foodtastechess/users"
 )
 
@@ -14,8 +16,19 @@ import (
 type ClientQueries interface {
 	UserGames(userId users.Id) []game.Id
 	GameInformation(id game.Id) (GameInformation, bool)
+	GamePlayers(id game.Id) map[game.Color]users.Id
 	GameHistory(id game.Id) ([]game.MoveRecord, bool)
+	Annotations(id game.Id) []Annotation
 	ValidMoves(id game.Id) ([]game.MoveRecord, bool)
+	InvitedGame(inviteCode string) (game.Id, bool)
+	Lobby(userId users.Id, filter LobbyFilter) []LobbyEntry
+	LiveGames() []LiveGame
+	Chat(gameId game.Id) ([]ChatMessage, bool)
+	User(userId users.Id) (users.User, bool)
+	RatingHistory(userId users.Id, category game.TimeCategory) []ratings.HistoryEntry
+	Leaderboard(category game.TimeCategory, limit int) LeaderboardInformation
+	PlayerStats(userId users.Id) (PlayerStats, bool)
+	HeadToHead(playerId, opponentId users.Id) HeadToHead
 }
 
 // ClientQueryService provides a concrete implementation of the
@@ -25,8 +38,9 @@ type ClientQueries interface {
 // aggregate information for the supported methods
 type ClientQueryService struct {
 	log           *logging.Logger
-	Users         users.Users   `inject:"users"`
-	SystemQueries SystemQueries `inject:"systemQueries"`
+	Users         users.Users     `inject:"users"`
+	Ratings       ratings.Ratings `inject:"ratings"`
+	SystemQueries SystemQueries   `inject:"systemQueries"`
 }
 
 func NewClientQueryService() *ClientQueryService {
@@ -61,6 +75,24 @@ type GameEnd struct {
 	Winner   game.Color
 }
 
+// SeriesScore totals the results of a game and the games it is a rematch
+// of, from the point of view of the game's current players
+type SeriesScore struct {
+	Games int
+	White float64
+	Black float64
+}
+
+// GameClock is the time each player of a timed game has left, in
+// milliseconds, as of when it was asked for
+type GameClock struct {
+	White        int64
+	Black        int64
+	Running      game.Color `json:",omitempty"`
+	WhiteBerserk bool
+	BlackBerserk bool
+}
+
 type GameInformation struct {
 	Id                   game.Id
 	TurnNumber           game.TurnNumber
@@ -70,9 +102,33 @@ type GameInformation struct {
 	Black                users.User
 	GameStatus           GameStatus
 	OutstandingDrawOffer bool
+	OutstandingTakeback  bool
 	DrawOfferer          game.Color         `json:",omitempty"`
+	TakebackRequester    game.Color         `json:",omitempty"`
 	Winner               game.Color         `json:",omitempty"`
 	GameEndReason        game.GameEndReason `json:",omitempty"`
+	PreviousGameId       game.Id            `json:",omitempty"`
+	RematchGameId        game.Id            `json:",omitempty"`
+	RematchOfferer       game.Color         `json:",omitempty"`
+	Invitee              game.Color         `json:",omitempty"`
+	InviteCode           string             `json:",omitempty"`
+	Private              bool
+	Visibility           game.Visibility
+	TimeControl          game.TimeControl
+	TimeCategory         game.TimeCategory
+	Clock                *GameClock `json:",omitempty"`
+	WhiteRating          float64
+	BlackRating          float64
+	Series               SeriesScore
+	HeadToHead           HeadToHeadSummary
+}
+
+// VisibleTo reports whether a user may view the game: anyone may view a
+// public game, but only its players a private one
+func (g GameInformation) VisibleTo(userId users.Id) bool {
+	return g.Visibility != game.Private ||
+		userId == g.White.Uuid ||
+		userId == g.Black.Uuid
 }
 
 // GameInformation accepts a game ID and queries the SQS for GameInformation
@@ -104,12 +160,12 @@ func (s *ClientQueryService) GameInformation(id game.Id) (GameInformation, bool)
 	gamePlayersQ := GamePlayersQuery(id)
 	gamePlayers := s.SystemQueries.AnswerQuery(gamePlayersQ).(map[game.Color]users.Id)
 
-	white, found := s.Users.Get(gamePlayers[game.White])
+	white, found := s.User(gamePlayers[game.White])
 	if found {
 		gameInfo.White = white
 	}
 
-	black, found := s.Users.Get(gamePlayers[game.Black])
+	black, found := s.User(gamePlayers[game.Black])
 	if found {
 		gameInfo.Black = black
 	}
@@ -123,17 +179,357 @@ func (s *ClientQueryService) GameInformation(id game.Id) (GameInformation, bool)
 		gameInfo.DrawOfferer = drawOfferer
 	}
 
+	takebackStateQ := TakebackStateQuery(id)
+	takebackRequester := s.SystemQueries.AnswerQuery(takebackStateQ).(game.Color)
+	if takebackRequester == game.NoOne {
+		gameInfo.OutstandingTakeback = false
+	} else {
+		gameInfo.OutstandingTakeback = true
+		gameInfo.TakebackRequester = takebackRequester
+	}
+
 	if gameInfo.GameStatus == GameStatusEnded {
 		gameEndQ := GameEndQuery(id)
 		gameEnd := s.SystemQueries.AnswerQuery(gameEndQ).(GameEnd)
 
 		gameInfo.GameEndReason = gameEnd.Reason
 		gameInfo.Winner = gameEnd.Winner
+
+		rematchQ := RematchQuery(id)
+		rematch := s.SystemQueries.AnswerQuery(rematchQ).(Rematch)
+		if rematch.Offered && (!rematch.Answered || rematch.Accepted) {
+			gameInfo.RematchGameId = rematch.GameId
+			gameInfo.RematchOfferer = rematch.Offerer
+		}
+	}
+
+	invitationQ := InvitationQuery(id)
+	invitation := s.SystemQueries.AnswerQuery(invitationQ).(Invitation)
+	gameInfo.Invitee = invitation.Invitee
+	gameInfo.InviteCode = invitation.Code
+	gameInfo.Private = invitation.Private()
+
+	visibilityQ := VisibilityQuery(id)
+	gameInfo.Visibility = s.SystemQueries.AnswerQuery(visibilityQ).(game.Visibility)
+
+	timeControlQ := TimeControlQuery(id)
+	gameInfo.TimeControl = s.SystemQueries.AnswerQuery(timeControlQ).(game.TimeControl)
+	gameInfo.TimeCategory = gameInfo.TimeControl.Category()
+	gameInfo.WhiteRating = ratingIn(gameInfo.White.Ratings, gameInfo.TimeCategory)
+	gameInfo.BlackRating = ratingIn(gameInfo.Black.Ratings, gameInfo.TimeCategory)
+
+	if !gameInfo.TimeControl.Untimed() {
+		clock := s.SystemQueries.AnswerQuery(ClockQuery(id)).(game.Clock)
+		now := time.Now()
+
+		gameInfo.Clock = &GameClock{
+			White:        int64(clock.Remaining(game.White, now) / time.Millisecond),
+			Black:        int64(clock.Remaining(game.Black, now) / time.Millisecond),
+			Running:      clock.Running,
+			WhiteBerserk: clock.WhiteBerserk,
+			BlackBerserk: clock.BlackBerserk,
+		}
+	}
+
+	previousGameQ := PreviousGameQuery(id)
+	gameInfo.PreviousGameId = s.SystemQueries.AnswerQuery(previousGameQ).(game.Id)
+
+	gameInfo.Series = s.seriesScore(id, gamePlayers)
+
+	// the games themselves are left to HeadToHead, as a long rivalry
+	// would make every update of the game grow
+	if gamePlayers[game.White] != "" && gamePlayers[game.Black] != "" {
+		record := s.HeadToHead(gamePlayers[game.White], gamePlayers[game.Black])
+		gameInfo.HeadToHead = record.HeadToHeadSummary
 	}
 
 	return *gameInfo, true
 }
 
+// GamePlayers gives who plays each colour in a game, without the work of
+// describing the rest of it
+func (s *ClientQueryService) GamePlayers(id game.Id) map[game.Color]users.Id {
+	gamePlayersQ := GamePlayersQuery(id)
+	return s.SystemQueries.AnswerQuery(gamePlayersQ).(map[game.Color]users.Id)
+}
+
+// HeadToHead is the record between two players, with every game they
+// have started against each other
+func (s *ClientQueryService) HeadToHead(playerId, opponentId users.Id) HeadToHead {
+	headToHeadQ := HeadToHeadQuery(playerId, opponentId)
+	return s.SystemQueries.AnswerQuery(headToHeadQ).(HeadToHead)
+}
+
+// seriesScore follows a game back through the games it is a rematch of,
+// crediting each finished game to the players of the game we started from
+func (s *ClientQueryService) seriesScore(id game.Id, players map[game.Color]users.Id) SeriesScore {
+	score := SeriesScore{}
+
+	for gameId := id; gameId != 0; {
+		status := s.SystemQueries.AnswerQuery(GameQuery(gameId)).(GameStatus)
+
+		if status == GameStatusEnded {
+			gameEnd := s.SystemQueries.AnswerQuery(GameEndQuery(gameId)).(GameEnd)
+			gamePlayers := s.SystemQueries.AnswerQuery(GamePlayersQuery(gameId)).(map[game.Color]users.Id)
+
+			if gameEnd.Reason != game.GameEndAborted {
+				score.Games += 1
+
+				switch gameEnd.Winner {
+				case game.NoOne:
+					score.White += 0.5
+					score.Black += 0.5
+				case game.White, game.Black:
+					if gamePlayers[gameEnd.Winner] == players[game.White] {
+						score.White += 1
+					} else {
+						score.Black += 1
+					}
+				}
+			}
+		}
+
+		gameId = s.SystemQueries.AnswerQuery(PreviousGameQuery(gameId)).(game.Id)
+	}
+
+	return score
+}
+
+// InvitedGame finds the game that was created with an invite code
+func (s *ClientQueryService) InvitedGame(inviteCode string) (game.Id, bool) {
+	if inviteCode == "" {
+		return 0, false
+	}
+
+	gameId := s.SystemQueries.AnswerQuery(InviteCodeQuery(inviteCode)).(game.Id)
+	return gameId, gameId != 0
+}
+
+// User looks up a user along with their ratings
+func (s *ClientQueryService) User(userId users.Id) (users.User, bool) {
+	user, found := s.Users.Get(userId)
+	if !found {
+		return user, false
+	}
+
+	user.Ratings = s.Ratings.Ratings(userId)
+	return user, true
+}
+
+// RatingHistory lists a user's rating after each rated game they played
+// in a category
+func (s *ClientQueryService) RatingHistory(userId users.Id, category game.TimeCategory) []ratings.HistoryEntry {
+	return s.Ratings.History(userId, category)
+}
+
+// PlayerStats breaks down a user's results
+func (s *ClientQueryService) PlayerStats(userId users.Id) (PlayerStats, bool) {
+	_, found := s.Users.Get(userId)
+	if !found {
+		return PlayerStats{}, false
+	}
+
+	stats := s.SystemQueries.AnswerQuery(PlayerStatsQuery(userId)).(PlayerStats)
+	return stats, true
+}
+
+// ratingIn is a player's rating in a category, defaulting for players
+// who are yet to play in it
+func ratingIn(playerRatings map[game.TimeCategory]float64, category game.TimeCategory) float64 {
+	rating, found := playerRatings[category]
+	if !found {
+		return ratings.DefaultRating
+	}
+	return rating
+}
+
+type LeaderboardRow struct {
+	UserId users.Id
+	User   users.User
+	Value  float64
+}
+
+type LeaderboardInformation struct {
+	Category  game.TimeCategory
+	Rating    []LeaderboardRow
+	Games     []LeaderboardRow
+	WinStreak []LeaderboardRow
+}
+
+// Leaderboard lists the top players of a category of game
+func (s *ClientQueryService) Leaderboard(category game.TimeCategory, limit int) LeaderboardInformation {
+	leaderboard := s.SystemQueries.AnswerQuery(LeaderboardQuery(category)).(Leaderboard)
+
+	leaders := []LeaderboardEntry{}
+	for _, rating := range s.Ratings.Leaders(category, limit) {
+		leaders = append(leaders, LeaderboardEntry{rating.UserId, rating.Rating})
+	}
+
+	rows := func(entries []LeaderboardEntry) []LeaderboardRow {
+		rows := []LeaderboardRow{}
+		for i, entry := range entries {
+			if i >= limit {
+				break
+			}
+
+			user, _ := s.Users.Get(entry.UserId)
+			rows = append(rows, LeaderboardRow{entry.UserId, user, entry.Value})
+		}
+		return rows
+	}
+
+	return LeaderboardInformation{
+		Category:  category,
+		Rating:    rows(leaders),
+		Games:     rows(leaderboard.Games),
+		WinStreak: rows(leaderboard.WinStreak),
+	}
+}
+
+// LobbyEntry describes an open game that is waiting for an opponent
+type LobbyEntry struct {
+	GameId       game.Id
+	Creator      users.User
+	CreatorColor game.Color
+	TimeControl  game.TimeControl
+	TimeCategory game.TimeCategory
+}
+
+// LobbyFilter narrows down the lobby. Color is the colour the joining
+// player wants to play. Empty fields match every game.
+type LobbyFilter struct {
+	Category game.TimeCategory
+	Color    game.Color
+}
+
+// Lobby lists the open games a user could join. Private games, whether
+// by invitation or visibility, games with no open seat and the user's
+// own games are left out.
+func (s *ClientQueryService) Lobby(userId users.Id, filter LobbyFilter) []LobbyEntry {
+	entries := []LobbyEntry{}
+
+	openGames := s.SystemQueries.AnswerQuery(OpenGamesQuery()).([]game.Id)
+
+	for _, id := range openGames {
+		invitation := s.SystemQueries.AnswerQuery(InvitationQuery(id)).(Invitation)
+		if invitation.Private() {
+			continue
+		}
+
+		visibility := s.SystemQueries.AnswerQuery(VisibilityQuery(id)).(game.Visibility)
+		if visibility == game.Private {
+			continue
+		}
+
+		gamePlayers := s.SystemQueries.AnswerQuery(GamePlayersQuery(id)).(map[game.Color]users.Id)
+
+		// rematch offers have both players seated before they start
+		if gamePlayers[game.White] != "" && gamePlayers[game.Black] != "" {
+			continue
+		}
+
+		entry := LobbyEntry{GameId: id}
+		if gamePlayers[game.White] != "" {
+			entry.CreatorColor = game.White
+		} else {
+			entry.CreatorColor = game.Black
+		}
+
+		creatorId := gamePlayers[entry.CreatorColor]
+		if creatorId == userId {
+			continue
+		}
+
+		if filter.Color != game.NoOne && filter.Color == entry.CreatorColor {
+			continue
+		}
+
+		entry.TimeControl = s.SystemQueries.AnswerQuery(TimeControlQuery(id)).(game.TimeControl)
+		entry.TimeCategory = entry.TimeControl.Category()
+		if filter.Category != "" && filter.Category != entry.TimeCategory {
+			continue
+		}
+
+		creator, found := s.User(creatorId)
+		if found {
+			entry.Creator = creator
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// LiveGame is a public game that is being played
+type LiveGame struct {
+	GameId       game.Id
+	White        users.User
+	Black        users.User
+	TurnNumber   game.TurnNumber
+	TimeControl  game.TimeControl
+	TimeCategory game.TimeCategory
+}
+
+// LiveGames lists the public games currently being played, oldest first
+func (s *ClientQueryService) LiveGames() []LiveGame {
+	entries := []LiveGame{}
+
+	liveGames := s.SystemQueries.AnswerQuery(LiveGamesQuery()).([]game.Id)
+
+	for _, id := range liveGames {
+		visibility := s.SystemQueries.AnswerQuery(VisibilityQuery(id)).(game.Visibility)
+		if visibility == game.Private {
+			continue
+		}
+
+		entry := LiveGame{GameId: id}
+
+		gamePlayers := s.SystemQueries.AnswerQuery(GamePlayersQuery(id)).(map[game.Color]users.Id)
+
+		white, found := s.User(gamePlayers[game.White])
+		if found {
+			entry.White = white
+		}
+
+		black, found := s.User(gamePlayers[game.Black])
+		if found {
+			entry.Black = black
+		}
+
+		entry.TurnNumber = s.SystemQueries.AnswerQuery(TurnNumberQuery(id)).(game.TurnNumber)
+		entry.TimeControl = s.SystemQueries.AnswerQuery(TimeControlQuery(id)).(game.TimeControl)
+		entry.TimeCategory = entry.TimeControl.Category()
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// Chat lists the messages sent in a game's chat, from both its players
+// and its spectators
+func (s *ClientQueryService) Chat(gameId game.Id) ([]ChatMessage, bool) {
+	gameStatus := s.SystemQueries.AnswerQuery(GameQuery(gameId)).(GameStatus)
+	if gameStatus == GameStatusNull {
+		return []ChatMessage{}, false
+	}
+
+	messages := s.SystemQueries.AnswerQuery(ChatQuery(gameId)).([]ChatMessage)
+
+	chat := make([]ChatMessage, len(messages))
+	for i, message := range messages {
+		chat[i] = message
+
+		author, found := s.User(message.AuthorId)
+		if found {
+			chat[i].Author = author
+		}
+	}
+
+	return chat, true
+}
+
 func (s *ClientQueryService) GameHistory(gameId game.Id) ([]game.MoveRecord, bool) {
 	var (
 		history []game.MoveRecord = []game.MoveRecord{}
@@ -164,9 +560,23 @@ func (s *ClientQueryService) GameHistory(gameId game.Id) ([]game.MoveRecord, boo
 		history = append(history, record)
 	}
 
+	for _, annotation := range s.Annotations(gameId) {
+		if annotation.TurnNumber <= turnNumber {
+			history[annotation.TurnNumber].Comment = annotation.Comment
+			history[annotation.TurnNumber].NAG = annotation.NAG
+		}
+	}
+
 	return history, true
 }
 
+// Annotations lists what the players have said about a game's moves, in
+// turn order
+func (s *ClientQueryService) Annotations(gameId game.Id) []Annotation {
+	annotationsQ := AnnotationsQuery(gameId)
+	return s.SystemQueries.AnswerQuery(annotationsQ).([]Annotation)
+}
+
 func (s *ClientQueryService) ValidMoves(gameId game.Id) ([]game.MoveRecord, bool) {
 	var (
 		validMoves []game.MoveRecord = []game.MoveRecord{}
@@ -5,10 +5,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"testing"
+	"time"
 
 	"foodtastechess/directory"
+	"foodtastechess/events"
 	"foodtastechess/game"
 	"foodtastechess/logger"
+	"foodtastechess/ratings"
 	"foodtastechess/users"
 )
 
@@ -22,6 +25,7 @@ type ClientQueriesTestSuite struct {
 	log               *logging.Logger
 	mockSystemQueries *MockSystemQueries
 	mockUsers         *MockUsers
+	mockRatings       *MockRatings
 	clientQueries     ClientQueries
 }
 
@@ -36,6 +40,7 @@ func (suite *ClientQueriesTestSuite) SetupTest() {
 		systemQueries MockSystemQueries
 		clientQueries ClientQueryService
 		mockUsers     MockUsers
+		mockRatings   MockRatings
 	)
 
 	systemQueries.complete = true
@@ -47,6 +52,7 @@ func (suite *ClientQueriesTestSuite) SetupTest() {
 	d.AddService("clientQueries", &clientQueries)
 	d.AddService("systemQueries", &systemQueries)
 	d.AddService("users", &mockUsers)
+	d.AddService("ratings", &mockRatings)
 
 	// Populate the directory so that clientQueries knows to use our mocked
 	// systemQueries
@@ -58,6 +64,7 @@ func (suite *ClientQueriesTestSuite) SetupTest() {
 	suite.mockSystemQueries = &systemQueries
 	suite.clientQueries = &clientQueries
 	suite.mockUsers = &mockUsers
+	suite.mockRatings = &mockRatings
 }
 
 // TestGameInformation tests the ClientQueries.GameInformation() method.
@@ -84,14 +91,22 @@ func (suite *ClientQueriesTestSuite) TestGameInformation() {
 			game.Black: blackId,
 		}
 
-		expectedWhite users.User = users.User{Uuid: whiteId}
-		expectedBlack users.User = users.User{Uuid: blackId}
+		whiteRatings map[game.TimeCategory]float64 = map[game.TimeCategory]float64{game.Blitz: 1620}
+		blackRatings map[game.TimeCategory]float64 = map[game.TimeCategory]float64{}
+
+		expectedWhite users.User = users.User{Uuid: whiteId, Ratings: whiteRatings}
+		expectedBlack users.User = users.User{Uuid: blackId, Ratings: blackRatings}
+
+		expectedHeadToHead HeadToHeadSummary = HeadToHeadSummary{
+			PlayerA: whiteId, PlayerB: blackId, Finished: 3, WinsA: 2, Draws: 1,
+		}
 
 		// expected query objects we're looking for
 		turnNumberQuery  Query = TurnNumberQuery(gameId)
 		boardStateQuery  Query = BoardAtTurnQuery(gameId, expectedTurnNumber)
 		gamePlayersQuery Query = GamePlayersQuery(gameId)
 		drawOfferQuery   Query = DrawOfferStateQuery(gameId)
+		takebackQuery    Query = TakebackStateQuery(gameId)
 	)
 
 	// given our expected queries, return our respective expected results
@@ -110,13 +125,43 @@ func (suite *ClientQueriesTestSuite) TestGameInformation() {
 	suite.mockSystemQueries.
 		On("AnswerQuery", drawOfferQuery).
 		Return(game.NoOne)
+	suite.mockSystemQueries.
+		On("AnswerQuery", takebackQuery).
+		Return(game.NoOne)
+	suite.mockSystemQueries.
+		On("AnswerQuery", PreviousGameQuery(gameId)).
+		Return(game.Id(0))
+	suite.mockSystemQueries.
+		On("AnswerQuery", InvitationQuery(gameId)).
+		Return(Invitation{Invitee: game.NoOne})
+	suite.mockSystemQueries.
+		On("AnswerQuery", TimeControlQuery(gameId)).
+		Return(game.TimeControl{Initial: 300, Increment: 3})
+	suite.mockSystemQueries.
+		On("AnswerQuery", VisibilityQuery(gameId)).
+		Return(game.Public)
+	suite.mockSystemQueries.
+		On("AnswerQuery", ClockQuery(gameId)).
+		Return(game.Clock{White: 250 * time.Second, Black: 280 * time.Second})
+	suite.mockSystemQueries.
+		On("AnswerQuery", HeadToHeadQuery(whiteId, blackId)).
+		Return(HeadToHead{
+			HeadToHeadSummary: expectedHeadToHead,
+			Games:             []HeadToHeadGame{{GameId: 1}, {GameId: 2}, {GameId: 3}},
+		})
 
 	suite.mockUsers.
 		On("Get", whiteId).
-		Return(expectedWhite, true)
+		Return(users.User{Uuid: whiteId}, true)
 	suite.mockUsers.
 		On("Get", blackId).
-		Return(expectedBlack, true)
+		Return(users.User{Uuid: blackId}, true)
+	suite.mockRatings.
+		On("Ratings", whiteId).
+		Return(whiteRatings)
+	suite.mockRatings.
+		On("Ratings", blackId).
+		Return(blackRatings)
 
 	// run the test call
 	gameInfo, found := suite.clientQueries.GameInformation(gameId)
@@ -128,6 +173,216 @@ func (suite *ClientQueriesTestSuite) TestGameInformation() {
 	assert.Equal(expectedBoardState, gameInfo.BoardState)
 	assert.Equal(expectedWhite, gameInfo.White)
 	assert.Equal(expectedBlack, gameInfo.Black)
+	assert.Equal(false, gameInfo.Private)
+	assert.Equal(game.Public, gameInfo.Visibility)
+	assert.Equal(game.Blitz, gameInfo.TimeCategory)
+	assert.Equal(1620.0, gameInfo.WhiteRating)
+	assert.Equal(1500.0, gameInfo.BlackRating)
+	assert.Equal(expectedHeadToHead, gameInfo.HeadToHead)
+	assert.Equal(&GameClock{White: 250000, Black: 280000}, gameInfo.Clock)
+}
+
+// TestGameInformationSeries tests that GameInformation totals the results
+// of the games a rematch follows, as seen by the rematch's players.
+func (suite *ClientQueriesTestSuite) TestGameInformationSeries() {
+	var (
+		// game 3 is a rematch of game 2, which is a rematch of game 1
+		firstId  game.Id = 1
+		secondId game.Id = 2
+		thirdId  game.Id = 3
+
+		aliceId users.Id = "alice"
+		bobId   users.Id = "bob"
+
+		alice users.User = users.User{Uuid: aliceId}
+		bob   users.User = users.User{Uuid: bobId}
+	)
+
+	players := func(whiteId, blackId users.Id) map[game.Color]users.Id {
+		return map[game.Color]users.Id{
+			game.White: whiteId,
+			game.Black: blackId,
+		}
+	}
+
+	// alice wins game 1 as white, game 2 is drawn, game 3 in progress
+	suite.mockSystemQueries.On("AnswerQuery", GameQuery(firstId)).Return(GameStatusEnded)
+	suite.mockSystemQueries.On("AnswerQuery", GameQuery(secondId)).Return(GameStatusEnded)
+	suite.mockSystemQueries.On("AnswerQuery", GameQuery(thirdId)).Return(GameStatusStarted)
+
+	suite.mockSystemQueries.On("AnswerQuery", GameEndQuery(firstId)).
+		Return(GameEnd{Occurred: true, Reason: game.GameEndCheckmate, Winner: game.White})
+	suite.mockSystemQueries.On("AnswerQuery", GameEndQuery(secondId)).
+		Return(GameEnd{Occurred: true, Reason: game.GameEndDraw, Winner: game.NoOne})
+
+	suite.mockSystemQueries.On("AnswerQuery", GamePlayersQuery(firstId)).Return(players(aliceId, bobId))
+	suite.mockSystemQueries.On("AnswerQuery", GamePlayersQuery(secondId)).Return(players(bobId, aliceId))
+	suite.mockSystemQueries.On("AnswerQuery", GamePlayersQuery(thirdId)).Return(players(aliceId, bobId))
+
+	suite.mockSystemQueries.On("AnswerQuery", PreviousGameQuery(firstId)).Return(game.Id(0))
+	suite.mockSystemQueries.On("AnswerQuery", PreviousGameQuery(secondId)).Return(firstId)
+	suite.mockSystemQueries.On("AnswerQuery", PreviousGameQuery(thirdId)).Return(secondId)
+
+	suite.mockSystemQueries.On("AnswerQuery", TurnNumberQuery(thirdId)).Return(game.TurnNumber(0))
+	suite.mockSystemQueries.On("AnswerQuery", BoardAtTurnQuery(thirdId, 0)).
+		Return(game.FEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"))
+	suite.mockSystemQueries.On("AnswerQuery", DrawOfferStateQuery(thirdId)).Return(game.NoOne)
+	suite.mockSystemQueries.On("AnswerQuery", TakebackStateQuery(thirdId)).Return(game.NoOne)
+	suite.mockSystemQueries.On("AnswerQuery", InvitationQuery(thirdId)).Return(Invitation{Invitee: game.NoOne})
+	suite.mockSystemQueries.On("AnswerQuery", TimeControlQuery(thirdId)).Return(game.TimeControl{})
+	suite.mockSystemQueries.On("AnswerQuery", VisibilityQuery(thirdId)).Return(game.Public)
+	suite.mockSystemQueries.On("AnswerQuery", HeadToHeadQuery(aliceId, bobId)).Return(HeadToHead{})
+
+	suite.mockUsers.On("Get", aliceId).Return(alice, true)
+	suite.mockUsers.On("Get", bobId).Return(bob, true)
+	suite.mockRatings.On("Ratings", aliceId).Return(map[game.TimeCategory]float64{})
+	suite.mockRatings.On("Ratings", bobId).Return(map[game.TimeCategory]float64{})
+
+	gameInfo, found := suite.clientQueries.GameInformation(thirdId)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, found)
+	assert.Equal(secondId, gameInfo.PreviousGameId)
+	assert.Equal(2, gameInfo.Series.Games)
+	assert.Equal(1.5, gameInfo.Series.White)
+	assert.Equal(0.5, gameInfo.Series.Black)
+}
+
+// TestLobby tests that the lobby lists the open games the user could
+// join, and narrows them down by filter.
+func (suite *ClientQueriesTestSuite) TestLobby() {
+	var (
+		aliceId users.Id = "alice"
+		bobId   users.Id = "bob"
+		carolId users.Id = "carol"
+
+		alice users.User = users.User{Uuid: aliceId}
+		bob   users.User = users.User{Uuid: bobId}
+
+		blitz     game.TimeControl = game.TimeControl{Initial: 300}
+		classical game.TimeControl = game.TimeControl{Initial: 3600}
+	)
+
+	openGame := func(id game.Id, whiteId, blackId users.Id, invitation Invitation, visibility game.Visibility, tc game.TimeControl) {
+		suite.mockSystemQueries.On("AnswerQuery", InvitationQuery(id)).Return(invitation)
+		suite.mockSystemQueries.On("AnswerQuery", VisibilityQuery(id)).Return(visibility)
+		suite.mockSystemQueries.On("AnswerQuery", GamePlayersQuery(id)).
+			Return(map[game.Color]users.Id{
+				game.White: whiteId,
+				game.Black: blackId,
+			})
+		suite.mockSystemQueries.On("AnswerQuery", TimeControlQuery(id)).Return(tc)
+	}
+
+	// alice waits as white, bob's second game is private, the third is
+	// carol's own, bob waits as black in the fourth and the fifth is a
+	// rematch alice has offered bob. The sixth is kept from spectators.
+	suite.mockSystemQueries.On("AnswerQuery", OpenGamesQuery()).
+		Return([]game.Id{1, 2, 3, 4, 5, 6})
+	openGame(1, aliceId, "", Invitation{Invitee: game.NoOne}, game.Public, blitz)
+	openGame(2, bobId, "", Invitation{Invitee: game.NoOne, Code: "secret"}, game.Private, blitz)
+	openGame(3, carolId, "", Invitation{Invitee: game.NoOne}, game.Public, blitz)
+	openGame(4, "", bobId, Invitation{Invitee: game.NoOne}, game.Public, classical)
+	openGame(5, bobId, aliceId, Invitation{Invitee: game.NoOne}, game.Public, blitz)
+	openGame(6, aliceId, "", Invitation{Invitee: game.NoOne}, game.Private, blitz)
+
+	suite.mockUsers.On("Get", aliceId).Return(alice, true)
+	suite.mockUsers.On("Get", bobId).Return(bob, true)
+	suite.mockRatings.On("Ratings", aliceId).Return(map[game.TimeCategory]float64{})
+	suite.mockRatings.On("Ratings", bobId).Return(map[game.TimeCategory]float64{})
+
+	assert := assert.New(suite.T())
+
+	lobby := suite.clientQueries.Lobby(carolId, LobbyFilter{})
+	assert.Equal(2, len(lobby))
+	assert.Equal(game.Id(1), lobby[0].GameId)
+	assert.Equal(aliceId, lobby[0].Creator.Uuid)
+	assert.Equal(game.White, lobby[0].CreatorColor)
+	assert.Equal(game.Blitz, lobby[0].TimeCategory)
+	assert.Equal(game.Id(4), lobby[1].GameId)
+	assert.Equal(game.Black, lobby[1].CreatorColor)
+
+	lobby = suite.clientQueries.Lobby(carolId, LobbyFilter{Color: game.White})
+	assert.Equal(1, len(lobby))
+	assert.Equal(game.Id(4), lobby[0].GameId)
+
+	lobby = suite.clientQueries.Lobby(carolId, LobbyFilter{Category: game.Blitz})
+	assert.Equal(1, len(lobby))
+	assert.Equal(game.Id(1), lobby[0].GameId)
+}
+
+// TestLiveGames tests that the games being played are listed, leaving
+// out private ones.
+func (suite *ClientQueriesTestSuite) TestLiveGames() {
+	var (
+		aliceId users.Id = "alice"
+		bobId   users.Id = "bob"
+
+		alice users.User = users.User{Uuid: aliceId}
+		bob   users.User = users.User{Uuid: bobId}
+
+		blitz game.TimeControl = game.TimeControl{Initial: 300}
+	)
+
+	liveGame := func(id game.Id, visibility game.Visibility) {
+		suite.mockSystemQueries.On("AnswerQuery", VisibilityQuery(id)).Return(visibility)
+		suite.mockSystemQueries.On("AnswerQuery", GamePlayersQuery(id)).
+			Return(map[game.Color]users.Id{
+				game.White: aliceId,
+				game.Black: bobId,
+			})
+		suite.mockSystemQueries.On("AnswerQuery", TurnNumberQuery(id)).Return(game.TurnNumber(12))
+		suite.mockSystemQueries.On("AnswerQuery", TimeControlQuery(id)).Return(blitz)
+	}
+
+	suite.mockSystemQueries.On("AnswerQuery", LiveGamesQuery()).
+		Return([]game.Id{1, 2})
+	liveGame(1, game.Private)
+	liveGame(2, game.Public)
+
+	suite.mockUsers.On("Get", aliceId).Return(alice, true)
+	suite.mockUsers.On("Get", bobId).Return(bob, true)
+	suite.mockRatings.On("Ratings", aliceId).Return(map[game.TimeCategory]float64{})
+	suite.mockRatings.On("Ratings", bobId).Return(map[game.TimeCategory]float64{})
+
+	live := suite.clientQueries.LiveGames()
+
+	assert := assert.New(suite.T())
+	assert.Equal(1, len(live))
+	assert.Equal(game.Id(2), live[0].GameId)
+	assert.Equal(aliceId, live[0].White.Uuid)
+	assert.Equal(bobId, live[0].Black.Uuid)
+	assert.Equal(game.TurnNumber(12), live[0].TurnNumber)
+	assert.Equal(game.Blitz, live[0].TimeCategory)
+}
+
+// TestLeaderboard tests that ratings are ranked by the ratings service,
+// and the rest by the leaderboard query
+func (suite *ClientQueriesTestSuite) TestLeaderboard() {
+	var (
+		aliceId users.Id = "alice"
+		bobId   users.Id = "bob"
+
+		alice users.User = users.User{Uuid: aliceId, Name: "Alice"}
+		bob   users.User = users.User{Uuid: bobId, Name: "Bob"}
+	)
+
+	suite.mockSystemQueries.On("AnswerQuery", LeaderboardQuery(game.Blitz)).
+		Return(Leaderboard{
+			Games:     []LeaderboardEntry{{bobId, 3}, {aliceId, 2}},
+			WinStreak: []LeaderboardEntry{{aliceId, 2}, {bobId, 1}},
+		})
+	suite.mockRatings.On("Leaders", game.Blitz, 1).
+		Return([]ratings.PlayerRating{{UserId: aliceId, Rating: 1620}})
+	suite.mockUsers.On("Get", aliceId).Return(alice, true)
+	suite.mockUsers.On("Get", bobId).Return(bob, true)
+
+	leaderboard := suite.clientQueries.Leaderboard(game.Blitz, 1)
+
+	assert := assert.New(suite.T())
+	assert.Equal([]LeaderboardRow{{aliceId, alice, 1620}}, leaderboard.Rating)
+	assert.Equal([]LeaderboardRow{{bobId, bob, 3}}, leaderboard.Games)
+	assert.Equal([]LeaderboardRow{{aliceId, alice, 2}}, leaderboard.WinStreak)
 }
 
 func (suite *ClientQueriesTestSuite) TestGameInformationGameDNE() {
@@ -142,6 +397,32 @@ func (suite *ClientQueriesTestSuite) TestGameInformationGameDNE() {
 	assert.Equal(false, found)
 }
 
+// TestChat tests that chat messages are given their authors
+func (suite *ClientQueriesTestSuite) TestChat() {
+	var (
+		gameId  game.Id    = 11
+		aliceId users.Id   = "alice"
+		alice   users.User = users.User{Uuid: aliceId, Name: "Alice"}
+	)
+
+	suite.mockSystemQueries.On("AnswerQuery", GameQuery(gameId)).Return(GameStatusEnded)
+	suite.mockSystemQueries.On("AnswerQuery", ChatQuery(gameId)).
+		Return([]ChatMessage{
+			NewChatMessage(events.NewChatEvent(gameId, aliceId, "good game")),
+		})
+	suite.mockUsers.On("Get", aliceId).Return(alice, true)
+	suite.mockRatings.On("Ratings", aliceId).Return(map[game.TimeCategory]float64{})
+
+	chat, found := suite.clientQueries.Chat(gameId)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, found)
+	assert.Equal(1, len(chat))
+	assert.Equal(PlayerChat, chat[0].Channel)
+	assert.Equal("Alice", chat[0].Author.Name)
+	assert.Equal("good game", chat[0].Message)
+}
+
 func (suite *ClientQueriesTestSuite) TestGameHistory() {
 	assert := assert.New(suite.T())
 	var (
@@ -184,10 +465,19 @@ func (suite *ClientQueriesTestSuite) TestGameHistory() {
 			Return(state)
 	}
 
+	suite.mockSystemQueries.
+		On("AnswerQuery", AnnotationsQuery(gameId)).
+		Return([]Annotation{
+			{Annotation: game.Annotation{TurnNumber: 2, Comment: "the only move", NAG: game.Brilliant}},
+		})
+
 	history, found := suite.clientQueries.GameHistory(gameId)
 
 	assert.Equal(true, found)
 	assert.Equal(len(states), len(history))
+	assert.Equal("the only move", history[2].Comment)
+	assert.Equal(game.Brilliant, history[2].NAG)
+	assert.Equal("", history[1].Comment)
 
 	for i, record := range history {
 		var expectedMove game.AlgebraicMove
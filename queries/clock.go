@@ -0,0 +1,58 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// clockQuery replays a timed game's clock up to its latest event. The
+// time left at any moment after that is worked out from the result.
+type clockQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   game.Clock
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *clockQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *clockQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *clockQuery) computeResult(queries SystemQueries) {
+	q.Answered = true
+	q.Result = game.NewClock(game.TimeControl{})
+
+	for _, event := range queries.getEvents().EventsForGame(q.GameId) {
+		switch event.Type {
+		case events.GameCreateType:
+			q.Result = game.NewClock(event.TimeControl())
+		case events.GameStartType:
+			q.Result = q.Result.Start(event.CreatedAt)
+		case events.MoveType:
+			q.Result = q.Result.Press(event.CreatedAt)
+		case events.MoveRetractType:
+			q.Result = q.Result.Switch(event.CreatedAt)
+		case events.BerserkType:
+			q.Result = q.Result.Berserk(event.Offerer)
+		case events.GameEndType:
+			q.Result = q.Result.Stop(event.CreatedAt)
+		}
+	}
+}
+
+func (q *clockQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *clockQuery) hash() string {
+	return fmt.Sprintf("clock:%v", q.GameId)
+}
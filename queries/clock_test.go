@@ -0,0 +1,73 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type ClockQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *ClockQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *clockQuery
+	)
+
+	hasResult = ClockQuery(gameId).(*clockQuery)
+	hasResult.Answered = true
+
+	noResult = ClockQuery(gameId).(*clockQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *ClockQueryTestSuite) TestComputeResult() {
+	var (
+		gameId  game.Id  = 1
+		whiteId users.Id = "alice"
+		blackId users.Id = "bob"
+		start   time.Time
+		query   *clockQuery
+	)
+
+	start = time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(event events.Event, seconds int) events.Event {
+		event.CreatedAt = start.Add(time.Duration(seconds) * time.Second)
+		return event
+	}
+
+	suite.mockEvents.
+		On("EventsForGame", gameId).
+		Return([]events.Event{
+			events.NewGameCreateEvent(gameId, whiteId, "").
+				WithTimeControl(game.TimeControl{Initial: 60, Increment: 1}),
+			at(events.NewGameStartEvent(gameId, whiteId, blackId), 0),
+			at(events.NewBerserkEvent(gameId, game.Black), 1),
+			at(events.NewMoveEvent(gameId, 1, "Pe2-e4"), 5),
+			at(events.NewMoveEvent(gameId, 2, "Pe7-e5"), 15),
+		})
+
+	query = ClockQuery(gameId).(*clockQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(game.White, query.Result.Running)
+	assert.Equal(56*time.Second, query.Result.White)
+	assert.Equal(20*time.Second, query.Result.Black)
+	assert.Equal(true, query.Result.BlackBerserk)
+}
+
+func TestClockQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(ClockQueryTestSuite))
+}
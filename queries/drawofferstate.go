@@ -28,9 +28,11 @@ func (q *drawOfferStateQuery) getResult() interface{} {
 func (q *drawOfferStateQuery) computeResult(queries SystemQueries) {
 	offers := queries.getEvents().EventsOfTypeForGame(q.GameId, events.DrawOfferType)
 	responses := queries.getEvents().EventsOfTypeForGame(q.GameId, events.DrawOfferResponseType)
+	withdrawals := queries.getEvents().EventsOfTypeForGame(q.GameId, events.DrawOfferWithdrawType)
 
+	// an offer is closed by a response or by the offerer withdrawing it
 	q.Answered = true
-	if len(responses) == len(offers) {
+	if len(responses)+len(withdrawals) == len(offers) {
 		q.Result = game.NoOne
 		return
 	}
@@ -65,6 +65,10 @@ func (suite *DrawOfferStateQueryTestSuite) TestComputeResult() {
 		On("EventsOfTypeForGame", gameId, events.DrawOfferResponseType).
 		Return([]events.Event{})
 
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.DrawOfferWithdrawType).
+		Return([]events.Event{})
+
 	query = DrawOfferStateQuery(gameId).(*drawOfferStateQuery)
 	query.computeResult(suite.mockSystemQueries)
 	assert.Equal(game.White, query.Result)
@@ -83,6 +87,10 @@ func (suite *DrawOfferStateQueryTestSuite) TestComputeResult() {
 		events.NewDrawOfferResponseEvent(gameId, true),
 	})
 
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.DrawOfferWithdrawType).
+		Return([]events.Event{})
+
 	query = DrawOfferStateQuery(gameId).(*drawOfferStateQuery)
 	query.computeResult(suite.mockSystemQueries)
 	assert.Equal(game.NoOne, query.Result)
@@ -102,9 +110,35 @@ func (suite *DrawOfferStateQueryTestSuite) TestComputeResult() {
 		events.NewDrawOfferResponseEvent(gameId, false),
 	})
 
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.DrawOfferWithdrawType).
+		Return([]events.Event{})
+
 	query = DrawOfferStateQuery(gameId).(*drawOfferStateQuery)
 	query.computeResult(suite.mockSystemQueries)
 	assert.Equal(game.Black, query.Result)
+
+	// Offer -> Withdraw
+	gameId = 4
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.DrawOfferType).
+		Return([]events.Event{
+		events.NewDrawOfferEvent(gameId, game.White),
+	})
+
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.DrawOfferResponseType).
+		Return([]events.Event{})
+
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.DrawOfferWithdrawType).
+		Return([]events.Event{
+		events.NewDrawOfferWithdrawEvent(gameId),
+	})
+
+	query = DrawOfferStateQuery(gameId).(*drawOfferStateQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.NoOne, query.Result)
 }
 
 func TestDrawOfferStateQueryTestSuite(t *testing.T) {
@@ -89,3 +89,153 @@ func (q *gameEndQuery) isExpired(now interface{}) bool {
 func (q *gameEndQuery) getExpiration(now interface{}) interface{} {
 	return nil
 }
+
+// Takeback State Query
+
+func (q *takebackStateQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *takebackStateQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Rematch Query
+
+func (q *rematchQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *rematchQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Previous Game Query
+
+func (q *previousGameQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *previousGameQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Invitation Query
+
+func (q *invitationQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *invitationQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Invite Code Query
+
+func (q *inviteCodeQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *inviteCodeQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Open Games Query
+
+func (q *openGamesQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *openGamesQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Time Control Query
+
+func (q *timeControlQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *timeControlQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Leaderboard Query
+
+func (q *leaderboardQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *leaderboardQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Player Stats Query
+
+func (q *playerStatsQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *playerStatsQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Head To Head Query
+
+func (q *headToHeadQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *headToHeadQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Clock Query
+
+func (q *clockQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *clockQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Visibility Query
+
+func (q *visibilityQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *visibilityQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Live Games Query
+
+func (q *liveGamesQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *liveGamesQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Chat Query
+
+func (q *chatQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *chatQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
+
+// Annotations Query
+
+func (q *annotationsQuery) isExpired(now interface{}) bool {
+	return false
+}
+
+func (q *annotationsQuery) getExpiration(now interface{}) interface{} {
+	return nil
+}
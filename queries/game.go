@@ -35,14 +35,16 @@ func (q *gameQuery) computeResult(queries SystemQueries) {
 		) > 0
 	}
 
+	// a game may end without ever starting, if it is aborted before
+	// anyone joins
 	if !exists(events.GameCreateType) {
 		q.Result = GameStatusNull
+	} else if exists(events.GameEndType) {
+		q.Result = GameStatusEnded
 	} else if !exists(events.GameStartType) {
 		q.Result = GameStatusCreated
-	} else if !exists(events.GameEndType) {
-		q.Result = GameStatusStarted
 	} else {
-		q.Result = GameStatusEnded
+		q.Result = GameStatusStarted
 	}
 }
 
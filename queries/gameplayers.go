@@ -39,17 +39,19 @@ func (q *gamePlayersQuery) computeResult(queries SystemQueries) {
 	var whiteId users.Id
 	var blackId users.Id
 
-	if status == GameStatusCreated {
+	gameStarts := queries.getEvents().
+		EventsOfTypeForGame(q.GameId, events.GameStartType)
+
+	// games that were aborted before anyone joined never started
+	if status == GameStatusCreated || len(gameStarts) == 0 {
 		gameCreate := queries.getEvents().
 			EventsOfTypeForGame(q.GameId, events.GameCreateType)[0]
 		whiteId = gameCreate.WhiteId
 		blackId = gameCreate.BlackId
 	} else {
-		gameStart := queries.getEvents().
-			EventsOfTypeForGame(q.GameId, events.GameStartType)[0]
+		gameStart := gameStarts[0]
 		whiteId = gameStart.WhiteId
 		blackId = gameStart.BlackId
-
 	}
 
 	q.Result = make(map[game.Color]users.Id)
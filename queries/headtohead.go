@@ -0,0 +1,158 @@
+package queries
+
+import (
+	"fmt"
+	"time"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type HeadToHeadGame struct {
+	GameId  game.Id
+	White   users.Id
+	Black   users.Id
+	Ended   bool
+	Reason  game.GameEndReason `json:",omitempty"`
+	Winner  game.Color         `json:",omitempty"`
+	EndedAt time.Time
+}
+
+// HeadToHeadSummary is the record between two players over every game
+// they have started against each other. PlayerA sorts before PlayerB,
+// and wins, draws and colours count finished games only.
+type HeadToHeadSummary struct {
+	PlayerA users.Id
+	PlayerB users.Id
+
+	Finished   int
+	WinsA      int
+	WinsB      int
+	Draws      int
+	WhiteA     int
+	WhiteB     int
+	LastPlayed time.Time
+}
+
+// HeadToHead is the record between two players along with the games it
+// counts
+type HeadToHead struct {
+	HeadToHeadSummary `bson:",inline"`
+	Games             []HeadToHeadGame
+}
+
+// headToHeadQuery is keyed on a sorted pair of players, so that it is
+// the same query whichever way round it is asked
+type headToHeadQuery struct {
+	PlayerA users.Id
+	PlayerB users.Id
+
+	Answered bool
+	Result   HeadToHead
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *headToHeadQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *headToHeadQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *headToHeadQuery) computeResult(queries SystemQueries) {
+	record := HeadToHead{
+		HeadToHeadSummary: HeadToHeadSummary{
+			PlayerA: q.PlayerA,
+			PlayerB: q.PlayerB,
+		},
+		Games: []HeadToHeadGame{},
+	}
+
+	against := func(event events.Event) bool {
+		return (event.WhiteId == q.PlayerA && event.BlackId == q.PlayerB) ||
+			(event.WhiteId == q.PlayerB && event.BlackId == q.PlayerA)
+	}
+
+	gameStarts := queries.getEvents().
+		EventsOfTypeForPlayer(q.PlayerA, events.GameStartType)
+
+	gameEnds := queries.getEvents().
+		EventsOfTypeForPlayer(q.PlayerA, events.GameEndType)
+
+	ends := make(map[game.Id]events.Event)
+	for _, event := range gameEnds {
+		if against(event) {
+			ends[event.GameId] = event
+		}
+	}
+
+	for _, start := range gameStarts {
+		if !against(start) {
+			continue
+		}
+
+		g := HeadToHeadGame{
+			GameId: start.GameId,
+			White:  start.WhiteId,
+			Black:  start.BlackId,
+		}
+
+		end, ended := ends[start.GameId]
+		if ended {
+			g.Ended = true
+			g.Reason = end.Reason
+			g.Winner = end.Winner
+			g.EndedAt = end.CreatedAt
+		}
+
+		record.Games = append(record.Games, g)
+
+		if !ended || end.Reason == game.GameEndAborted {
+			continue
+		}
+
+		record.Finished += 1
+
+		if g.White == q.PlayerA {
+			record.WhiteA += 1
+		} else {
+			record.WhiteB += 1
+		}
+
+		var winnerId users.Id
+		switch g.Winner {
+		case game.White:
+			winnerId = g.White
+		case game.Black:
+			winnerId = g.Black
+		}
+
+		switch winnerId {
+		case "":
+			record.Draws += 1
+		case q.PlayerA:
+			record.WinsA += 1
+		default:
+			record.WinsB += 1
+		}
+
+		if g.EndedAt.After(record.LastPlayed) {
+			record.LastPlayed = g.EndedAt
+		}
+	}
+
+	q.Result = record
+	q.Answered = true
+}
+
+func (q *headToHeadQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *headToHeadQuery) hash() string {
+	return fmt.Sprintf("headtohead:%v:%v", q.PlayerA, q.PlayerB)
+}
@@ -0,0 +1,98 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type HeadToHeadQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *HeadToHeadQueryTestSuite) TestHasResult() {
+	var hasResult, noResult *headToHeadQuery
+
+	hasResult = HeadToHeadQuery("alice", "bob").(*headToHeadQuery)
+	hasResult.Answered = true
+
+	noResult = HeadToHeadQuery("alice", "bob").(*headToHeadQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *HeadToHeadQueryTestSuite) TestSortedPair() {
+	assert := assert.New(suite.T())
+	assert.Equal(
+		HeadToHeadQuery("alice", "bob").hash(),
+		HeadToHeadQuery("bob", "alice").hash(),
+	)
+}
+
+func (suite *HeadToHeadQueryTestSuite) TestComputeResult() {
+	var (
+		alice users.Id = "alice"
+		bob   users.Id = "bob"
+		carol users.Id = "carol"
+		query *headToHeadQuery
+
+		firstEnd  time.Time = time.Date(2015, 4, 1, 12, 0, 0, 0, time.UTC)
+		secondEnd time.Time = firstEnd.Add(time.Hour)
+	)
+
+	endedAt := func(event events.Event, at time.Time) events.Event {
+		event.CreatedAt = at
+		return event
+	}
+
+	// alice and bob finish two games and abort one, with one still
+	// going. Alice's game with carol isn't part of the record.
+	suite.mockEvents.
+		On("EventsOfTypeForPlayer", alice, events.GameStartType).
+		Return([]events.Event{
+			events.NewGameStartEvent(1, alice, bob),
+			events.NewGameStartEvent(2, bob, alice),
+			events.NewGameStartEvent(3, alice, carol),
+			events.NewGameStartEvent(4, alice, bob),
+			events.NewGameStartEvent(5, bob, alice),
+		})
+	suite.mockEvents.
+		On("EventsOfTypeForPlayer", alice, events.GameEndType).
+		Return([]events.Event{
+			endedAt(events.NewGameEndEvent(1, game.GameEndCheckmate, game.White, alice, bob), firstEnd),
+			endedAt(events.NewGameEndEvent(2, game.GameEndDraw, game.NoOne, bob, alice), secondEnd),
+			events.NewGameEndEvent(3, game.GameEndConcede, game.Black, alice, carol),
+			events.NewGameEndEvent(4, game.GameEndAborted, game.NoOne, alice, bob),
+		})
+
+	query = HeadToHeadQuery(bob, alice).(*headToHeadQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, query.Answered)
+
+	record := query.Result
+	assert.Equal(alice, record.PlayerA)
+	assert.Equal(bob, record.PlayerB)
+	assert.Equal(4, len(record.Games))
+	assert.Equal(2, record.Finished)
+	assert.Equal(1, record.WinsA)
+	assert.Equal(0, record.WinsB)
+	assert.Equal(1, record.Draws)
+	assert.Equal(1, record.WhiteA)
+	assert.Equal(1, record.WhiteB)
+	assert.Equal(secondEnd, record.LastPlayed)
+	assert.Equal(false, record.Games[3].Ended)
+}
+
+func TestHeadToHeadQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(HeadToHeadQueryTestSuite))
+}
@@ -0,0 +1,101 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// Invitation describes who may join a game that has not yet started.
+// Invitee is the seat reserved for a challenged player, and Code is the
+// invite code a private game can only be joined with.
+type Invitation struct {
+	Invitee game.Color
+	Code    string
+}
+
+// Private reports whether the game is closed to the lobby
+func (i Invitation) Private() bool {
+	return i.Invitee != game.NoOne || i.Code != ""
+}
+
+type invitationQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   Invitation
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *invitationQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *invitationQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *invitationQuery) computeResult(queries SystemQueries) {
+	q.Answered = true
+	q.Result = Invitation{Invitee: game.NoOne}
+
+	gameCreates := queries.getEvents().
+		EventsOfTypeForGame(q.GameId, events.GameCreateType)
+	if len(gameCreates) == 0 {
+		return
+	}
+
+	gameCreate := gameCreates[0]
+	q.Result.Code = gameCreate.InviteCode
+
+	switch gameCreate.Offerer {
+	case game.White:
+		q.Result.Invitee = game.Black
+	case game.Black:
+		q.Result.Invitee = game.White
+	}
+}
+
+func (q *invitationQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *invitationQuery) hash() string {
+	return fmt.Sprintf("invitation:%v", q.GameId)
+}
+
+// inviteCodeQuery finds the game created with an invite code, or 0 if
+// there is none
+type inviteCodeQuery struct {
+	Code string
+
+	Answered bool
+	Result   game.Id
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *inviteCodeQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *inviteCodeQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *inviteCodeQuery) computeResult(queries SystemQueries) {
+	q.Answered = true
+	q.Result = queries.getEvents().GameCreateEventForInviteCode(q.Code).GameId
+}
+
+func (q *inviteCodeQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *inviteCodeQuery) hash() string {
+	return fmt.Sprintf("invitecode:%v", q.Code)
+}
@@ -0,0 +1,104 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type InvitationQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *InvitationQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *invitationQuery
+	)
+
+	hasResult = InvitationQuery(gameId).(*invitationQuery)
+	hasResult.Answered = true
+
+	noResult = InvitationQuery(gameId).(*invitationQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *InvitationQueryTestSuite) TestComputeResult() {
+	assert := assert.New(suite.T())
+
+	var (
+		whiteId users.Id = "alice"
+		blackId users.Id = "bob"
+		query   *invitationQuery
+	)
+
+	// Open game
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(1), events.GameCreateType).
+		Return([]events.Event{
+			events.NewGameCreateEvent(1, whiteId, ""),
+		})
+
+	query = InvitationQuery(1).(*invitationQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(Invitation{Invitee: game.NoOne}, query.Result)
+	assert.Equal(false, query.Result.Private())
+
+	// White challenges black
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(2), events.GameCreateType).
+		Return([]events.Event{
+			events.NewChallengeCreateEvent(2, whiteId, blackId, game.White),
+		})
+
+	query = InvitationQuery(2).(*invitationQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Black, query.Result.Invitee)
+	assert.Equal(true, query.Result.Private())
+
+	// Private game
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(3), events.GameCreateType).
+		Return([]events.Event{
+			events.NewPrivateGameCreateEvent(3, "", blackId, "secret"),
+		})
+
+	query = InvitationQuery(3).(*invitationQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.NoOne, query.Result.Invitee)
+	assert.Equal("secret", query.Result.Code)
+	assert.Equal(true, query.Result.Private())
+}
+
+func (suite *InvitationQueryTestSuite) TestInviteCode() {
+	var query *inviteCodeQuery
+
+	suite.mockEvents.
+		On("GameCreateEventForInviteCode", "secret").
+		Return(events.NewPrivateGameCreateEvent(3, "alice", "", "secret"))
+	suite.mockEvents.
+		On("GameCreateEventForInviteCode", "unknown").
+		Return(events.Event{})
+
+	assert := assert.New(suite.T())
+
+	query = InviteCodeQuery("secret").(*inviteCodeQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Id(3), query.Result)
+
+	query = InviteCodeQuery("unknown").(*inviteCodeQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Id(0), query.Result)
+}
+
+func TestInvitationQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(InvitationQueryTestSuite))
+}
@@ -0,0 +1,123 @@
+package queries
+
+import (
+	"fmt"
+	"sort"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/ratings"
+	"foodtastechess/users"
+)
+
+type LeaderboardEntry struct {
+	UserId users.Id
+	Value  float64
+}
+
+// Leaderboard ranks the players of a category of game by games played
+// and by their longest run of wins, best first. Ratings are ranked by
+// the ratings service, which keeps them.
+type Leaderboard struct {
+	Games     []LeaderboardEntry
+	WinStreak []LeaderboardEntry
+}
+
+type leaderboardEntries []LeaderboardEntry
+
+func (es leaderboardEntries) Len() int      { return len(es) }
+func (es leaderboardEntries) Swap(i, j int) { es[i], es[j] = es[j], es[i] }
+func (es leaderboardEntries) Less(i, j int) bool {
+	if es[i].Value != es[j].Value {
+		return es[i].Value > es[j].Value
+	}
+	return es[i].UserId < es[j].UserId
+}
+
+// leaderboardQuery goes through every rated game of a category, oldest
+// first, to rank its players
+type leaderboardQuery struct {
+	Category game.TimeCategory
+
+	Answered bool
+	Result   Leaderboard
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *leaderboardQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *leaderboardQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *leaderboardQuery) computeResult(queries SystemQueries) {
+	categories := make(map[game.Id]game.TimeCategory)
+
+	gameCreates := queries.getEvents().EventsOfType(events.GameCreateType)
+	for _, event := range gameCreates {
+		categories[event.GameId] = event.TimeControl().Category()
+	}
+
+	var (
+		games          = make(map[users.Id]int)
+		streaks        = make(map[users.Id]int)
+		longestStreaks = make(map[users.Id]int)
+	)
+
+	score := func(userId users.Id, s float64) {
+		games[userId] += 1
+
+		if s == 1 {
+			streaks[userId] += 1
+		} else {
+			streaks[userId] = 0
+		}
+
+		if streaks[userId] > longestStreaks[userId] {
+			longestStreaks[userId] = streaks[userId]
+		}
+	}
+
+	gameEnds := queries.getEvents().EventsOfType(events.GameEndType)
+	for _, event := range gameEnds {
+		if categories[event.GameId] != q.Category {
+			continue
+		}
+
+		if !ratings.Rated(event) {
+			continue
+		}
+
+		whiteScore := ratings.WhiteScore(event.Winner)
+
+		score(event.WhiteId, whiteScore)
+		score(event.BlackId, 1-whiteScore)
+	}
+
+	q.Result = Leaderboard{
+		Games:     []LeaderboardEntry{},
+		WinStreak: []LeaderboardEntry{},
+	}
+
+	for userId, played := range games {
+		q.Result.Games = append(q.Result.Games, LeaderboardEntry{userId, float64(played)})
+		q.Result.WinStreak = append(q.Result.WinStreak, LeaderboardEntry{userId, float64(longestStreaks[userId])})
+	}
+
+	sort.Sort(leaderboardEntries(q.Result.Games))
+	sort.Sort(leaderboardEntries(q.Result.WinStreak))
+
+	q.Answered = true
+}
+
+func (q *leaderboardQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *leaderboardQuery) hash() string {
+	return fmt.Sprintf("leaderboard:%v", q.Category)
+}
@@ -0,0 +1,84 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type LeaderboardQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *LeaderboardQueryTestSuite) TestHasResult() {
+	var hasResult, noResult *leaderboardQuery
+
+	hasResult = LeaderboardQuery(game.Blitz).(*leaderboardQuery)
+	hasResult.Answered = true
+
+	noResult = LeaderboardQuery(game.Blitz).(*leaderboardQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *LeaderboardQueryTestSuite) TestComputeResult() {
+	var (
+		alice users.Id = "alice"
+		bob   users.Id = "bob"
+		carol users.Id = "carol"
+
+		blitz  game.TimeControl = game.TimeControl{Initial: 300}
+		bullet game.TimeControl = game.TimeControl{Initial: 60}
+
+		query *leaderboardQuery
+	)
+
+	suite.mockEvents.
+		On("EventsOfType", events.GameCreateType).
+		Return([]events.Event{
+			events.NewGameCreateEvent(1, alice, "").WithTimeControl(blitz),
+			events.NewGameCreateEvent(2, alice, "").WithTimeControl(blitz),
+			events.NewGameCreateEvent(3, alice, "").WithTimeControl(blitz),
+			events.NewGameCreateEvent(4, carol, "").WithTimeControl(blitz),
+			events.NewGameCreateEvent(5, bob, "").WithTimeControl(bullet),
+			events.NewGameCreateEvent(6, bob, "").WithTimeControl(blitz),
+		})
+
+	// alice beats bob twice then draws, and loses to carol. Bob's
+	// bullet game and aborted game don't count.
+	suite.mockEvents.
+		On("EventsOfType", events.GameEndType).
+		Return([]events.Event{
+			events.NewGameEndEvent(1, game.GameEndCheckmate, game.White, alice, bob),
+			events.NewGameEndEvent(2, game.GameEndConcede, game.White, alice, bob),
+			events.NewGameEndEvent(3, game.GameEndDraw, game.NoOne, alice, bob),
+			events.NewGameEndEvent(4, game.GameEndCheckmate, game.White, carol, alice),
+			events.NewGameEndEvent(5, game.GameEndCheckmate, game.White, bob, carol),
+			events.NewGameEndEvent(6, game.GameEndAborted, game.NoOne, bob, carol),
+		})
+
+	query = LeaderboardQuery(game.Blitz).(*leaderboardQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, query.Answered)
+
+	assert.Equal([]LeaderboardEntry{
+		{alice, 4}, {bob, 3}, {carol, 1},
+	}, query.Result.Games)
+
+	assert.Equal([]LeaderboardEntry{
+		{alice, 2}, {carol, 1}, {bob, 0},
+	}, query.Result.WinStreak)
+}
+
+func TestLeaderboardQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(LeaderboardQueryTestSuite))
+}
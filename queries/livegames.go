@@ -0,0 +1,60 @@
+package queries
+
+import (
+	"sort"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// liveGamesQuery lists the games that have started and not yet ended,
+// oldest first
+type liveGamesQuery struct {
+	Answered bool
+	Result   []game.Id
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *liveGamesQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *liveGamesQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *liveGamesQuery) computeResult(queries SystemQueries) {
+	liveGames := make(map[game.Id]bool)
+
+	gameStarts := queries.getEvents().EventsOfType(events.GameStartType)
+	gameEnds := queries.getEvents().EventsOfType(events.GameEndType)
+
+	for _, event := range gameStarts {
+		liveGames[event.GameId] = true
+	}
+
+	for _, event := range gameEnds {
+		delete(liveGames, event.GameId)
+	}
+
+	liveGameIds := gameIds{}
+
+	for id, _ := range liveGames {
+		liveGameIds = append(liveGameIds, id)
+	}
+
+	sort.Sort(liveGameIds)
+
+	q.Result = []game.Id(liveGameIds)
+	q.Answered = true
+}
+
+func (q *liveGamesQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *liveGamesQuery) hash() string {
+	return "livegames"
+}
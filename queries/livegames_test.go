@@ -0,0 +1,62 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type LiveGamesQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *LiveGamesQueryTestSuite) TestHasResult() {
+	var hasResult, noResult *liveGamesQuery
+
+	hasResult = LiveGamesQuery().(*liveGamesQuery)
+	hasResult.Answered = true
+
+	noResult = LiveGamesQuery().(*liveGamesQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *LiveGamesQueryTestSuite) TestComputeResult() {
+	var (
+		whiteId users.Id = "alice"
+		blackId users.Id = "bob"
+		query   *liveGamesQuery
+	)
+
+	// games 1-3 are started and 2 has ended
+	suite.mockEvents.
+		On("EventsOfType", events.GameStartType).
+		Return([]events.Event{
+			events.NewGameStartEvent(3, whiteId, blackId),
+			events.NewGameStartEvent(1, whiteId, blackId),
+			events.NewGameStartEvent(2, whiteId, blackId),
+		})
+	suite.mockEvents.
+		On("EventsOfType", events.GameEndType).
+		Return([]events.Event{
+			events.NewGameEndEvent(2, game.GameEndCheckmate, game.White, whiteId, blackId),
+		})
+
+	query = LiveGamesQuery().(*liveGamesQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, query.Answered)
+	assert.Equal([]game.Id{1, 3}, query.Result)
+}
+
+func TestLiveGamesQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(LiveGamesQueryTestSuite))
+}
@@ -0,0 +1,71 @@
+package queries
+
+import (
+	"sort"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// openGamesQuery lists the games that have been created but neither
+// started nor ended, oldest first
+type openGamesQuery struct {
+	Answered bool
+	Result   []game.Id
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+type gameIds []game.Id
+
+func (ids gameIds) Len() int           { return len(ids) }
+func (ids gameIds) Less(i, j int) bool { return ids[i] < ids[j] }
+func (ids gameIds) Swap(i, j int)      { ids[i], ids[j] = ids[j], ids[i] }
+
+func (q *openGamesQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *openGamesQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *openGamesQuery) computeResult(queries SystemQueries) {
+	openGames := make(map[game.Id]bool)
+
+	gameCreates := queries.getEvents().EventsOfType(events.GameCreateType)
+	gameStarts := queries.getEvents().EventsOfType(events.GameStartType)
+	gameEnds := queries.getEvents().EventsOfType(events.GameEndType)
+
+	for _, event := range gameCreates {
+		openGames[event.GameId] = true
+	}
+
+	for _, event := range gameStarts {
+		delete(openGames, event.GameId)
+	}
+
+	for _, event := range gameEnds {
+		delete(openGames, event.GameId)
+	}
+
+	openGameIds := gameIds{}
+
+	for id, _ := range openGames {
+		openGameIds = append(openGameIds, id)
+	}
+
+	sort.Sort(openGameIds)
+
+	q.Result = []game.Id(openGameIds)
+	q.Answered = true
+}
+
+func (q *openGamesQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *openGamesQuery) hash() string {
+	return "opengames"
+}
@@ -0,0 +1,68 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type OpenGamesQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *OpenGamesQueryTestSuite) TestHasResult() {
+	var hasResult, noResult *openGamesQuery
+
+	hasResult = OpenGamesQuery().(*openGamesQuery)
+	hasResult.Answered = true
+
+	noResult = OpenGamesQuery().(*openGamesQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *OpenGamesQueryTestSuite) TestComputeResult() {
+	var (
+		whiteId users.Id = "alice"
+		blackId users.Id = "bob"
+		query   *openGamesQuery
+	)
+
+	// games 1-4 are created, 2 is started and 3 is aborted
+	suite.mockEvents.
+		On("EventsOfType", events.GameCreateType).
+		Return([]events.Event{
+			events.NewGameCreateEvent(4, "", blackId),
+			events.NewGameCreateEvent(1, whiteId, ""),
+			events.NewGameCreateEvent(2, whiteId, ""),
+			events.NewGameCreateEvent(3, "", blackId),
+		})
+	suite.mockEvents.
+		On("EventsOfType", events.GameStartType).
+		Return([]events.Event{
+			events.NewGameStartEvent(2, whiteId, blackId),
+		})
+	suite.mockEvents.
+		On("EventsOfType", events.GameEndType).
+		Return([]events.Event{
+			events.NewGameEndEvent(3, game.GameEndAborted, game.NoOne, "", blackId),
+		})
+
+	query = OpenGamesQuery().(*openGamesQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, query.Answered)
+	assert.Equal([]game.Id{1, 4}, query.Result)
+}
+
+func TestOpenGamesQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(OpenGamesQueryTestSuite))
+}
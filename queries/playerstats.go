@@ -0,0 +1,106 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type Record struct {
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+func (r *Record) add(player, winner game.Color) {
+	switch winner {
+	case game.NoOne:
+		r.Draws += 1
+	case player:
+		r.Wins += 1
+	default:
+		r.Losses += 1
+	}
+}
+
+// PlayerStats breaks down a player's results over their finished games
+type PlayerStats struct {
+	Total      Record
+	ByColor    map[game.Color]Record
+	ByReason   map[game.GameEndReason]Record
+	ByOpponent map[users.Id]Record
+}
+
+type playerStatsQuery struct {
+	PlayerId users.Id
+
+	Answered bool
+	Result   PlayerStats
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *playerStatsQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *playerStatsQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *playerStatsQuery) computeResult(queries SystemQueries) {
+	stats := PlayerStats{
+		ByColor:    make(map[game.Color]Record),
+		ByReason:   make(map[game.GameEndReason]Record),
+		ByOpponent: make(map[users.Id]Record),
+	}
+
+	gameEnds := queries.getEvents().
+		EventsOfTypeForPlayer(q.PlayerId, events.GameEndType)
+
+	for _, event := range gameEnds {
+		// aborted games never really happened
+		if event.Reason == game.GameEndAborted {
+			continue
+		}
+
+		var (
+			color    game.Color
+			opponent users.Id
+		)
+
+		if event.WhiteId == q.PlayerId {
+			color, opponent = game.White, event.BlackId
+		} else {
+			color, opponent = game.Black, event.WhiteId
+		}
+
+		stats.Total.add(color, event.Winner)
+
+		byColor := stats.ByColor[color]
+		byColor.add(color, event.Winner)
+		stats.ByColor[color] = byColor
+
+		byReason := stats.ByReason[event.Reason]
+		byReason.add(color, event.Winner)
+		stats.ByReason[event.Reason] = byReason
+
+		byOpponent := stats.ByOpponent[opponent]
+		byOpponent.add(color, event.Winner)
+		stats.ByOpponent[opponent] = byOpponent
+	}
+
+	q.Result = stats
+	q.Answered = true
+}
+
+func (q *playerStatsQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *playerStatsQuery) hash() string {
+	return fmt.Sprintf("playerstats:%v", q.PlayerId)
+}
@@ -0,0 +1,76 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type PlayerStatsQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *PlayerStatsQueryTestSuite) TestHasResult() {
+	var (
+		playerId            users.Id = "chloe"
+		hasResult, noResult *playerStatsQuery
+	)
+
+	hasResult = PlayerStatsQuery(playerId).(*playerStatsQuery)
+	hasResult.Answered = true
+
+	noResult = PlayerStatsQuery(playerId).(*playerStatsQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *PlayerStatsQueryTestSuite) TestComputeResult() {
+	var (
+		alice users.Id = "alice"
+		bob   users.Id = "bob"
+		carol users.Id = "carol"
+		query *playerStatsQuery
+	)
+
+	suite.mockEvents.
+		On("EventsOfTypeForPlayer", alice, events.GameEndType).
+		Return([]events.Event{
+			events.NewGameEndEvent(1, game.GameEndCheckmate, game.White, alice, bob),
+			events.NewGameEndEvent(2, game.GameEndConcede, game.White, bob, alice),
+			events.NewGameEndEvent(3, game.GameEndDraw, game.NoOne, alice, carol),
+			events.NewGameEndEvent(4, game.GameEndCheckmate, game.Black, carol, alice),
+			events.NewGameEndEvent(5, game.GameEndAborted, game.NoOne, alice, ""),
+		})
+
+	query = PlayerStatsQuery(alice).(*playerStatsQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, query.Answered)
+
+	stats := query.Result
+	assert.Equal(Record{Wins: 2, Losses: 1, Draws: 1}, stats.Total)
+
+	assert.Equal(Record{Wins: 1, Draws: 1}, stats.ByColor[game.White])
+	assert.Equal(Record{Wins: 1, Losses: 1}, stats.ByColor[game.Black])
+
+	assert.Equal(Record{Wins: 2}, stats.ByReason[game.GameEndCheckmate])
+	assert.Equal(Record{Losses: 1}, stats.ByReason[game.GameEndConcede])
+	assert.Equal(Record{Draws: 1}, stats.ByReason[game.GameEndDraw])
+	assert.Equal(3, len(stats.ByReason))
+
+	assert.Equal(Record{Wins: 1, Losses: 1}, stats.ByOpponent[bob])
+	assert.Equal(Record{Wins: 1, Draws: 1}, stats.ByOpponent[carol])
+	assert.Equal(2, len(stats.ByOpponent))
+}
+
+func TestPlayerStatsQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(PlayerStatsQueryTestSuite))
+}
@@ -0,0 +1,49 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// previousGameQuery finds the game that a rematch was created from, or 0
+// for games that are not rematches
+type previousGameQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   game.Id
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *previousGameQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *previousGameQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *previousGameQuery) computeResult(queries SystemQueries) {
+	q.Answered = true
+
+	gameCreates := queries.getEvents().
+		EventsOfTypeForGame(q.GameId, events.GameCreateType)
+	if len(gameCreates) == 0 {
+		q.Result = 0
+		return
+	}
+
+	q.Result = gameCreates[0].LinkedGameId
+}
+
+func (q *previousGameQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *previousGameQuery) hash() string {
+	return fmt.Sprintf("previousgame:%v", q.GameId)
+}
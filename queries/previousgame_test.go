@@ -0,0 +1,68 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type PreviousGameQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *PreviousGameQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *previousGameQuery
+	)
+
+	hasResult = PreviousGameQuery(gameId).(*previousGameQuery)
+	hasResult.Answered = true
+
+	noResult = PreviousGameQuery(gameId).(*previousGameQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *PreviousGameQueryTestSuite) TestComputeResult() {
+	assert := assert.New(suite.T())
+
+	var (
+		whiteId users.Id = "alice"
+		blackId users.Id = "bob"
+		query   *previousGameQuery
+	)
+
+	// Not a rematch
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(1), events.GameCreateType).
+		Return([]events.Event{
+			events.NewGameCreateEvent(1, whiteId, ""),
+		})
+
+	query = PreviousGameQuery(1).(*previousGameQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Id(0), query.Result)
+
+	// Rematch of game 1
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(2), events.GameCreateType).
+		Return([]events.Event{
+			events.NewRematchCreateEvent(2, 1, blackId, whiteId),
+		})
+
+	query = PreviousGameQuery(2).(*previousGameQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Id(1), query.Result)
+}
+
+func TestPreviousGameQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(PreviousGameQueryTestSuite))
+}
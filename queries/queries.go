@@ -86,3 +86,94 @@ func GameEndQuery(gameId game.Id) Query {
 		GameId: gameId,
 	}
 }
+
+func TakebackStateQuery(gameId game.Id) Query {
+	return &takebackStateQuery{
+		GameId: gameId,
+	}
+}
+
+func RematchQuery(gameId game.Id) Query {
+	return &rematchQuery{
+		GameId: gameId,
+	}
+}
+
+func PreviousGameQuery(gameId game.Id) Query {
+	return &previousGameQuery{
+		GameId: gameId,
+	}
+}
+
+func InvitationQuery(gameId game.Id) Query {
+	return &invitationQuery{
+		GameId: gameId,
+	}
+}
+
+func InviteCodeQuery(code string) Query {
+	return &inviteCodeQuery{
+		Code: code,
+	}
+}
+
+func OpenGamesQuery() Query {
+	return &openGamesQuery{}
+}
+
+func TimeControlQuery(gameId game.Id) Query {
+	return &timeControlQuery{
+		GameId: gameId,
+	}
+}
+
+func LeaderboardQuery(category game.TimeCategory) Query {
+	return &leaderboardQuery{
+		Category: category,
+	}
+}
+
+func PlayerStatsQuery(playerId users.Id) Query {
+	return &playerStatsQuery{
+		PlayerId: playerId,
+	}
+}
+
+func HeadToHeadQuery(playerId, opponentId users.Id) Query {
+	if opponentId < playerId {
+		playerId, opponentId = opponentId, playerId
+	}
+
+	return &headToHeadQuery{
+		PlayerA: playerId,
+		PlayerB: opponentId,
+	}
+}
+
+func ClockQuery(gameId game.Id) Query {
+	return &clockQuery{
+		GameId: gameId,
+	}
+}
+
+func VisibilityQuery(gameId game.Id) Query {
+	return &visibilityQuery{
+		GameId: gameId,
+	}
+}
+
+func LiveGamesQuery() Query {
+	return &liveGamesQuery{}
+}
+
+func ChatQuery(gameId game.Id) Query {
+	return &chatQuery{
+		GameId: gameId,
+	}
+}
+
+func AnnotationsQuery(gameId game.Id) Query {
+	return &annotationsQuery{
+		GameId: gameId,
+	}
+}
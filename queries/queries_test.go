@@ -10,6 +10,7 @@ import (
 	"foodtastechess/events"
 	"foodtastechess/game"
 	"foodtastechess/logger"
+	"foodtastechess/ratings"
 	"foodtastechess/users"
 )
 
@@ -74,6 +75,35 @@ func (m *MockUsers) Save(user *users.User) error {
 	return args.Error(0)
 }
 
+// MockRatings is a mock for the ratings service
+type MockRatings struct {
+	mock.Mock
+}
+
+func (m *MockRatings) Receive(event events.Event) error {
+	return nil
+}
+
+func (m *MockRatings) Rating(userId users.Id, category game.TimeCategory) float64 {
+	args := m.Called(userId, category)
+	return args.Get(0).(float64)
+}
+
+func (m *MockRatings) Ratings(userId users.Id) map[game.TimeCategory]float64 {
+	args := m.Called(userId)
+	return args.Get(0).(map[game.TimeCategory]float64)
+}
+
+func (m *MockRatings) History(userId users.Id, category game.TimeCategory) []ratings.HistoryEntry {
+	args := m.Called(userId, category)
+	return args.Get(0).([]ratings.HistoryEntry)
+}
+
+func (m *MockRatings) Leaders(category game.TimeCategory, limit int) []ratings.PlayerRating {
+	args := m.Called(category, limit)
+	return args.Get(0).([]ratings.PlayerRating)
+}
+
 // MockSystemQueries is a mock that we're going to use as a
 // SystemQueryInterface
 type MockSystemQueries struct {
@@ -150,6 +180,11 @@ func (m *MockEventsService) EventsForGame(gameId game.Id) []events.Event {
 	return args.Get(0).([]events.Event)
 }
 
+func (m *MockEventsService) EventsOfType(eventType events.EventType) []events.Event {
+	args := m.Called(eventType)
+	return args.Get(0).([]events.Event)
+}
+
 func (m *MockEventsService) EventsOfTypeForGame(gameId game.Id, eventType events.EventType) []events.Event {
 	args := m.Called(gameId, eventType)
 	return args.Get(0).([]events.Event)
@@ -160,11 +195,21 @@ func (m *MockEventsService) EventsOfTypeForPlayer(userId users.Id, eventType eve
 	return args.Get(0).([]events.Event)
 }
 
+func (m *MockEventsService) EventsForGamesSince(gameIds []game.Id, lastId int) []events.Event {
+	args := m.Called(gameIds, lastId)
+	return args.Get(0).([]events.Event)
+}
+
 func (m *MockEventsService) MoveEventForGameAtTurn(gameId game.Id, turnNumber game.TurnNumber) events.Event {
 	args := m.Called(gameId, turnNumber)
 	return args.Get(0).(events.Event)
 }
 
+func (m *MockEventsService) GameCreateEventForInviteCode(inviteCode string) events.Event {
+	args := m.Called(inviteCode)
+	return args.Get(0).(events.Event)
+}
+
 func (m *MockEventsService) NextGameId() game.Id {
 	args := m.Called()
 	return args.Get(0).(game.Id)
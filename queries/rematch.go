@@ -0,0 +1,66 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// Rematch describes the most recent rematch offered after a game
+type Rematch struct {
+	Offered  bool
+	GameId   game.Id
+	Offerer  game.Color
+	Answered bool
+	Accepted bool
+}
+
+type rematchQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   Rematch
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *rematchQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *rematchQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *rematchQuery) computeResult(queries SystemQueries) {
+	offers := queries.getEvents().EventsOfTypeForGame(q.GameId, events.RematchOfferType)
+	responses := queries.getEvents().EventsOfTypeForGame(q.GameId, events.RematchResponseType)
+
+	q.Answered = true
+	if len(offers) == 0 {
+		q.Result = Rematch{Offered: false}
+		return
+	}
+
+	lastOffer := offers[len(offers)-1]
+	q.Result = Rematch{
+		Offered:  true,
+		GameId:   lastOffer.LinkedGameId,
+		Offerer:  lastOffer.Offerer,
+		Answered: len(responses) == len(offers),
+	}
+
+	if q.Result.Answered {
+		q.Result.Accepted = responses[len(responses)-1].OfferAccept
+	}
+}
+
+func (q *rematchQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *rematchQuery) hash() string {
+	return fmt.Sprintf("rematch:%v", q.GameId)
+}
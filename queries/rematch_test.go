@@ -0,0 +1,113 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+type RematchQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *RematchQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *rematchQuery
+	)
+
+	hasResult = RematchQuery(gameId).(*rematchQuery)
+	hasResult.Answered = true
+
+	noResult = RematchQuery(gameId).(*rematchQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *RematchQueryTestSuite) TestDependentQueries() {
+	var (
+		gameId game.Id = 1
+		query  *rematchQuery
+
+		expectedDependents = []Query{}
+	)
+
+	query = RematchQuery(gameId).(*rematchQuery)
+
+	actualDependents := query.getDependentQueries()
+
+	assert := assert.New(suite.T())
+	assert.Equal(expectedDependents, actualDependents)
+}
+
+func (suite *RematchQueryTestSuite) TestComputeResult() {
+	assert := assert.New(suite.T())
+
+	var (
+		gameId game.Id
+		query  *rematchQuery
+	)
+
+	// No Offer
+	gameId = 1
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.RematchOfferType).
+		Return([]events.Event{})
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.RematchResponseType).
+		Return([]events.Event{})
+
+	query = RematchQuery(gameId).(*rematchQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(false, query.Result.Offered)
+
+	// Offer -> No Response
+	gameId = 2
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.RematchOfferType).
+		Return([]events.Event{
+			events.NewRematchOfferEvent(gameId, 12, game.Black),
+		})
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.RematchResponseType).
+		Return([]events.Event{})
+
+	query = RematchQuery(gameId).(*rematchQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(true, query.Result.Offered)
+	assert.Equal(game.Id(12), query.Result.GameId)
+	assert.Equal(game.Black, query.Result.Offerer)
+	assert.Equal(false, query.Result.Answered)
+
+	// Offer -> Decline -> New Offer -> Accept
+	gameId = 3
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.RematchOfferType).
+		Return([]events.Event{
+			events.NewRematchOfferEvent(gameId, 13, game.White),
+			events.NewRematchOfferEvent(gameId, 14, game.Black),
+		})
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.RematchResponseType).
+		Return([]events.Event{
+			events.NewRematchResponseEvent(gameId, false),
+			events.NewRematchResponseEvent(gameId, true),
+		})
+
+	query = RematchQuery(gameId).(*rematchQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Id(14), query.Result.GameId)
+	assert.Equal(game.Black, query.Result.Offerer)
+	assert.Equal(true, query.Result.Answered)
+	assert.Equal(true, query.Result.Accepted)
+}
+
+func TestRematchQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(RematchQueryTestSuite))
+}
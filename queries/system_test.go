@@ -96,6 +96,7 @@ func (suite *SystemQueriesTestSuite) TestCompute() {
 	}
 	expected := game.TurnNumber(len(fakeMoves))
 	suite.mockEvents.On("EventsOfTypeForGame", gameId, events.MoveType).Return(fakeMoves).Once()
+	suite.mockEvents.On("EventsOfTypeForGame", gameId, events.MoveRetractType).Return([]events.Event{}).Once()
 
 	suite.mockQueriesCache.On("Store", query).Return().Once()
 
@@ -165,6 +166,7 @@ func (suite *SystemQueriesTestSuite) TestQueryStorage() {
 		events.NewMoveEvent(gameId, 3, ""),
 	}
 	suite.mockEvents.On("EventsOfTypeForGame", gameId, events.MoveType).Return(fakeMoves).Once()
+	suite.mockEvents.On("EventsOfTypeForGame", gameId, events.MoveRetractType).Return([]events.Event{}).Once()
 
 	suite.mockQueriesCache.On("Store", query).Return().Once()
 
@@ -188,6 +190,7 @@ func (suite *SystemQueriesTestSuite) TestInvalidation() {
 		events.NewMoveEvent(gameId, 3, ""),
 	}
 	suite.mockEvents.On("EventsOfTypeForGame", gameId, events.MoveType).Return(fakeMoves).Once()
+	suite.mockEvents.On("EventsOfTypeForGame", gameId, events.MoveRetractType).Return([]events.Event{}).Once()
 
 	suite.mockQueriesCache.On("Delete", query).Return().Once()
 	suite.mockQueriesCache.On("Store", query).Return().Once()
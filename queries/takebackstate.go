@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+type takebackStateQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   game.Color
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *takebackStateQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *takebackStateQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *takebackStateQuery) computeResult(queries SystemQueries) {
+	requests := queries.getEvents().EventsOfTypeForGame(q.GameId, events.TakebackRequestType)
+	responses := queries.getEvents().EventsOfTypeForGame(q.GameId, events.TakebackResponseType)
+
+	q.Answered = true
+	if len(responses) == len(requests) {
+		q.Result = game.NoOne
+		return
+	}
+
+	lastRequest := requests[len(requests)-1]
+	if lastRequest.Offerer == game.White {
+		q.Result = game.White
+	} else {
+		q.Result = game.Black
+	}
+}
+
+func (q *takebackStateQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *takebackStateQuery) hash() string {
+	return fmt.Sprintf("takeback:%v", q.GameId)
+}
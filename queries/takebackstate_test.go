@@ -0,0 +1,110 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+type TakebackStateQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *TakebackStateQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *takebackStateQuery
+	)
+
+	hasResult = TakebackStateQuery(gameId).(*takebackStateQuery)
+	hasResult.Answered = true
+
+	noResult = TakebackStateQuery(gameId).(*takebackStateQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *TakebackStateQueryTestSuite) TestDependentQueries() {
+	var (
+		gameId game.Id = 1
+		query  *takebackStateQuery
+
+		expectedDependents = []Query{}
+	)
+
+	query = TakebackStateQuery(gameId).(*takebackStateQuery)
+
+	actualDependents := query.getDependentQueries()
+
+	assert := assert.New(suite.T())
+	assert.Equal(expectedDependents, actualDependents)
+}
+
+func (suite *TakebackStateQueryTestSuite) TestComputeResult() {
+	assert := assert.New(suite.T())
+
+	var (
+		gameId game.Id
+		query  *takebackStateQuery
+	)
+
+	// Request -> No Response
+	gameId = 1
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.TakebackRequestType).
+		Return([]events.Event{
+			events.NewTakebackRequestEvent(gameId, game.White),
+		})
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.TakebackResponseType).
+		Return([]events.Event{})
+
+	query = TakebackStateQuery(gameId).(*takebackStateQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.White, query.Result)
+
+	// Request -> Accept
+	gameId = 2
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.TakebackRequestType).
+		Return([]events.Event{
+			events.NewTakebackRequestEvent(gameId, game.Black),
+		})
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.TakebackResponseType).
+		Return([]events.Event{
+			events.NewTakebackResponseEvent(gameId, true),
+		})
+
+	query = TakebackStateQuery(gameId).(*takebackStateQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.NoOne, query.Result)
+
+	// Request -> Reject -> New Request
+	gameId = 3
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.TakebackRequestType).
+		Return([]events.Event{
+			events.NewTakebackRequestEvent(gameId, game.White),
+			events.NewTakebackRequestEvent(gameId, game.Black),
+		})
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.TakebackResponseType).
+		Return([]events.Event{
+			events.NewTakebackResponseEvent(gameId, false),
+		})
+
+	query = TakebackStateQuery(gameId).(*takebackStateQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Black, query.Result)
+}
+
+func TestTakebackStateQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(TakebackStateQueryTestSuite))
+}
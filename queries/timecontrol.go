@@ -0,0 +1,48 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// timeControlQuery finds the clock a game was created with
+type timeControlQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   game.TimeControl
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *timeControlQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *timeControlQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *timeControlQuery) computeResult(queries SystemQueries) {
+	q.Answered = true
+
+	gameCreates := queries.getEvents().
+		EventsOfTypeForGame(q.GameId, events.GameCreateType)
+	if len(gameCreates) == 0 {
+		q.Result = game.TimeControl{}
+		return
+	}
+
+	q.Result = gameCreates[0].TimeControl()
+}
+
+func (q *timeControlQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *timeControlQuery) hash() string {
+	return fmt.Sprintf("timecontrol:%v", q.GameId)
+}
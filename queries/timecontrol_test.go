@@ -0,0 +1,56 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type TimeControlQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *TimeControlQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *timeControlQuery
+	)
+
+	hasResult = TimeControlQuery(gameId).(*timeControlQuery)
+	hasResult.Answered = true
+
+	noResult = TimeControlQuery(gameId).(*timeControlQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *TimeControlQueryTestSuite) TestComputeResult() {
+	var (
+		whiteId  users.Id         = "alice"
+		expected game.TimeControl = game.TimeControl{Initial: 180, Increment: 2}
+		query    *timeControlQuery
+	)
+
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(1), events.GameCreateType).
+		Return([]events.Event{
+			events.NewGameCreateEvent(1, whiteId, "").WithTimeControl(expected),
+		})
+
+	query = TimeControlQuery(1).(*timeControlQuery)
+	query.computeResult(suite.mockSystemQueries)
+
+	assert := assert.New(suite.T())
+	assert.Equal(expected, query.Result)
+}
+
+func TestTimeControlQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(TimeControlQueryTestSuite))
+}
@@ -30,7 +30,11 @@ func (q *turnNumberQuery) getResult() interface{} {
 
 func (q *turnNumberQuery) computeResult(queries SystemQueries) {
 	moves := queries.getEvents().EventsOfTypeForGame(q.GameId, events.MoveType)
-	q.Result = game.TurnNumber(len(moves))
+	retracts := queries.getEvents().EventsOfTypeForGame(q.GameId, events.MoveRetractType)
+
+	// every retracted move has been (or will be) replaced by a new move
+	// at the same turn, so it must not be counted twice
+	q.Result = game.TurnNumber(len(moves) - len(retracts))
 }
 
 func (q *turnNumberQuery) getDependentQueries() []Query {
@@ -62,6 +62,10 @@ func (suite *TurnNumberQueryTestSuite) TestComputeResult() {
 		On("EventsOfTypeForGame", gameId, events.MoveType).
 		Return([]events.Event{}).
 		Once()
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.MoveRetractType).
+		Return([]events.Event{}).
+		Once()
 
 	query.computeResult(suite.mockSystemQueries)
 
@@ -78,9 +82,37 @@ func (suite *TurnNumberQueryTestSuite) TestComputeResult() {
 		On("EventsOfTypeForGame", gameId, events.MoveType).
 		Return(fakeMoves).
 		Once()
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.MoveRetractType).
+		Return([]events.Event{}).
+		Once()
 
 	query.computeResult(suite.mockSystemQueries)
 	assert.Equal(game.TurnNumber(3), query.Result)
+
+	// case 3: moves 2 and 3 taken back, then move 2 replayed
+
+	fakeMoves = []events.Event{
+		events.NewMoveEvent(gameId, 1, ""),
+		events.NewMoveEvent(gameId, 2, ""),
+		events.NewMoveEvent(gameId, 3, ""),
+		events.NewMoveEvent(gameId, 2, ""),
+	}
+	fakeRetracts := []events.Event{
+		events.NewMoveRetractEvent(gameId, 3),
+		events.NewMoveRetractEvent(gameId, 2),
+	}
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.MoveType).
+		Return(fakeMoves).
+		Once()
+	suite.mockEvents.
+		On("EventsOfTypeForGame", gameId, events.MoveRetractType).
+		Return(fakeRetracts).
+		Once()
+
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.TurnNumber(2), query.Result)
 }
 
 func TestTurnNumberQueryTestSuite(t *testing.T) {
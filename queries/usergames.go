@@ -35,10 +35,8 @@ func (q *userGamesQuery) computeResult(queries SystemQueries) {
 	gameStarts := queries.getEvents().
 		EventsOfTypeForPlayer(q.PlayerId, events.GameStartType)
 
-	/*
-		gameEnds := queries.getEvents().
-			EventsOfTypeForPlayer(q.PlayerId, events.GameEndType)
-	*/
+	gameEnds := queries.getEvents().
+		EventsOfTypeForPlayer(q.PlayerId, events.GameEndType)
 
 	for _, event := range gameCreates {
 		activeGames[event.GameId] = true
@@ -48,11 +46,13 @@ func (q *userGamesQuery) computeResult(queries SystemQueries) {
 		activeGames[event.GameId] = true
 	}
 
-	/*
-		for _, event := range gameEnds {
+	// finished games stay listed, but aborted games never really
+	// happened
+	for _, event := range gameEnds {
+		if event.Reason == game.GameEndAborted {
 			delete(activeGames, event.GameId)
 		}
-	*/
+	}
 
 	activeGameIds := []game.Id{}
 
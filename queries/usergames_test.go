@@ -53,6 +53,7 @@ func (suite *UserGamesQueryTestSuite) TestComputeResult() {
 		query         *userGamesQuery
 		activeGames   []game.Id = []game.Id{5, 6, 7}
 		finishedGames []game.Id = []game.Id{1, 2, 3, 4}
+		abortedGames  []game.Id = []game.Id{8, 9}
 		gameCreates   []events.Event
 		gameStarts    []events.Event
 		gameEnds      []events.Event
@@ -67,6 +68,10 @@ func (suite *UserGamesQueryTestSuite) TestComputeResult() {
 		gameStarts = append(gameStarts, events.NewGameStartEvent(id, playerId, playerId))
 		gameEnds = append(gameEnds, events.NewGameEndEvent(id, game.GameEndCheckmate, game.Black, playerId, playerId))
 	}
+	for _, id := range abortedGames {
+		gameCreates = append(gameCreates, events.NewGameCreateEvent(id, playerId, ""))
+		gameEnds = append(gameEnds, events.NewGameEndEvent(id, game.GameEndAborted, game.NoOne, playerId, ""))
+	}
 
 	suite.mockEvents.
 		On("EventsOfTypeForPlayer", playerId, events.GameCreateType).
@@ -96,6 +101,10 @@ func (suite *UserGamesQueryTestSuite) TestComputeResult() {
 		assert.Contains(query.Result, id)
 	}
 
+	for _, id := range abortedGames {
+		assert.NotContains(query.Result, id)
+	}
+
 	assert.Equal(len(activeGames)+len(finishedGames), len(query.Result))
 
 	assert.Equal(true, query.hasResult())
@@ -0,0 +1,46 @@
+package queries
+
+import (
+	"fmt"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+)
+
+// visibilityQuery finds who may view a game
+type visibilityQuery struct {
+	GameId game.Id
+
+	Answered bool
+	Result   game.Visibility
+
+	// Compose a queryRecord
+	queryRecord `bson:",inline"`
+}
+
+func (q *visibilityQuery) hasResult() bool {
+	return q.Answered
+}
+
+func (q *visibilityQuery) getResult() interface{} {
+	return q.Result
+}
+
+func (q *visibilityQuery) computeResult(queries SystemQueries) {
+	q.Answered = true
+	q.Result = game.Public
+
+	gameCreates := queries.getEvents().
+		EventsOfTypeForGame(q.GameId, events.GameCreateType)
+	if len(gameCreates) > 0 && gameCreates[0].Visibility != "" {
+		q.Result = gameCreates[0].Visibility
+	}
+}
+
+func (q *visibilityQuery) getDependentQueries() []Query {
+	return []Query{}
+}
+
+func (q *visibilityQuery) hash() string {
+	return fmt.Sprintf("visibility:%v", q.GameId)
+}
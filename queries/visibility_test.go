@@ -0,0 +1,65 @@
+package queries
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type VisibilityQueryTestSuite struct {
+	QueryTestSuite
+}
+
+func (suite *VisibilityQueryTestSuite) TestHasResult() {
+	var (
+		gameId              game.Id = 5
+		hasResult, noResult *visibilityQuery
+	)
+
+	hasResult = VisibilityQuery(gameId).(*visibilityQuery)
+	hasResult.Answered = true
+
+	noResult = VisibilityQuery(gameId).(*visibilityQuery)
+	noResult.Answered = false
+
+	assert := assert.New(suite.T())
+	assert.Equal(true, hasResult.hasResult())
+	assert.Equal(false, noResult.hasResult())
+}
+
+func (suite *VisibilityQueryTestSuite) TestComputeResult() {
+	var (
+		whiteId users.Id = "alice"
+		query   *visibilityQuery
+	)
+
+	// game 2 was created before games had a visibility
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(1), events.GameCreateType).
+		Return([]events.Event{
+			events.NewGameCreateEvent(1, whiteId, "").WithVisibility(game.Private),
+		})
+	suite.mockEvents.
+		On("EventsOfTypeForGame", game.Id(2), events.GameCreateType).
+		Return([]events.Event{
+			events.NewGameCreateEvent(2, whiteId, ""),
+		})
+
+	assert := assert.New(suite.T())
+
+	query = VisibilityQuery(1).(*visibilityQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Private, query.Result)
+
+	query = VisibilityQuery(2).(*visibilityQuery)
+	query.computeResult(suite.mockSystemQueries)
+	assert.Equal(game.Public, query.Result)
+}
+
+func TestVisibilityQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(VisibilityQueryTestSuite))
+}
@@ -0,0 +1,138 @@
+package ratings
+
+import (
+	"math"
+)
+
+// Glicko-2, as described by Mark Glickman in "Example of the Glicko-2
+// system" (http://www.glicko.net/glicko/glicko2.pdf). Ratings are updated
+// after every game, each game being its own rating period.
+
+const (
+	DefaultRating     = 1500.0
+	DefaultDeviation  = 350.0
+	DefaultVolatility = 0.06
+
+	// constrains how quickly volatility changes
+	tau = 0.5
+
+	// converts between the Glicko and Glicko-2 scales
+	scale = 173.7178
+
+	// convergence tolerance for the volatility iteration
+	epsilon = 0.000001
+)
+
+// Glicko is a player's rating, with the deviation and volatility that
+// describe how reliable it is
+type Glicko struct {
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+}
+
+// NewGlicko is the rating of a player who has yet to play
+func NewGlicko() Glicko {
+	return Glicko{
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// Outcome is a game against an opponent, scored 1 for a win, 0.5 for a
+// draw and 0 for a loss
+type Outcome struct {
+	Opponent Glicko
+	Score    float64
+}
+
+func (r Glicko) mu() float64 {
+	return (r.Rating - DefaultRating) / scale
+}
+
+func (r Glicko) phi() float64 {
+	return r.Deviation / scale
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, phiJ float64) float64 {
+	return 1 / (1 + math.Exp(-g(phiJ)*(mu-muJ)))
+}
+
+// Update rates a player on the games of one rating period
+func (r Glicko) Update(outcomes []Outcome) Glicko {
+	mu, phi, sigma := r.mu(), r.phi(), r.Volatility
+
+	// a player who sat the period out only becomes less certain
+	if len(outcomes) == 0 {
+		phi = math.Sqrt(phi*phi + sigma*sigma)
+		return Glicko{r.Rating, phi * scale, sigma}
+	}
+
+	var vInverse, improvement float64
+	for _, outcome := range outcomes {
+		gJ := g(outcome.Opponent.phi())
+		e := expected(mu, outcome.Opponent.mu(), outcome.Opponent.phi())
+
+		vInverse += gJ * gJ * e * (1 - e)
+		improvement += gJ * (outcome.Score - e)
+	}
+
+	v := 1 / vInverse
+	delta := v * improvement
+
+	sigma = volatility(delta, phi, v, sigma)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu = mu + phi*phi*improvement
+
+	return Glicko{
+		Rating:     mu*scale + DefaultRating,
+		Deviation:  phi * scale,
+		Volatility: sigma,
+	}
+}
+
+// volatility finds the new volatility with the Illinois algorithm
+func volatility(delta, phi, v, sigma float64) float64 {
+	a := math.Log(sigma * sigma)
+
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k += 1
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+
+		if fC*fB < 0 {
+			A, fA = B, fB
+		} else {
+			fA = fA / 2
+		}
+
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
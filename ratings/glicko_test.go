@@ -0,0 +1,54 @@
+package ratings
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type GlickoTestSuite struct {
+	suite.Suite
+}
+
+func TestGlickoTestSuite(t *testing.T) {
+	suite.Run(t, new(GlickoTestSuite))
+}
+
+// TestGlickmanExample checks the worked example from Glickman's paper
+func (s *GlickoTestSuite) TestGlickmanExample() {
+	player := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+
+	updated := player.Update([]Outcome{
+		{Opponent: Glicko{1400, 30, 0.06}, Score: 1},
+		{Opponent: Glicko{1550, 100, 0.06}, Score: 0},
+		{Opponent: Glicko{1700, 300, 0.06}, Score: 0},
+	})
+
+	assert := assert.New(s.T())
+	assert.InDelta(1464.06, updated.Rating, 0.01)
+	assert.InDelta(151.52, updated.Deviation, 0.01)
+	assert.InDelta(0.05999, updated.Volatility, 0.00001)
+}
+
+func (s *GlickoTestSuite) TestNoGames() {
+	player := Glicko{Rating: 1500, Deviation: 200, Volatility: 0.06}
+
+	updated := player.Update([]Outcome{})
+
+	assert := assert.New(s.T())
+	assert.Equal(1500.0, updated.Rating)
+	assert.InDelta(200.2714, updated.Deviation, 0.0001)
+}
+
+func (s *GlickoTestSuite) TestWinnerGains() {
+	white, black := NewGlicko(), NewGlicko()
+
+	newWhite := white.Update([]Outcome{{Opponent: black, Score: 1}})
+	newBlack := black.Update([]Outcome{{Opponent: white, Score: 0}})
+
+	assert := assert.New(s.T())
+	assert.True(newWhite.Rating > DefaultRating)
+	assert.True(newBlack.Rating < DefaultRating)
+	assert.InDelta(newWhite.Rating-DefaultRating, DefaultRating-newBlack.Rating, 0.0001)
+	assert.True(newWhite.Deviation < DefaultDeviation)
+}
@@ -0,0 +1,51 @@
+package ratings
+
+import (
+	"fmt"
+	"time"
+
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+// PlayerRating is a player's current rating in one category of game
+type PlayerRating struct {
+	Id         int               `json:"-"`
+	UserId     users.Id          `sql:"index" json:"-"`
+	Category   game.TimeCategory `sql:"index"`
+	Rating     float64
+	Deviation  float64
+	Volatility float64
+	Games      int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (r PlayerRating) TableName() string {
+	return fmt.Sprintf("%sratings", tablePrefix)
+}
+
+func (r PlayerRating) glicko() Glicko {
+	return Glicko{
+		Rating:     r.Rating,
+		Deviation:  r.Deviation,
+		Volatility: r.Volatility,
+	}
+}
+
+// HistoryEntry records a player's rating after a game
+type HistoryEntry struct {
+	Id        int               `json:"-"`
+	UserId    users.Id          `sql:"index" json:"-"`
+	Category  game.TimeCategory `sql:"index"`
+	GameId    game.Id
+	Rating    float64
+	Deviation float64
+
+	CreatedAt time.Time
+}
+
+func (h HistoryEntry) TableName() string {
+	return fmt.Sprintf("%srating_history", tablePrefix)
+}
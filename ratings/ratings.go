@@ -0,0 +1,212 @@
+package ratings
+
+import (
+	"fmt"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/op/go-logging"
+
+	"foodtastechess/config"
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/logger"
+	"foodtastechess/users"
+)
+
+var tablePrefix string = ""
+
+// Ratings keeps a Glicko-2 rating for each player in each category of
+// game, updated as games end
+type Ratings interface {
+	events.EventSubscriber
+
+	Rating(userId users.Id, category game.TimeCategory) float64
+	Ratings(userId users.Id) map[game.TimeCategory]float64
+	History(userId users.Id, category game.TimeCategory) []HistoryEntry
+	Leaders(category game.TimeCategory, limit int) []PlayerRating
+}
+
+type RatingsService struct {
+	Config    config.DatabaseConfig `inject:"databaseConfig"`
+	Events    events.Events         `inject:"events"`
+	Publisher events.EventPublisher `inject:"eventSubscriber"`
+
+	log *logging.Logger
+	db  gorm.DB
+}
+
+func New() Ratings {
+	service := new(RatingsService)
+	service.log = logger.Log("ratings")
+	return service
+}
+
+func (s *RatingsService) PostPopulate() error {
+	// hook for test-suite, make a global table prefix if our config
+	// defines it
+	tablePrefix = s.Config.Prefix
+
+	dsn := fmt.Sprintf(
+		"%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True",
+		s.Config.Username, s.Config.Password,
+		s.Config.HostAddr, s.Config.Port,
+		s.Config.Database,
+	)
+
+	db, err := gorm.Open("mysql", dsn)
+
+	db.LogMode(true)
+	db.AutoMigrate(&PlayerRating{}, &HistoryEntry{})
+
+	s.db = db
+
+	// hear about games ending once queries have caught up with them
+	s.Publisher.Subscribe(s)
+
+	return err
+}
+
+// Rating is a player's rating in a category, or the default rating if
+// they haven't played in it
+func (s *RatingsService) Rating(userId users.Id, category game.TimeCategory) float64 {
+	return s.get(userId, category).Rating
+}
+
+// Ratings lists a player's ratings in the categories they have played
+func (s *RatingsService) Ratings(userId users.Id) map[game.TimeCategory]float64 {
+	var playerRatings []PlayerRating
+	s.db.Where(&PlayerRating{UserId: userId}).Find(&playerRatings)
+
+	ratings := make(map[game.TimeCategory]float64)
+	for _, rating := range playerRatings {
+		ratings[rating.Category] = rating.Rating
+	}
+
+	return ratings
+}
+
+// History lists a player's ratings after each of their rated games in
+// a category, oldest first
+func (s *RatingsService) History(userId users.Id, category game.TimeCategory) []HistoryEntry {
+	var history []HistoryEntry
+	s.db.
+		Where(&HistoryEntry{UserId: userId, Category: category}).
+		Order("id asc").
+		Find(&history)
+	return history
+}
+
+// Leaders lists the highest rated players in a category, best first
+func (s *RatingsService) Leaders(category game.TimeCategory, limit int) []PlayerRating {
+	var leaders []PlayerRating
+	s.db.
+		Where(&PlayerRating{Category: category}).
+		Order("rating desc, user_id asc").
+		Limit(limit).
+		Find(&leaders)
+	return leaders
+}
+
+func (s *RatingsService) Receive(event events.Event) error {
+	if event.Type != events.GameEndType {
+		return nil
+	}
+
+	if !Rated(event) {
+		return nil
+	}
+
+	category := game.TimeControl{}.Category()
+	gameCreates := s.Events.EventsOfTypeForGame(event.GameId, events.GameCreateType)
+	if len(gameCreates) > 0 {
+		category = gameCreates[0].TimeControl().Category()
+	}
+
+	whiteScore := WhiteScore(event.Winner)
+
+	white := s.get(event.WhiteId, category)
+	black := s.get(event.BlackId, category)
+
+	newWhite := white.glicko().Update([]Outcome{{Opponent: black.glicko(), Score: whiteScore}})
+	newBlack := black.glicko().Update([]Outcome{{Opponent: white.glicko(), Score: 1 - whiteScore}})
+
+	s.save(&white, newWhite, event.GameId)
+	s.save(&black, newBlack, event.GameId)
+
+	return nil
+}
+
+// Rated reports whether a game end counts towards ratings. Aborted games,
+// and games nobody joined, aren't rated.
+func Rated(gameEnd events.Event) bool {
+	if gameEnd.Reason == game.GameEndAborted {
+		return false
+	} else if gameEnd.WhiteId == "" || gameEnd.BlackId == "" {
+		return false
+	} else {
+		return true
+	}
+}
+
+// WhiteScore is what white scores from a game, black scoring the rest
+func WhiteScore(winner game.Color) float64 {
+	switch winner {
+	case game.White:
+		return 1
+	case game.Black:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+func (s *RatingsService) get(userId users.Id, category game.TimeCategory) PlayerRating {
+	rating := PlayerRating{}
+	s.db.Where(&PlayerRating{UserId: userId, Category: category}).First(&rating)
+
+	if rating.UserId != userId {
+		initial := NewGlicko()
+		rating = PlayerRating{
+			UserId:     userId,
+			Category:   category,
+			Rating:     initial.Rating,
+			Deviation:  initial.Deviation,
+			Volatility: initial.Volatility,
+		}
+	}
+
+	return rating
+}
+
+func (s *RatingsService) save(rating *PlayerRating, updated Glicko, gameId game.Id) {
+	rating.Rating = updated.Rating
+	rating.Deviation = updated.Deviation
+	rating.Volatility = updated.Volatility
+	rating.Games += 1
+
+	if s.db.NewRecord(*rating) {
+		s.db.Create(rating)
+	} else {
+		s.db.Save(rating)
+	}
+
+	s.db.Create(&HistoryEntry{
+		UserId:    rating.UserId,
+		Category:  rating.Category,
+		GameId:    gameId,
+		Rating:    rating.Rating,
+		Deviation: rating.Deviation,
+	})
+}
+
+func (s *RatingsService) ResetTestDB() {
+	if tablePrefix != "test_" {
+		s.log.Error(
+			"Cannot reset a database not configured with ConfigTestProvider",
+		)
+		return
+	}
+	s.db.DropTable(&PlayerRating{})
+	s.db.DropTable(&HistoryEntry{})
+	s.db.AutoMigrate(&PlayerRating{}, &HistoryEntry{})
+}
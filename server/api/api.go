@@ -1,24 +1,36 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/ant0ine/go-json-rest/rest"
+	"golang.org/x/net/websocket"
 	"math/rand"
 	"net/http"
 	"strconv"
+	"time"
 
+	"foodtastechess/broker"
 	"foodtastechess/commands"
+	"foodtastechess/conditionals"
+	"foodtastechess/events"
 	"foodtastechess/game"
 	"foodtastechess/logger"
+	"foodtastechess/matchmaking"
 	"foodtastechess/queries"
+	"foodtastechess/tournaments"
 	"foodtastechess/users"
 )
 
 var log = logger.Log("chessApi")
 
 type ChessApi struct {
-	Queries  queries.ClientQueries `inject:"clientQueries"`
-	Commands commands.Commands     `inject:"commands"`
+	Queries      queries.ClientQueries     `inject:"clientQueries"`
+	Commands     commands.Commands         `inject:"commands"`
+	Matchmaker   matchmaking.Matchmaker    `inject:"matchmaker"`
+	Tournaments  tournaments.Tournaments   `inject:"tournaments"`
+	Broker       broker.Broker             `inject:"broker"`
+	Conditionals conditionals.Conditionals `inject:"conditionals"`
 
 	restApi *rest.Api
 }
@@ -39,144 +51,1239 @@ func (api *ChessApi) PostPopulate() error {
 		&rest.ContentTypeCheckerMiddleware{},
 	)
 	restApi.Use(authMiddleware)
-	router, err := rest.MakeRouter(
+	router, err := rest.MakeRouter(api.routes()...)
+	if err != nil {
+		log.Error(fmt.Sprintf("Could not initialize Chess API: %v", err))
+		return err
+	}
+
+	restApi.SetApp(router)
+
+	api.restApi = restApi
+
+	return nil
+}
+
+// routes are everything the API serves, relative to Prefix. Each must be
+// described in the OpenAPI document. The router serves a request with
+// the first route defined that matches it, so fixed paths go before the
+// templates that would match them too.
+func (api *ChessApi) routes() []*rest.Route {
+	return []*rest.Route{
+		rest.Get("/openapi.json", api.GetOpenAPI),
 		rest.Get("/games", api.GetGames),
+		rest.Get("/lobby", api.GetLobby),
+		rest.Get("/leaderboard", api.GetLeaderboard),
+		rest.Get("/seeks", api.GetSeeks),
+		rest.Post("/seeks", api.PostSeek),
+		rest.Delete("/seeks", api.DeleteSeek),
+		rest.Get("/tournaments", api.GetTournaments),
+		rest.Get("/tournaments/:id", api.GetTournament),
+		rest.Get("/games/live", api.GetLiveGames),
 		rest.Get("/games/:id", api.GetGameInfo),
 		rest.Get("/games/:id/", api.GetGameInfo),
 		rest.Get("/games/:id/history", api.GetGameHistory),
 		rest.Get("/games/:id/validmoves", api.GetGameValidMoves),
+		rest.Get("/games/:id/chat", api.GetGameChat),
+		rest.Get("/games/:id/pgn", api.GetGamePGN),
+		rest.Get("/games/:id/conditionals", api.GetConditionals),
+		rest.Get("/games/:id/premove", api.GetPremove),
+		rest.Get("/games/:id/ws", api.GetGameSocket),
+		rest.Get("/games/:id/wait", api.GetGameWait),
+		rest.Get("/events/stream", api.GetEventStream),
+		rest.Get("/users/:id", api.GetUser),
+		rest.Get("/users/:id/ratings/:category", api.GetRatingHistory),
+		rest.Get("/users/:id/stats", api.GetPlayerStats),
+		rest.Get("/users/:id/headtohead/:opponent", api.GetHeadToHead),
+
+		rest.Post("/games/create", api.PostCreateGame),
+		rest.Post("/tournaments", api.PostCreateTournament),
+		rest.Post("/tournaments/:id/start", api.PostStartTournament),
+		rest.Post("/games/:id/join", api.PostJoinGame),
+		rest.Post("/invitations/:code/join", api.PostJoinInvitation),
+		rest.Post("/games/:id/move", api.PostMove),
+		rest.Post("/games/:id/offerdraw", api.PostDrawOffer),
+		rest.Post("/games/:id/respondoffer", api.PostDrawOfferResponse),
+		rest.Post("/games/:id/withdrawoffer", api.PostDrawOfferWithdraw),
+		rest.Post("/games/:id/concede", api.PostConcede),
+		rest.Post("/games/:id/abort", api.PostAbort),
+		rest.Post("/games/:id/claimtimeout", api.PostClaimTimeout),
+		rest.Post("/games/:id/berserk", api.PostBerserk),
+		rest.Post("/games/:id/chat", api.PostChat),
+		rest.Post("/games/:id/annotate", api.PostAnnotation),
+		rest.Post("/games/:id/conditionals", api.PostConditionals),
+		rest.Post("/games/:id/premove", api.PostPremove),
+		rest.Post("/games/:id/rematch", api.PostRematch),
+		rest.Post("/games/:id/respondrematch", api.PostRematchResponse),
+		rest.Post("/games/:id/requesttakeback", api.PostTakebackRequest),
+		rest.Post("/games/:id/respondtakeback", api.PostTakebackResponse),
+	}
+}
+
+// Prefix is where this version of the API is served. Changes that would
+// break clients get a new version.
+const Prefix = "/api/v1"
+
+func (api *ChessApi) Handler() http.Handler {
+	return http.StripPrefix(Prefix, api.restApi.MakeHandler())
+}
+
+func (api *ChessApi) GetGames(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+	res.WriteJson(api.Queries.UserGames(u.Uuid))
+}
+
+func (api *ChessApi) GetLobby(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	query := req.URL.Query()
+	filter := queries.LobbyFilter{
+		Category: game.TimeCategory(query.Get("category")),
+		Color:    game.Color(query.Get("color")),
+	}
+
+	res.WriteJson(api.Queries.Lobby(u.Uuid, filter))
+}
+
+func (api *ChessApi) GetLeaderboard(res rest.ResponseWriter, req *rest.Request) {
+	query := req.URL.Query()
+
+	category := game.TimeCategory(query.Get("category"))
+	if category == "" {
+		category = game.Blitz
+	}
+
+	valid := false
+	for _, c := range game.TimeCategories {
+		valid = valid || c == category
+	}
+	if !valid {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Unknown category")
+		return
+	}
+
+	limit, err := strconv.Atoi(query.Get("limit"))
+	if err != nil || limit <= 0 || limit > 100 {
+		limit = 10
+	}
+
+	res.WriteJson(api.Queries.Leaderboard(category, limit))
+}
+
+func (api *ChessApi) GetSeeks(res rest.ResponseWriter, req *rest.Request) {
+	res.WriteJson(api.Matchmaker.Seeks())
+}
+
+func (api *ChessApi) PostSeek(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	body := new(SeekBody)
+	err := req.DecodeJsonPayload(body)
+	if err != nil {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Seek must be a seek")
+		return
+	}
+
+	err = api.Matchmaker.Seek(matchmaking.Seek{
+		UserId:      user.Uuid,
+		TimeControl: body.TimeControl,
+		Variant:     body.Variant,
+		Color:       body.Color,
+		MinRating:   body.MinRating,
+		MaxRating:   body.MaxRating,
+	})
+
+	if err == nil {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, err)
+	}
+}
+
+func (api *ChessApi) DeleteSeek(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	api.Matchmaker.Cancel(user.Uuid)
+
+	res.WriteHeader(http.StatusAccepted)
+	res.WriteJson("ok")
+}
+
+func (api *ChessApi) GetGameInfo(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	id := req.PathParam("id")
+	intId, err := strconv.Atoi(id)
+	gameId := game.Id(intId)
+	if err != nil {
+		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	// private games are hidden from everyone but their players
+	gameInfo, found := api.Queries.GameInformation(gameId)
+	if !found || !gameInfo.VisibleTo(u.Uuid) {
+		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	res.WriteJson(api.gameInfoResponse(u, gameInfo))
+}
+
+// LiveGameResponse is a game being played and how many are watching it
+type LiveGameResponse struct {
+	queries.LiveGame
+	Spectators int
+}
+
+func (api *ChessApi) GetLiveGames(res rest.ResponseWriter, req *rest.Request) {
+	response := []LiveGameResponse{}
+
+	for _, liveGame := range api.Queries.LiveGames() {
+		response = append(response, LiveGameResponse{
+			LiveGame:   liveGame,
+			Spectators: api.Broker.Spectators(liveGame.GameId),
+		})
+	}
+
+	res.WriteJson(response)
+}
+
+// GameInfoResponse is a game as seen by a particular user
+type GameInfoResponse struct {
+	GameInfo              queries.GameInformation `json:",inline"`
+	UserColor             game.Color              `json:",omitempty"`
+	UserActive            bool
+	DrawOfferToUser       bool
+	TakebackRequestToUser bool
+	RematchOfferToUser    bool
+	ChallengeToUser       bool
+	Spectators            int
+}
+
+func (api *ChessApi) gameInfoResponse(u users.User, gameInfo queries.GameInformation) GameInfoResponse {
+	response := new(GameInfoResponse)
+	response.GameInfo = gameInfo
+	response.Spectators = api.Broker.Spectators(gameInfo.Id)
+
+	// only the players may hand out a private game's invite code
+	if u.Uuid != gameInfo.White.Uuid && u.Uuid != gameInfo.Black.Uuid {
+		response.GameInfo.InviteCode = ""
+	}
+
+	if gameInfo.GameStatus == queries.GameStatusCreated {
+		if gameInfo.Invitee == game.White && u.Uuid == gameInfo.White.Uuid {
+			response.ChallengeToUser = true
+		} else if gameInfo.Invitee == game.Black && u.Uuid == gameInfo.Black.Uuid {
+			response.ChallengeToUser = true
+		}
+	}
+
+	if u.Uuid == gameInfo.White.Uuid {
+		response.UserColor = game.White
+		response.UserActive = gameInfo.ActiveColor == game.White
+
+		if gameInfo.OutstandingDrawOffer && gameInfo.DrawOfferer == game.Black {
+			response.DrawOfferToUser = true
+		}
+
+		if gameInfo.OutstandingTakeback && gameInfo.TakebackRequester == game.Black {
+			response.TakebackRequestToUser = true
+		}
+
+		if gameInfo.RematchGameId != 0 && gameInfo.RematchOfferer == game.Black {
+			response.RematchOfferToUser = true
+		}
+	} else if u.Uuid == gameInfo.Black.Uuid {
+		response.UserColor = game.Black
+		response.UserActive = gameInfo.ActiveColor == game.Black
+
+		if gameInfo.OutstandingDrawOffer && gameInfo.DrawOfferer == game.White {
+			response.DrawOfferToUser = true
+		}
+
+		if gameInfo.OutstandingTakeback && gameInfo.TakebackRequester == game.White {
+			response.TakebackRequestToUser = true
+		}
+
+		if gameInfo.RematchGameId != 0 && gameInfo.RematchOfferer == game.White {
+			response.RematchOfferToUser = true
+		}
+	}
+
+	return *response
+}
+
+// GameMessage is sent over a game's socket: the game as it stands when
+// the socket opens, then the game after each of its events
+type GameMessage struct {
+	Type       string
+	TurnNumber game.TurnNumber      `json:",omitempty"`
+	Move       game.AlgebraicMove   `json:",omitempty"`
+	Chat       *queries.ChatMessage `json:",omitempty"`
+	Game       GameInfoResponse
+}
+
+const stateMessage = "state"
+
+func (api *ChessApi) GetGameSocket(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	gameInfo, found := api.Queries.GameInformation(gameId)
+	if !found || !gameInfo.VisibleTo(u.Uuid) {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	// players aren't counted among the game's spectators
+	var watcher *broker.Watcher
+	if u.Uuid == gameInfo.White.Uuid || u.Uuid == gameInfo.Black.Uuid {
+		watcher = api.Broker.Watch(gameId)
+	} else {
+		watcher = api.Broker.Spectate(gameId)
+	}
+	defer api.Broker.Unwatch(watcher)
+
+	// look the game up again now it is watched, so nothing is missed
+	// between
+	gameInfo, _ = api.Queries.GameInformation(gameId)
+
+	handler := func(ws *websocket.Conn) {
+		err := websocket.JSON.Send(ws, GameMessage{
+			Type: stateMessage,
+			Game: api.gameInfoResponse(u, gameInfo),
+		})
+		if err != nil {
+			return
+		}
+
+		// clients don't send anything, so reading only tells us when
+		// they have gone
+		closed := make(chan bool)
+		go func() {
+			var msg string
+			for websocket.Message.Receive(ws, &msg) == nil {
+			}
+			close(closed)
+		}()
+
+		for {
+			select {
+			case update, ok := <-watcher.Updates:
+				if !ok {
+					return
+				}
+
+				// players aren't shown what spectators say
+				if update.Event.Type == events.SpectatorChatType && !watcher.Spectator {
+					continue
+				}
+
+				err := websocket.JSON.Send(ws, GameMessage{
+					Type:       string(update.Event.Type),
+					TurnNumber: update.Event.TurnNumber,
+					Move:       update.Event.Move,
+					Chat:       api.chatMessage(update.Event),
+					Game:       api.gameInfoResponse(u, update.Game),
+				})
+				if err != nil {
+					return
+				}
+			case <-closed:
+				return
+			}
+		}
+	}
+
+	// the API is used across origins, so the origin isn't checked
+	server := websocket.Server{Handler: handler}
+	server.ServeHTTP(res.(http.ResponseWriter), req.Request)
+}
+
+// how long a wait for a move is held open before the game is returned
+// unchanged, for the client to wait again
+const waitTimeout = 60 * time.Second
+
+// GetGameWait blocks until a move is made after the afterTurn query
+// parameter, or the game ends, then returns the game as GetGameInfo
+// would. Clients wanting every move can wait on the turn number they
+// were last given.
+func (api *ChessApi) GetGameWait(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	afterTurn, err := strconv.Atoi(req.URL.Query().Get("afterTurn"))
+	if err != nil || afterTurn < 0 {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "afterTurn must be a turn number")
+		return
+	}
+
+	done := func(gameInfo queries.GameInformation) bool {
+		return gameInfo.TurnNumber > game.TurnNumber(afterTurn) ||
+			gameInfo.GameStatus == queries.GameStatusEnded
+	}
+
+	// watch before looking the game up, so nothing is missed between
+	watcher := api.Broker.Watch(gameId)
+	defer api.Broker.Unwatch(watcher)
+
+	gameInfo, found := api.Queries.GameInformation(gameId)
+	if !found || !gameInfo.VisibleTo(u.Uuid) {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	var closed <-chan bool
+	if notifier, ok := res.(http.CloseNotifier); ok {
+		closed = notifier.CloseNotify()
+	}
+
+	timeout := time.After(waitTimeout)
+
+	for !done(gameInfo) {
+		select {
+		case update, ok := <-watcher.Updates:
+			if !ok {
+				// dropped by the broker, so look the game up afresh
+				gameInfo, _ = api.Queries.GameInformation(gameId)
+				res.WriteJson(api.gameInfoResponse(u, gameInfo))
+				return
+			}
+			gameInfo = update.Game
+		case <-timeout:
+			res.WriteJson(api.gameInfoResponse(u, gameInfo))
+			return
+		case <-closed:
+			return
+		}
+	}
+
+	res.WriteJson(api.gameInfoResponse(u, gameInfo))
+}
+
+// StreamEvent is the data sent down the event stream for each event in
+// one of the user's games
+type StreamEvent struct {
+	GameId      game.Id
+	TurnNumber  game.TurnNumber      `json:",omitempty"`
+	Move        game.AlgebraicMove   `json:",omitempty"`
+	Offerer     game.Color           `json:",omitempty"`
+	OfferAccept bool                 `json:",omitempty"`
+	Winner      game.Color           `json:",omitempty"`
+	Reason      game.GameEndReason   `json:",omitempty"`
+	Chat        *queries.ChatMessage `json:",omitempty"`
+}
+
+// how often an idle event stream is sent a comment, so that proxies
+// don't close it
+const streamKeepAlive = 30 * time.Second
+
+// GetEventStream sends the events in all of the user's games as
+// server-sent events. A client reconnecting with a Last-Event-ID header
+// is first sent the events it missed.
+func (api *ChessApi) GetEventStream(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	w := res.(http.ResponseWriter)
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		refuse(res, http.StatusInternalServerError, commands.InternalError, "Streaming unsupported")
+		return
+	}
+
+	// watch before catching up, so nothing is missed between
+	watcher := api.Broker.WatchUser(u.Uuid)
+	defer api.Broker.Unwatch(watcher)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event events.Event) bool {
+		// the stream only carries the user's own games, whose
+		// spectators' chat they aren't shown
+		if event.Type == events.SpectatorChatType {
+			return true
+		}
+
+		data, _ := json.Marshal(StreamEvent{
+			GameId:      event.GameId,
+			TurnNumber:  event.TurnNumber,
+			Move:        event.Move,
+			Offerer:     event.Offerer,
+			OfferAccept: event.OfferAccept,
+			Winner:      event.Winner,
+			Reason:      event.Reason,
+			Chat:        api.chatMessage(event),
+		})
+		_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, data)
+		return err == nil
+	}
+
+	// the events sent while catching up, which the watcher may pass on
+	// again. Events aren't published in the order they are stored, so
+	// later ones are sent whatever their id.
+	caughtUp := make(map[int]bool)
+	if id, err := strconv.Atoi(req.Header.Get("Last-Event-ID")); err == nil {
+		for _, event := range api.Broker.Missed(u.Uuid, id) {
+			caughtUp[event.Id] = true
+			if !send(event) {
+				return
+			}
+		}
+	}
+	flusher.Flush()
+
+	var closed <-chan bool
+	if notifier, ok := w.(http.CloseNotifier); ok {
+		closed = notifier.CloseNotify()
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case update, ok := <-watcher.Updates:
+			if !ok {
+				return
+			}
+			if caughtUp[update.Event.Id] {
+				continue
+			}
+			if !send(update.Event) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// chatMessage reads the message out of a chat event, and is nil for
+// any other event
+func (api *ChessApi) chatMessage(event events.Event) *queries.ChatMessage {
+	if event.Type != events.ChatType && event.Type != events.SpectatorChatType {
+		return nil
+	}
+
+	message := queries.NewChatMessage(event)
+	author, found := api.Queries.User(event.AuthorId)
+	if found {
+		message.Author = author
+	}
+
+	return &message
+}
+
+func (api *ChessApi) GetUser(res rest.ResponseWriter, req *rest.Request) {
+	userId := users.Id(req.PathParam("id"))
+
+	user, found := api.Queries.User(userId)
+	if !found {
+		notFound(res, commands.UserNotFound, "User does not exist.")
+		return
+	}
+
+	res.WriteJson(UserResponse{Id: userId, User: user})
+}
+
+func (api *ChessApi) GetRatingHistory(res rest.ResponseWriter, req *rest.Request) {
+	userId := users.Id(req.PathParam("id"))
+	category := game.TimeCategory(req.PathParam("category"))
+
+	_, found := api.Queries.User(userId)
+	if !found {
+		notFound(res, commands.UserNotFound, "User does not exist.")
+		return
+	}
+
+	res.WriteJson(api.Queries.RatingHistory(userId, category))
+}
+
+func (api *ChessApi) GetPlayerStats(res rest.ResponseWriter, req *rest.Request) {
+	userId := users.Id(req.PathParam("id"))
+
+	stats, found := api.Queries.PlayerStats(userId)
+	if !found {
+		notFound(res, commands.UserNotFound, "User does not exist.")
+		return
+	}
+
+	res.WriteJson(stats)
+}
+
+// GetHeadToHead lists the games two players have started against each
+// other, leaving out those the user may not view
+func (api *ChessApi) GetHeadToHead(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+	userId := users.Id(req.PathParam("id"))
+	opponentId := users.Id(req.PathParam("opponent"))
+
+	for _, id := range []users.Id{userId, opponentId} {
+		if _, found := api.Queries.User(id); !found {
+			notFound(res, commands.UserNotFound, "User does not exist.")
+			return
+		}
+	}
+
+	record := api.Queries.HeadToHead(userId, opponentId)
+	if u.Uuid != userId && u.Uuid != opponentId {
+		visible := []queries.HeadToHeadGame{}
+		for _, g := range record.Games {
+			if api.visible(u, g.GameId) {
+				visible = append(visible, g)
+			}
+		}
+		record.Games = visible
+	}
+
+	res.WriteJson(record)
+}
+
+func (api *ChessApi) GetGameHistory(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	id := req.PathParam("id")
+	intId, err := strconv.Atoi(id)
+	gameId := game.Id(intId)
+	if err != nil {
+		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	if !api.visible(u, gameId) {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	history, found := api.Queries.GameHistory(gameId)
+	if !found {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	res.WriteJson(history)
+}
+
+func (api *ChessApi) GetGameValidMoves(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	id := req.PathParam("id")
+	intId, err := strconv.Atoi(id)
+	gameId := game.Id(intId)
+	if err != nil {
+		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	if !api.visible(u, gameId) {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	validMoves, found := api.Queries.ValidMoves(gameId)
+	if !found {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	res.WriteJson(validMoves)
+}
+
+// GetGameChat lists what has been said in a game. Spectators see both
+// channels, but players only their own.
+func (api *ChessApi) GetGameChat(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	gameInfo, found := api.Queries.GameInformation(gameId)
+	if !found || !gameInfo.VisibleTo(u.Uuid) {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	chat, _ := api.Queries.Chat(gameId)
+	player := u.Uuid == gameInfo.White.Uuid || u.Uuid == gameInfo.Black.Uuid
+
+	messages := []queries.ChatMessage{}
+	for _, message := range chat {
+		if player && message.Channel == queries.SpectatorChat {
+			continue
+		}
+		messages = append(messages, message)
+	}
+
+	res.WriteJson(messages)
+}
+
+// GetGamePGN exports a game, with its annotations, as a PGN file
+func (api *ChessApi) GetGamePGN(res rest.ResponseWriter, req *rest.Request) {
+	u := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	gameInfo, found := api.Queries.GameInformation(gameId)
+	if !found || !gameInfo.VisibleTo(u.Uuid) {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	history, _ := api.Queries.GameHistory(gameId)
+
+	name := func(user users.User) string {
+		if user.Name == "" {
+			return "?"
+		}
+		return user.Name
+	}
+
+	timeControl := "-"
+	if !gameInfo.TimeControl.Untimed() {
+		timeControl = fmt.Sprintf(
+			"%d+%d", gameInfo.TimeControl.Initial, gameInfo.TimeControl.Increment,
+		)
+	}
+
+	result := pgnResult(gameInfo)
+	tags := []game.PGNTag{
+		{Name: "Event", Value: "Casual game"},
+		{Name: "Site", Value: "foodtastechess"},
+		{Name: "Date", Value: "????.??.??"},
+		{Name: "Round", Value: "-"},
+		{Name: "White", Value: name(gameInfo.White)},
+		{Name: "Black", Value: name(gameInfo.Black)},
+		{Name: "Result", Value: result},
+		{Name: "TimeControl", Value: timeControl},
+	}
+
+	w := res.(http.ResponseWriter)
+	w.Header().Set("Content-Type", "application/x-chess-pgn")
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"game-%d.pgn\"", gameId),
+	)
+	game.WritePGN(w, tags, history, result)
+}
+
+// pgnResult is how PGN writes a game's result
+func pgnResult(gameInfo queries.GameInformation) string {
+	if gameInfo.GameStatus != queries.GameStatusEnded {
+		return "*"
+	}
+
+	switch {
+	case gameInfo.Winner == game.White:
+		return "1-0"
+	case gameInfo.Winner == game.Black:
+		return "0-1"
+	case gameInfo.GameEndReason == game.GameEndAborted:
+		return "*"
+	default:
+		return "1/2-1/2"
+	}
+}
+
+// visible reports whether a game exists and the user may view it
+func (api *ChessApi) visible(u users.User, gameId game.Id) bool {
+	gameInfo, found := api.Queries.GameInformation(gameId)
+	return found && gameInfo.VisibleTo(u.Uuid)
+}
+
+func (api *ChessApi) PostCreateGame(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	body := new(CreateGameBody)
+	err := req.DecodeJsonPayload(body)
+
+	if err != nil || body.Color == "" {
+		idx := rand.Perm(2)[0]
+		body.Color = []game.Color{game.White, game.Black}[idx]
+	}
+
+	// games joined by invite code are kept from spectators unless
+	// asked otherwise, and private games nobody is invited to are
+	// joined by invite code
+	if body.Visibility == "" && body.Private {
+		body.Visibility = game.Private
+	} else if body.Visibility == game.Private && body.Opponent == "" {
+		body.Private = true
+	}
+
+	params := map[string]interface{}{
+		"color":       body.Color,
+		"timeControl": body.TimeControl,
+		"visibility":  body.Visibility,
+	}
+
+	inviteCode := ""
+	if body.Opponent != "" {
+		params["opponent"] = body.Opponent
+	} else if body.Private {
+		inviteCode = commands.NewInviteCode()
+		params["inviteCode"] = inviteCode
+	}
+
+	ok, refusal := api.Commands.ExecCommand(commands.CreateGame, user.Uuid, params)
+
+	if ok && inviteCode != "" {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson(InvitationResponse{
+			InviteCode: inviteCode,
+			InviteLink: fmt.Sprintf("%s/invitations/%s/join", Prefix, inviteCode),
+		})
+	} else if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
+}
+
+func (api *ChessApi) PostJoinGame(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	body := new(JoinBody)
+	req.DecodeJsonPayload(body)
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.JoinGame, user.Uuid, map[string]interface{}{
+			"gameId":     gameId,
+			"inviteCode": body.InviteCode,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
+}
+
+func (api *ChessApi) PostJoinInvitation(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	inviteCode := req.PathParam("code")
+	gameId, found := api.Queries.InvitedGame(inviteCode)
+	if !found {
+		notFound(res, commands.NotFound, "Invitation does not exist.")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.JoinGame, user.Uuid, map[string]interface{}{
+			"gameId":     gameId,
+			"inviteCode": inviteCode,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson(JoinedResponse{GameId: gameId})
+	} else {
+		refuseError(res, refusal)
+	}
+}
+
+func (api *ChessApi) PostMove(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	body := new(MoveBody)
+	err = req.DecodeJsonPayload(body)
+	if err != nil || body.Move == "" {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Move must be a move")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.Move, user.Uuid, map[string]interface{}{
+			"move":   body.Move,
+			"gameId": gameId,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		// clients moving quickly can tell which of their moves failed
+		refuseError(res, refusal.WithDetail("move", body.Move))
+	}
+}
+
+func (api *ChessApi) PostDrawOffer(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.OfferDraw, user.Uuid, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
+}
+
+func (api *ChessApi) PostDrawOfferResponse(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	body := new(AcceptBody)
+	err = req.DecodeJsonPayload(body)
+	if err != nil {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Accept must be a boolean.")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.DrawOfferRespond, user.Uuid, map[string]interface{}{
+			"gameId": gameId,
+			"accept": body.Accept,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
+}
 
-		rest.Post("/games/create", api.PostCreateGame),
-		rest.Post("/games/:id/join", api.PostJoinGame),
-		rest.Post("/games/:id/move", api.PostMove),
-		rest.Post("/games/:id/offerdraw", api.PostDrawOffer),
-		rest.Post("/games/:id/respondoffer", api.PostDrawOfferResponse),
-		rest.Post("/games/:id/concede", api.PostConcede),
+func (api *ChessApi) PostDrawOfferWithdraw(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.WithdrawDrawOffer, user.Uuid, map[string]interface{}{
+			"gameId": gameId,
+		},
 	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
+}
+
+func (api *ChessApi) PostConcede(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
 	if err != nil {
-		log.Error(fmt.Sprintf("Could not initialize Chess API: %v", err))
-		return err
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
 	}
 
-	restApi.SetApp(router)
+	ok, refusal := api.Commands.ExecCommand(
+		commands.Concede, user.Uuid, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
 
-	api.restApi = restApi
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
+}
 
-	return nil
+func (api *ChessApi) PostClaimTimeout(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.ClaimTimeout, user.Uuid, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
 }
 
-func (api *ChessApi) Handler() http.Handler {
-	return http.StripPrefix("/api", api.restApi.MakeHandler())
+func (api *ChessApi) PostBerserk(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.Berserk, user.Uuid, map[string]interface{}{
+			"gameId": gameId,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
 }
 
-func (api *ChessApi) GetGames(res rest.ResponseWriter, req *rest.Request) {
-	u := getUser(req)
-	res.WriteJson(api.Queries.UserGames(u.Uuid))
+func (api *ChessApi) PostChat(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	body := new(ChatBody)
+	err = req.DecodeJsonPayload(body)
+	if err != nil {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Message must be a string")
+		return
+	}
+
+	ok, refusal := api.Commands.ExecCommand(
+		commands.Chat, user.Uuid, map[string]interface{}{
+			"gameId":  gameId,
+			"message": body.Message,
+		},
+	)
+
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
 }
 
-func (api *ChessApi) GetGameInfo(res rest.ResponseWriter, req *rest.Request) {
-	u := getUser(req)
+func (api *ChessApi) PostAnnotation(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
 
-	id := req.PathParam("id")
-	intId, err := strconv.Atoi(id)
+	intId, err := strconv.Atoi(req.PathParam("id"))
 	gameId := game.Id(intId)
 	if err != nil {
-		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
-		rest.NotFound(res, req)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
 		return
 	}
 
-	gameInfo, found := api.Queries.GameInformation(gameId)
-	if !found {
-		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
-		rest.NotFound(res, req)
+	body := new(AnnotationBody)
+	err = req.DecodeJsonPayload(body)
+	if err != nil {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Annotation must have a turn number")
 		return
 	}
 
-	type Response struct {
-		GameInfo        queries.GameInformation `json:",inline"`
-		UserColor       game.Color              `json:",omitempty"`
-		UserActive      bool
-		DrawOfferToUser bool
+	nag, ok := game.ParseNAG(body.NAG)
+	if !ok {
+		refuse(res, http.StatusBadRequest, commands.InvalidAnnotation, "Unknown annotation symbol.")
+		return
 	}
 
-	response := new(Response)
-	response.GameInfo = gameInfo
+	ok, refusal := api.Commands.ExecCommand(
+		commands.Annotate, user.Uuid, map[string]interface{}{
+			"gameId":     gameId,
+			"turnNumber": body.TurnNumber,
+			"comment":    body.Comment,
+			"nag":        nag,
+		},
+	)
 
-	if u.Uuid == gameInfo.White.Uuid {
-		response.UserColor = game.White
-		response.UserActive = gameInfo.ActiveColor == game.White
+	if ok {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson("ok")
+	} else {
+		refuseError(res, refusal)
+	}
+}
 
-		if gameInfo.OutstandingDrawOffer && gameInfo.DrawOfferer == game.Black {
-			response.DrawOfferToUser = true
-		}
-	} else if u.Uuid == gameInfo.Black.Uuid {
-		response.UserColor = game.Black
-		response.UserActive = gameInfo.ActiveColor == game.Black
+// GetConditionals lists the conditional moves the user has left in a game
+func (api *ChessApi) GetConditionals(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
 
-		if gameInfo.OutstandingDrawOffer && gameInfo.DrawOfferer == game.White {
-			response.DrawOfferToUser = true
-		}
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
 	}
 
-	res.WriteJson(response)
+	res.WriteJson(api.Conditionals.Conditions(gameId, user.Uuid))
 }
 
-func (api *ChessApi) GetGameHistory(res rest.ResponseWriter, req *rest.Request) {
-	id := req.PathParam("id")
-	intId, err := strconv.Atoi(id)
+// PostConditionals replaces the conditional moves the user has left in a
+// game. Posting none clears them.
+func (api *ChessApi) PostConditionals(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
 	gameId := game.Id(intId)
 	if err != nil {
-		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
-		rest.NotFound(res, req)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
 		return
 	}
 
-	history, found := api.Queries.GameHistory(gameId)
-	if !found {
-		rest.NotFound(res, req)
+	body := new(ConditionalsBody)
+	err = req.DecodeJsonPayload(body)
+	if err != nil {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Conditions must be a list of conditions")
 		return
 	}
 
-	res.WriteJson(history)
+	err = api.Conditionals.Submit(gameId, user.Uuid, body.Conditions)
+	if err != nil {
+		refuseError(res, err)
+		return
+	}
+
+	res.WriteHeader(http.StatusAccepted)
+	res.WriteJson("ok")
 }
 
-func (api *ChessApi) GetGameValidMoves(res rest.ResponseWriter, req *rest.Request) {
-	id := req.PathParam("id")
-	intId, err := strconv.Atoi(id)
+// GetPremove shows the premove the user has queued in a game, if any
+func (api *ChessApi) GetPremove(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
 	gameId := game.Id(intId)
 	if err != nil {
-		log.Debug("Recieved an invalid gameid, it was not an int: %s", id)
-		rest.NotFound(res, req)
-	}
-
-	validMoves, found := api.Queries.ValidMoves(gameId)
-	if !found {
-		rest.NotFound(res, req)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
 		return
 	}
 
-	res.WriteJson(validMoves)
+	move, _ := api.Conditionals.QueuedPremove(gameId, user.Uuid)
+	res.WriteJson(PremoveBody{Move: move})
 }
 
-func (api *ChessApi) PostCreateGame(res rest.ResponseWriter, req *rest.Request) {
+// PostPremove queues the move the user will make once their opponent has
+// moved. Posting no move clears it.
+func (api *ChessApi) PostPremove(res rest.ResponseWriter, req *rest.Request) {
 	user := getUser(req)
 
-	type createBody struct {
-		Color game.Color `json:"Color"`
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
+	}
+
+	body := new(PremoveBody)
+	err = req.DecodeJsonPayload(body)
+	if err != nil {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Move must be a move")
+		return
 	}
 
-	body := new(createBody)
-	err := req.DecodeJsonPayload(body)
+	err = api.Conditionals.Premove(gameId, user.Uuid, body.Move)
+	if err != nil {
+		refuseError(res, err)
+		return
+	}
 
-	if err != nil || body.Color == "" {
-		idx := rand.Perm(2)[0]
-		body.Color = []game.Color{game.White, game.Black}[idx]
+	res.WriteHeader(http.StatusAccepted)
+	res.WriteJson("ok")
+}
+
+func (api *ChessApi) PostAbort(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	intId, err := strconv.Atoi(req.PathParam("id"))
+	gameId := game.Id(intId)
+	if err != nil {
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
 	}
 
-	ok, msg := api.Commands.ExecCommand(
-		commands.CreateGame, user.Uuid, map[string]interface{}{
-			"color": body.Color,
+	ok, refusal := api.Commands.ExecCommand(
+		commands.Abort, user.Uuid, map[string]interface{}{
+			"gameId": gameId,
 		},
 	)
 
@@ -184,22 +1291,22 @@ func (api *ChessApi) PostCreateGame(res rest.ResponseWriter, req *rest.Request)
 		res.WriteHeader(http.StatusAccepted)
 		res.WriteJson("ok")
 	} else {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": msg})
+		refuseError(res, refusal)
 	}
 }
 
-func (api *ChessApi) PostJoinGame(res rest.ResponseWriter, req *rest.Request) {
+func (api *ChessApi) PostRematch(res rest.ResponseWriter, req *rest.Request) {
 	user := getUser(req)
 
 	intId, err := strconv.Atoi(req.PathParam("id"))
 	gameId := game.Id(intId)
 	if err != nil {
-		rest.NotFound(res, req)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
 	}
 
-	ok, msg := api.Commands.ExecCommand(
-		commands.JoinGame, user.Uuid, map[string]interface{}{
+	ok, refusal := api.Commands.ExecCommand(
+		commands.Rematch, user.Uuid, map[string]interface{}{
 			"gameId": gameId,
 		},
 	)
@@ -208,36 +1315,31 @@ func (api *ChessApi) PostJoinGame(res rest.ResponseWriter, req *rest.Request) {
 		res.WriteHeader(http.StatusAccepted)
 		res.WriteJson("ok")
 	} else {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": msg})
+		refuseError(res, refusal)
 	}
 }
 
-func (api *ChessApi) PostMove(res rest.ResponseWriter, req *rest.Request) {
+func (api *ChessApi) PostRematchResponse(res rest.ResponseWriter, req *rest.Request) {
 	user := getUser(req)
 
 	intId, err := strconv.Atoi(req.PathParam("id"))
 	gameId := game.Id(intId)
 	if err != nil {
-		rest.NotFound(res, req)
-	}
-
-	type moveBody struct {
-		Move game.AlgebraicMove `json:"Move"`
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
 	}
 
-	body := new(moveBody)
+	body := new(AcceptBody)
 	err = req.DecodeJsonPayload(body)
-	if err != nil || body.Move == "" {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": "Move must be a move"})
+	if err != nil {
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Accept must be a boolean.")
 		return
 	}
 
-	ok, msg := api.Commands.ExecCommand(
-		commands.Move, user.Uuid, map[string]interface{}{
-			"move":   body.Move,
+	ok, refusal := api.Commands.ExecCommand(
+		commands.RematchRespond, user.Uuid, map[string]interface{}{
 			"gameId": gameId,
+			"accept": body.Accept,
 		},
 	)
 
@@ -245,22 +1347,22 @@ func (api *ChessApi) PostMove(res rest.ResponseWriter, req *rest.Request) {
 		res.WriteHeader(http.StatusAccepted)
 		res.WriteJson("ok")
 	} else {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": msg})
+		refuseError(res, refusal)
 	}
 }
 
-func (api *ChessApi) PostDrawOffer(res rest.ResponseWriter, req *rest.Request) {
+func (api *ChessApi) PostTakebackRequest(res rest.ResponseWriter, req *rest.Request) {
 	user := getUser(req)
 
 	intId, err := strconv.Atoi(req.PathParam("id"))
 	gameId := game.Id(intId)
 	if err != nil {
-		rest.NotFound(res, req)
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
 	}
 
-	ok, msg := api.Commands.ExecCommand(
-		commands.OfferDraw, user.Uuid, map[string]interface{}{
+	ok, refusal := api.Commands.ExecCommand(
+		commands.RequestTakeback, user.Uuid, map[string]interface{}{
 			"gameId": gameId,
 		},
 	)
@@ -269,34 +1371,29 @@ func (api *ChessApi) PostDrawOffer(res rest.ResponseWriter, req *rest.Request) {
 		res.WriteHeader(http.StatusAccepted)
 		res.WriteJson("ok")
 	} else {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": msg})
+		refuseError(res, refusal)
 	}
 }
 
-func (api *ChessApi) PostDrawOfferResponse(res rest.ResponseWriter, req *rest.Request) {
+func (api *ChessApi) PostTakebackResponse(res rest.ResponseWriter, req *rest.Request) {
 	user := getUser(req)
 
 	intId, err := strconv.Atoi(req.PathParam("id"))
 	gameId := game.Id(intId)
 	if err != nil {
-		rest.NotFound(res, req)
-	}
-
-	type responseBody struct {
-		Accept bool `json:"Accept"`
+		notFound(res, commands.GameNotFound, "Game does not exist.")
+		return
 	}
 
-	body := new(responseBody)
+	body := new(AcceptBody)
 	err = req.DecodeJsonPayload(body)
 	if err != nil {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": "Accept must be a boolean."})
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Accept must be a boolean.")
 		return
 	}
 
-	ok, msg := api.Commands.ExecCommand(
-		commands.DrawOfferRespond, user.Uuid, map[string]interface{}{
+	ok, refusal := api.Commands.ExecCommand(
+		commands.TakebackRespond, user.Uuid, map[string]interface{}{
 			"gameId": gameId,
 			"accept": body.Accept,
 		},
@@ -306,32 +1403,80 @@ func (api *ChessApi) PostDrawOfferResponse(res rest.ResponseWriter, req *rest.Re
 		res.WriteHeader(http.StatusAccepted)
 		res.WriteJson("ok")
 	} else {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": msg})
+		refuseError(res, refusal)
 	}
 }
 
-func (api *ChessApi) PostConcede(res rest.ResponseWriter, req *rest.Request) {
+func (api *ChessApi) GetTournaments(res rest.ResponseWriter, req *rest.Request) {
+	res.WriteJson(api.Tournaments.Tournaments())
+}
+
+func (api *ChessApi) GetTournament(res rest.ResponseWriter, req *rest.Request) {
+	tournamentId, err := strconv.Atoi(req.PathParam("id"))
+	if err != nil {
+		notFound(res, commands.TournamentNotFound, "Tournament does not exist.")
+		return
+	}
+
+	tournament, found := api.Tournaments.Tournament(tournamentId)
+	if !found {
+		notFound(res, commands.TournamentNotFound, "Tournament does not exist.")
+		return
+	}
+
+	res.WriteJson(tournament)
+}
+
+func (api *ChessApi) PostCreateTournament(res rest.ResponseWriter, req *rest.Request) {
 	user := getUser(req)
 
-	intId, err := strconv.Atoi(req.PathParam("id"))
-	gameId := game.Id(intId)
+	body := new(CreateTournamentBody)
+	err := req.DecodeJsonPayload(body)
 	if err != nil {
-		rest.NotFound(res, req)
+		refuse(res, http.StatusBadRequest, commands.InvalidRequest, "Tournament must be a tournament")
+		return
 	}
 
-	ok, msg := api.Commands.ExecCommand(
-		commands.Concede, user.Uuid, map[string]interface{}{
-			"gameId": gameId,
-		},
-	)
+	if body.Format == "" && len(body.Teams) > 0 {
+		body.Format = tournaments.TeamMatch
+	} else if body.Format == "" {
+		body.Format = tournaments.RoundRobin
+	}
 
-	if ok {
+	tournament, err := api.Tournaments.Create(user.Uuid, tournaments.Spec{
+		Name:        body.Name,
+		Format:      body.Format,
+		Players:     body.Players,
+		Teams:       body.Teams,
+		TimeControl: body.TimeControl,
+		Rounds:      body.Rounds,
+		Minutes:     body.Minutes,
+	})
+
+	if err == nil {
+		res.WriteHeader(http.StatusAccepted)
+		res.WriteJson(tournament)
+	} else {
+		refuseError(res, err)
+	}
+}
+
+func (api *ChessApi) PostStartTournament(res rest.ResponseWriter, req *rest.Request) {
+	user := getUser(req)
+
+	tournamentId, err := strconv.Atoi(req.PathParam("id"))
+	if err != nil {
+		notFound(res, commands.TournamentNotFound, "Tournament does not exist.")
+		return
+	}
+
+	err = api.Tournaments.Begin(tournamentId, user.Uuid)
+
+	if err == nil {
 		res.WriteHeader(http.StatusAccepted)
 		res.WriteJson("ok")
 	} else {
-		res.WriteHeader(http.StatusBadRequest)
-		res.WriteJson(map[string]string{"error": msg})
+		refuseError(res, err)
 	}
 }
 
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"foodtastechess/commands"
+	"foodtastechess/conditionals"
+	"foodtastechess/game"
+	"foodtastechess/tournaments"
+	"foodtastechess/users"
+)
+
+// SeekBody is posted to look for an opponent
+type SeekBody struct {
+	TimeControl game.TimeControl `json:"TimeControl"`
+	Variant     string           `json:"Variant"`
+	Color       game.Color       `json:"Color"`
+	MinRating   float64          `json:"MinRating"`
+	MaxRating   float64          `json:"MaxRating"`
+}
+
+// CreateGameBody is posted to create a game
+type CreateGameBody struct {
+	Color       game.Color       `json:"Color"`
+	Opponent    users.Id         `json:"Opponent"`
+	Private     bool             `json:"Private"`
+	TimeControl game.TimeControl `json:"TimeControl"`
+	Visibility  game.Visibility  `json:"Visibility"`
+}
+
+// JoinBody is posted to join a game, with the invite code a private
+// game needs
+type JoinBody struct {
+	InviteCode string `json:"InviteCode"`
+}
+
+// MoveBody is posted to make a move
+type MoveBody struct {
+	Move game.AlgebraicMove `json:"Move"`
+}
+
+// AcceptBody is posted to answer a draw offer, rematch offer or takeback
+// request
+type AcceptBody struct {
+	Accept bool `json:"Accept"`
+}
+
+// ChatBody is posted to say something in a game
+type ChatBody struct {
+	Message string `json:"Message"`
+}
+
+// AnnotationBody is posted to annotate a move, with the NAG given by its
+// symbol
+type AnnotationBody struct {
+	TurnNumber game.TurnNumber `json:"TurnNumber"`
+	Comment    string          `json:"Comment"`
+	NAG        string          `json:"NAG"`
+}
+
+// ConditionalsBody is posted to replace a player's conditional moves
+type ConditionalsBody struct {
+	Conditions []conditionals.Condition `json:"Conditions"`
+}
+
+// CreateTournamentBody is posted to create a tournament
+type CreateTournamentBody struct {
+	Name        string             `json:"Name"`
+	Format      tournaments.Format `json:"Format"`
+	Players     []users.Id         `json:"Players"`
+	Teams       []tournaments.Team `json:"Teams"`
+	TimeControl game.TimeControl   `json:"TimeControl"`
+	Rounds      int                `json:"Rounds"`
+	Minutes     int                `json:"Minutes"`
+}
+
+// PremoveBody is a player's queued premove, posted to replace it
+type PremoveBody struct {
+	Move game.AlgebraicMove `json:"Move"`
+}
+
+// InvitationResponse is how others may join a private game just created
+type InvitationResponse struct {
+	InviteCode string
+	InviteLink string
+}
+
+// JoinedResponse is the game joined by an invite code
+type JoinedResponse struct {
+	GameId game.Id
+}
+
+// UserResponse is a user along with their id, which is otherwise kept
+// out of responses
+type UserResponse struct {
+	Id users.Id
+	users.User
+}
+
+// ErrorResponse is sent whenever a request isn't carried out. Clients
+// act on its code; the message is for people.
+type ErrorResponse struct {
+	Code    commands.Code          `json:"code"`
+	Message string                 `json:"message"`
+	Details map[string]interface{} `json:"details,omitempty"`
+}
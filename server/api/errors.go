@@ -0,0 +1,47 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"net/http"
+
+	"foodtastechess/commands"
+)
+
+// refuse sends an error body, so every refusal looks the same to clients
+func refuse(res rest.ResponseWriter, status int, code commands.Code, message string) {
+	res.WriteHeader(status)
+	res.WriteJson(ErrorResponse{Code: code, Message: message})
+}
+
+// refuseError sends why a service refused a request. Errors without a
+// code of their own are taken to be the request's fault.
+func refuseError(res rest.ResponseWriter, err error) {
+	refusal, ok := err.(commands.Refusal)
+	if !ok {
+		refusal = commands.Refuse(commands.InvalidRequest, err.Error())
+	}
+
+	res.WriteHeader(statusOf(refusal.Code))
+	res.WriteJson(ErrorResponse{
+		Code:    refusal.Code,
+		Message: refusal.Message,
+		Details: refusal.Details,
+	})
+}
+
+func notFound(res rest.ResponseWriter, code commands.Code, message string) {
+	refuse(res, http.StatusNotFound, code, message)
+}
+
+// statusOf is the HTTP status a refusal is sent with
+func statusOf(code commands.Code) int {
+	switch code {
+	case commands.NotFound, commands.GameNotFound,
+		commands.UserNotFound, commands.TournamentNotFound:
+		return http.StatusNotFound
+	case commands.InternalError:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
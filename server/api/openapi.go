@@ -0,0 +1,631 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"foodtastechess/conditionals"
+	"foodtastechess/game"
+	"foodtastechess/matchmaking"
+	"foodtastechess/queries"
+	"foodtastechess/ratings"
+	"foodtastechess/tournaments"
+)
+
+// OpenAPI is an OpenAPI 3 document, holding as much of the format as
+// is needed to describe this API
+type OpenAPI struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	Url string `json:"url"`
+}
+
+// PathItem holds a path's operations by lower case method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationId string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+const (
+	jsonType = "application/json"
+	okBody   = "ok"
+)
+
+// oneOf is a response that may take any of several forms
+type oneOf []interface{}
+
+// operation describes a route. Bodies and responses are given as values
+// of the types sent, from which their schemas are made.
+type operation struct {
+	summary string
+	query   []Parameter
+
+	body     interface{}
+	response interface{}
+
+	// the response's media type, when it isn't JSON
+	content string
+
+	// whether the request may be refused with an error body
+	refusable bool
+}
+
+func queryParam(name, description string) Parameter {
+	return Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &Schema{Type: "string"},
+	}
+}
+
+// operations describe each route, keyed by method and path as they are
+// routed
+var operations = map[string]operation{
+	"GET /openapi.json": {
+		summary:  "This document.",
+		response: map[string]interface{}{},
+	},
+	"GET /games": {
+		summary:  "The ids of the user's games.",
+		response: []game.Id{},
+	},
+	"GET /lobby": {
+		summary: "Open games the user may join.",
+		query: []Parameter{
+			queryParam("category", "Only games in this time category."),
+			queryParam("color", "Only games where the user would play this color."),
+		},
+		response: []queries.LobbyEntry{},
+	},
+	"GET /leaderboard": {
+		summary: "The best rated players in a time category.",
+		query: []Parameter{
+			queryParam("category", "The time category, blitz if not given."),
+			queryParam("limit", "How many players to list."),
+		},
+		response:  queries.LeaderboardInformation{},
+		refusable: true,
+	},
+	"GET /seeks": {
+		summary:  "Everyone looking for an opponent.",
+		response: []matchmaking.Seek{},
+	},
+	"POST /seeks": {
+		summary:   "Look for an opponent, replacing any seek the user has.",
+		body:      SeekBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"DELETE /seeks": {
+		summary:  "Stop looking for an opponent.",
+		response: okBody,
+	},
+	"GET /tournaments": {
+		summary:  "All tournaments.",
+		response: []tournaments.Tournament{},
+	},
+	"GET /tournaments/:id": {
+		summary:  "A tournament and its standings.",
+		response: tournaments.Tournament{},
+	},
+	"POST /tournaments": {
+		summary:   "Create a tournament, organized by the user.",
+		body:      CreateTournamentBody{},
+		response:  tournaments.Tournament{},
+		refusable: true,
+	},
+	"POST /tournaments/:id/start": {
+		summary:   "Start a tournament the user organizes.",
+		response:  okBody,
+		refusable: true,
+	},
+	"GET /games/live": {
+		summary:  "Public games being played, with how many are watching.",
+		response: []LiveGameResponse{},
+	},
+	"GET /games/:id": {
+		summary:  "A game as the user sees it.",
+		response: GameInfoResponse{},
+	},
+	"GET /games/:id/": {
+		summary:  "The same as /games/{id}.",
+		response: GameInfoResponse{},
+	},
+	"GET /games/:id/history": {
+		summary:  "Every move of a game, starting from the initial board.",
+		response: []game.MoveRecord{},
+	},
+	"GET /games/:id/validmoves": {
+		summary:  "The moves that may be made in a game.",
+		response: []game.MoveRecord{},
+	},
+	"GET /games/:id/chat": {
+		summary:  "What has been said in a game. Players only see their own channel.",
+		response: []queries.ChatMessage{},
+	},
+	"GET /games/:id/pgn": {
+		summary:  "A game and its annotations as a PGN file.",
+		response: "",
+		content:  "application/x-chess-pgn",
+	},
+	"GET /games/:id/conditionals": {
+		summary:  "The conditional moves the user has left in a game.",
+		response: []conditionals.Condition{},
+	},
+	"GET /games/:id/premove": {
+		summary:  "The premove the user has queued in a game, empty if none.",
+		response: PremoveBody{},
+	},
+	"GET /games/:id/ws": {
+		summary: "A websocket sending the game, then a GameMessage after each of its events.",
+	},
+	"GET /games/:id/wait": {
+		summary: "Wait up to a minute for a move after a turn, or the end of the game.",
+		query: []Parameter{{
+			Name:        "afterTurn",
+			In:          "query",
+			Description: "Wait for the game to pass this turn.",
+			Required:    true,
+			Schema:      &Schema{Type: "integer"},
+		}},
+		response:  GameInfoResponse{},
+		refusable: true,
+	},
+	"GET /events/stream": {
+		summary:  "Server-sent events from all of the user's games. Send Last-Event-ID to catch up.",
+		response: StreamEvent{},
+		content:  "text/event-stream",
+	},
+	"GET /users/:id": {
+		summary:  "A user.",
+		response: UserResponse{},
+	},
+	"GET /users/:id/ratings/:category": {
+		summary:  "A user's rating after each of their games in a time category.",
+		response: []ratings.HistoryEntry{},
+	},
+	"GET /users/:id/stats": {
+		summary:  "A user's results.",
+		response: queries.PlayerStats{},
+	},
+	"GET /users/:id/headtohead/:opponent": {
+		summary:  "The record between two users, with the games they have played each other.",
+		response: queries.HeadToHead{},
+	},
+	"POST /games/create": {
+		summary:   "Create a game. Private games without an opponent are joined by invitation.",
+		body:      CreateGameBody{},
+		response:  oneOf{okBody, InvitationResponse{}},
+		refusable: true,
+	},
+	"POST /games/:id/join": {
+		summary:   "Join a game.",
+		body:      JoinBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /invitations/:code/join": {
+		summary:   "Join the game an invitation is for.",
+		response:  JoinedResponse{},
+		refusable: true,
+	},
+	"POST /games/:id/move": {
+		summary:   "Make a move.",
+		body:      MoveBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/offerdraw": {
+		summary:   "Offer a draw.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/respondoffer": {
+		summary:   "Accept or decline a draw offer.",
+		body:      AcceptBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/withdrawoffer": {
+		summary:   "Withdraw a draw offer.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/concede": {
+		summary:   "Resign a game.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/abort": {
+		summary:   "Abort a game before it has properly begun.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/claimtimeout": {
+		summary:   "Claim a win once the opponent's time has run out.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/berserk": {
+		summary:   "Give up half the clock in a tournament game for an extra point.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/chat": {
+		summary:   "Say something in a game.",
+		body:      ChatBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/annotate": {
+		summary:   "Comment on a move of a finished game, with an annotation symbol such as !? or ??.",
+		body:      AnnotationBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/conditionals": {
+		summary:   "Replace the user's conditional moves in a correspondence game.",
+		body:      ConditionalsBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/premove": {
+		summary:   "Queue a move to make once the opponent has moved. An empty move clears it.",
+		body:      PremoveBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/rematch": {
+		summary:   "Offer a rematch of a finished game.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/respondrematch": {
+		summary:   "Accept or decline a rematch.",
+		body:      AcceptBody{},
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/requesttakeback": {
+		summary:   "Ask to take back the last move.",
+		response:  okBody,
+		refusable: true,
+	},
+	"POST /games/:id/respondtakeback": {
+		summary:   "Accept or decline a takeback.",
+		body:      AcceptBody{},
+		response:  okBody,
+		refusable: true,
+	},
+}
+
+// enums are the values string types may take
+var enums = map[reflect.Type][]interface{}{
+	reflect.TypeOf(game.White):                {game.White, game.Black},
+	reflect.TypeOf(game.Public):               {game.Public, game.Private},
+	reflect.TypeOf(game.Blitz):                {game.Bullet, game.Blitz, game.Rapid, game.Classical, game.Correspondence},
+	reflect.TypeOf(game.GameEndConcede):       {game.GameEndConcede, game.GameEndDraw, game.GameEndCheckmate, game.GameEndAborted, game.GameEndTimeout},
+	reflect.TypeOf(queries.GameStatusStarted): {queries.GameStatusCreated, queries.GameStatusStarted, queries.GameStatusEnded},
+	reflect.TypeOf(queries.PlayerChat):        {queries.PlayerChat, queries.SpectatorChat},
+	reflect.TypeOf(tournaments.RoundRobin):    {tournaments.RoundRobin, tournaments.Swiss, tournaments.Arena, tournaments.TeamMatch},
+}
+
+var pathParam = regexp.MustCompile(`:([a-zA-Z]+)`)
+
+// Spec describes the API as an OpenAPI document
+func Spec() OpenAPI {
+	spec := OpenAPI{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "foodtastechess",
+			Description: "Every route needs a logged in session, begun at /auth/login, except this document.",
+			Version:     "1.0.0",
+		},
+		Servers:    []Server{{Url: Prefix}},
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+	}
+
+	named := map[string]bool{}
+
+	for _, route := range new(ChessApi).routes() {
+		op, ok := operations[route.HttpMethod+" "+route.PathExp]
+		if !ok {
+			continue
+		}
+
+		path := pathParam.ReplaceAllString(route.PathExp, "{$1}")
+		item, ok := spec.Paths[path]
+		if !ok {
+			item = PathItem{}
+			spec.Paths[path] = item
+		}
+
+		operation := &Operation{
+			Summary:    op.summary,
+			Parameters: []Parameter{},
+			Responses:  map[string]Response{},
+		}
+
+		// a handler served at several paths is only named once
+		name := handlerName(route.Func)
+		if !named[name] {
+			operation.OperationId = name
+			named[name] = true
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(route.PathExp, -1) {
+			schema := &Schema{Type: "string"}
+			if match[1] == "id" && !strings.HasPrefix(route.PathExp, "/users/") {
+				schema = &Schema{Type: "integer"}
+			}
+			operation.Parameters = append(operation.Parameters, Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   schema,
+			})
+		}
+		operation.Parameters = append(operation.Parameters, op.query...)
+
+		if op.body != nil {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					jsonType: {Schema: spec.schema(reflect.TypeOf(op.body))},
+				},
+			}
+		}
+
+		status := "200"
+		if route.HttpMethod != "GET" {
+			status = "202"
+		}
+
+		switch {
+		case op.response == nil:
+			operation.Responses["101"] = Response{Description: "Switching to a websocket."}
+		case op.content != "":
+			operation.Responses[status] = Response{
+				Description: "OK",
+				Content: map[string]MediaType{
+					op.content: {Schema: spec.schema(reflect.TypeOf(op.response))},
+				},
+			}
+		default:
+			operation.Responses[status] = Response{
+				Description: "OK",
+				Content: map[string]MediaType{
+					jsonType: {Schema: spec.responseSchema(op.response)},
+				},
+			}
+		}
+
+		refusal := map[string]MediaType{
+			jsonType: {Schema: spec.schema(reflect.TypeOf(ErrorResponse{}))},
+		}
+
+		if op.refusable {
+			operation.Responses["400"] = Response{
+				Description: "The request could not be carried out.",
+				Content:     refusal,
+			}
+		}
+
+		if strings.Contains(route.PathExp, ":") {
+			operation.Responses["404"] = Response{
+				Description: "Not found, or not visible to the user.",
+				Content:     refusal,
+			}
+		}
+
+		item[strings.ToLower(route.HttpMethod)] = operation
+	}
+
+	return spec
+}
+
+func (spec OpenAPI) responseSchema(response interface{}) *Schema {
+	forms, ok := response.(oneOf)
+	if !ok {
+		return spec.schema(reflect.TypeOf(response))
+	}
+
+	schema := &Schema{}
+	for _, form := range forms {
+		schema.OneOf = append(schema.OneOf, spec.schema(reflect.TypeOf(form)))
+	}
+	return schema
+}
+
+// schema describes a type as it is encoded to JSON. Named structs are
+// added to the document's components and referred to.
+func (spec OpenAPI) schema(t reflect.Type) *Schema {
+	if values, ok := enums[t]; ok {
+		return &Schema{Type: "string", Enum: values}
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return &Schema{Type: "integer", Format: "int64", Description: "Nanoseconds."}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := spec.schema(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: spec.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: spec.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return spec.object(t)
+		}
+		if _, ok := spec.Components.Schemas[t.Name()]; !ok {
+			// claimed before its fields are walked, for types that
+			// hold themselves
+			spec.Components.Schemas[t.Name()] = &Schema{}
+			*spec.Components.Schemas[t.Name()] = *spec.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+// object lists a struct's fields as encoding/json would
+func (spec OpenAPI) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := field.Name
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		} else if tag[0] != "" {
+			name = tag[0]
+		}
+
+		if field.Anonymous && tag[0] == "" && field.Type.Kind() == reflect.Struct {
+			for name, property := range spec.object(field.Type).Properties {
+				schema.Properties[name] = property
+			}
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		schema.Properties[name] = spec.schema(field.Type)
+	}
+
+	return schema
+}
+
+// handlerName is the name of the ChessApi method a route is served by
+func handlerName(handler rest.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// Route finds the route a request would be served by, taking the first
+// defined that matches as the router does. It gives the route's path as
+// this document names it.
+func Route(method, path string) (string, bool) {
+	segments := strings.Split(path, "/")
+
+	for _, route := range new(ChessApi).routes() {
+		if route.HttpMethod != strings.ToUpper(method) {
+			continue
+		}
+
+		routeSegments := strings.Split(route.PathExp, "/")
+		if len(routeSegments) != len(segments) {
+			continue
+		}
+
+		matches := true
+		for i, segment := range routeSegments {
+			if strings.HasPrefix(segment, ":") {
+				matches = matches && segments[i] != ""
+			} else {
+				matches = matches && segments[i] == segment
+			}
+		}
+
+		if matches {
+			return pathParam.ReplaceAllString(route.PathExp, "{$1}"), true
+		}
+	}
+
+	return "", false
+}
+
+// GetOpenAPI serves the API's OpenAPI document. It is open to those who
+// haven't logged in.
+func (api *ChessApi) GetOpenAPI(res rest.ResponseWriter, req *rest.Request) {
+	res.WriteJson(Spec())
+}
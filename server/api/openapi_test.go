@@ -0,0 +1,153 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"strings"
+	"testing"
+)
+
+type OpenAPITestSuite struct {
+	suite.Suite
+}
+
+func TestOpenAPITestSuite(t *testing.T) {
+	suite.Run(t, new(OpenAPITestSuite))
+}
+
+func (s *OpenAPITestSuite) TestEveryRouteDescribed() {
+	assert := assert.New(s.T())
+
+	routed := map[string]bool{}
+	for _, route := range new(ChessApi).routes() {
+		key := route.HttpMethod + " " + route.PathExp
+		routed[key] = true
+
+		_, ok := operations[key]
+		assert.True(ok, "%s is not described", key)
+	}
+
+	for key := range operations {
+		assert.True(routed[key], "%s is described but not routed", key)
+	}
+}
+
+// TestEveryRouteReachable checks that no route is hidden by one defined
+// before it
+func (s *OpenAPITestSuite) TestEveryRouteReachable() {
+	assert := assert.New(s.T())
+
+	for _, route := range new(ChessApi).routes() {
+		path := pathParam.ReplaceAllString(route.PathExp, "1")
+		served, ok := Route(route.HttpMethod, path)
+		assert.True(ok, "%s %s is not routed", route.HttpMethod, path)
+		assert.Equal(
+			pathParam.ReplaceAllString(route.PathExp, "{$1}"), served,
+			"%s %s is served by the wrong route", route.HttpMethod, path,
+		)
+	}
+
+	served, _ := Route("GET", "/games/live")
+	assert.Equal("/games/live", served)
+
+	served, _ = Route("GET", "/games/12")
+	assert.Equal("/games/{id}", served)
+
+	_, ok := Route("DELETE", "/games/12")
+	assert.False(ok)
+}
+
+func (s *OpenAPITestSuite) TestSpec() {
+	assert := assert.New(s.T())
+
+	spec := Spec()
+	assert.Equal("3.0.3", spec.OpenAPI)
+
+	move := spec.Paths["/games/{id}/move"]["post"]
+	assert.Equal("PostMove", move.OperationId)
+	assert.Equal("id", move.Parameters[0].Name)
+	assert.Equal("integer", move.Parameters[0].Schema.Type)
+	assert.Equal(
+		"#/components/schemas/MoveBody",
+		move.RequestBody.Content[jsonType].Schema.Ref,
+	)
+	assert.Equal(
+		"#/components/schemas/ErrorResponse",
+		move.Responses["400"].Content[jsonType].Schema.Ref,
+	)
+
+	// the duplicate route isn't named twice
+	assert.Equal("GetGameInfo", spec.Paths["/games/{id}"]["get"].OperationId)
+	assert.Equal("", spec.Paths["/games/{id}/"]["get"].OperationId)
+
+	user := spec.Paths["/users/{id}/ratings/{category}"]["get"]
+	assert.Equal("string", user.Parameters[0].Schema.Type)
+	assert.Equal("category", user.Parameters[1].Name)
+
+	gameInfo := spec.Components.Schemas["GameInformation"]
+	assert.Equal("object", gameInfo.Type)
+	assert.Equal("#/components/schemas/User", gameInfo.Properties["White"].Ref)
+	assert.Equal(
+		[]interface{}{"white", "black"},
+		toJSON(gameInfo.Properties["ActiveColor"].Enum),
+	)
+	assert.True(gameInfo.Properties["Clock"].Ref != "")
+
+	moveRecord := spec.Components.Schemas["MoveRecord"]
+	assert.Equal("string", moveRecord.Properties["Move"].Type)
+	assert.Equal("integer", moveRecord.Properties["NAG"].Type)
+
+	// hidden fields are left out, embedded ones lifted up
+	user2 := spec.Components.Schemas["UserResponse"]
+	_, hasUuid := user2.Properties["Uuid"]
+	assert.False(hasUuid)
+	assert.Equal("string", user2.Properties["Name"].Type)
+	assert.Equal("string", user2.Properties["CreatedAt"].Type)
+	assert.Equal("date-time", user2.Properties["CreatedAt"].Format)
+
+	refusal := spec.Components.Schemas["ErrorResponse"]
+	assert.Equal("string", refusal.Properties["code"].Type)
+	assert.Equal("string", refusal.Properties["message"].Type)
+	assert.Equal("object", refusal.Properties["details"].Type)
+	assert.Equal("/api/v1", spec.Servers[0].Url)
+}
+
+func (s *OpenAPITestSuite) TestRefsResolve() {
+	assert := assert.New(s.T())
+
+	spec := Spec()
+	encoded, err := json.Marshal(spec)
+	assert.Nil(err)
+
+	var document interface{}
+	assert.Nil(json.Unmarshal(encoded, &document))
+
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch node := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := node["$ref"].(string); ok {
+				name := strings.TrimPrefix(ref, "#/components/schemas/")
+				_, found := spec.Components.Schemas[name]
+				assert.True(found, "%s does not resolve", ref)
+			}
+			for _, child := range node {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range node {
+				walk(child)
+			}
+		}
+	}
+	walk(document)
+}
+
+// toJSON gives values as they would be decoded from JSON
+func toJSON(values interface{}) interface{} {
+	encoded, _ := json.Marshal(values)
+	var decoded interface{}
+	json.Unmarshal(encoded, &decoded)
+	return decoded
+}
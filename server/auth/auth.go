@@ -78,6 +78,9 @@ func (s *authService) LoginRequired(res http.ResponseWriter, req *http.Request,
 		s.completeAuth(res, req, session)
 	case "/auth/me":
 		s.authInfo(res, req, session)
+	case "/api/v1/openapi.json":
+		// integrators may read the API's description without logging in
+		next(res, req)
 	default:
 		u, valid := s.validCredentials(session)
 		if !valid {
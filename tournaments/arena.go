@@ -0,0 +1,169 @@
+package tournaments
+
+import (
+	"sort"
+
+	"foodtastechess/users"
+)
+
+const (
+	arenaWin  = 2
+	arenaDraw = 1
+
+	// wins in a row it takes to double the points for the next games
+	arenaStreak = 2
+)
+
+// arenaStandings ranks arena players by points. A win scores 2 and a draw
+// 1, doubled once a player has won twice running, and winning a game
+// after going berserk earns a point more.
+func arenaStandings(players []Participant, results []Result) []Standing {
+	byUser := make(map[users.Id]*Standing)
+	list := []*Standing{}
+	for _, player := range players {
+		standing := &Standing{UserId: player.UserId, Seed: player.Seed}
+		byUser[player.UserId] = standing
+		list = append(list, standing)
+	}
+
+	// streaks run in the order games finished. Aborted games don't
+	// count, since both players are soon paired again.
+	finished := []Result{}
+	for _, result := range results {
+		if result.Finished && !result.bye() && !result.Aborted {
+			finished = append(finished, result)
+		}
+	}
+	sort.Stable(byEnd(finished))
+
+	for _, result := range finished {
+		if white, ok := byUser[result.WhiteId]; ok {
+			white.score(result.WhiteScore, result.WhiteBerserk)
+		}
+
+		if black, ok := byUser[result.BlackId]; ok {
+			black.score(result.BlackScore, result.BlackBerserk)
+		}
+	}
+
+	sort.Sort(byRank(list))
+
+	ranked := []Standing{}
+	for i, standing := range list {
+		standing.Rank = i + 1
+		ranked = append(ranked, *standing)
+	}
+
+	return ranked
+}
+
+// score adds the arena points for a game to a player's standing
+func (s *Standing) score(score float64, berserk bool) {
+	points := 0.0
+	switch score {
+	case 1:
+		points = arenaWin
+	case 0.5:
+		points = arenaDraw
+	}
+
+	if s.Streak >= arenaStreak {
+		points *= 2
+	}
+
+	if score == 1 {
+		if berserk {
+			points += 1
+		}
+		s.Streak += 1
+	} else {
+		s.Streak = 0
+	}
+
+	s.Score += points
+	s.Played += 1
+}
+
+// arenaPlayer is what arena pairing needs to know about a player waiting
+// for a game
+type arenaPlayer struct {
+	index        int
+	userId       users.Id
+	lastOpponent users.Id
+	colors       int
+}
+
+// arenaRound pairs the waiting players of an arena, best ranked first,
+// each with the next best ranked player they didn't just play. Pairs are
+// indexes into players.
+func arenaRound(players []Participant, results []Result, waiting map[users.Id]bool) []pair {
+	history := make(map[users.Id]*arenaPlayer)
+	for i, player := range players {
+		history[player.UserId] = &arenaPlayer{index: i, userId: player.UserId}
+	}
+
+	for _, result := range results {
+		if result.bye() {
+			continue
+		}
+
+		if white, ok := history[result.WhiteId]; ok {
+			white.lastOpponent = result.BlackId
+			white.colors += 1
+		}
+
+		if black, ok := history[result.BlackId]; ok {
+			black.lastOpponent = result.WhiteId
+			black.colors -= 1
+		}
+	}
+
+	ranked := []*arenaPlayer{}
+	for _, standing := range arenaStandings(players, results) {
+		if waiting[standing.UserId] {
+			ranked = append(ranked, history[standing.UserId])
+		}
+	}
+
+	// with only two players in the arena they can only play each other
+	rematches := len(players) == 2
+
+	paired := make(map[users.Id]bool)
+	pairs := []pair{}
+	for i, player := range ranked {
+		if paired[player.userId] {
+			continue
+		}
+
+		for _, opponent := range ranked[i+1:] {
+			if paired[opponent.userId] {
+				continue
+			}
+
+			if !rematches && player.lastOpponent == opponent.userId {
+				continue
+			}
+
+			paired[player.userId] = true
+			paired[opponent.userId] = true
+
+			// whoever has had white less often takes it
+			if opponent.colors < player.colors {
+				pairs = append(pairs, pair{opponent.index, player.index})
+			} else {
+				pairs = append(pairs, pair{player.index, opponent.index})
+			}
+			break
+		}
+	}
+
+	return pairs
+}
+
+type byEnd []Result
+
+func (s byEnd) Len() int      { return len(s) }
+func (s byEnd) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byEnd) Less(i, j int) bool {
+	return s[i].EndedAt.Before(s[j].EndedAt)
+}
@@ -0,0 +1,133 @@
+package tournaments
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+
+	"foodtastechess/users"
+)
+
+type ArenaTestSuite struct {
+	suite.Suite
+}
+
+func TestArenaTestSuite(t *testing.T) {
+	suite.Run(t, new(ArenaTestSuite))
+}
+
+// arenaGames gives results finishing a minute apart, in order
+func arenaGames(results ...Result) []Result {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := range results {
+		results[i].EndedAt = start.Add(time.Duration(i) * time.Minute)
+	}
+	return results
+}
+
+func (s *ArenaTestSuite) TestStreaks() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b", "c")
+	results := arenaGames(
+		finished("a", "b", 1),
+		finished("c", "a", 0),
+		// a is on fire from here, so the draw is worth double
+		finished("a", "b", 0.5),
+		finished("b", "a", 0),
+	)
+
+	standings := standings(Arena, players, results)
+
+	assert.Equal(users.Id("a"), standings[0].UserId)
+	assert.Equal(2+2+2+2.0, standings[0].Score)
+	assert.Equal(4, standings[0].Played)
+	assert.Equal(1, standings[0].Streak)
+
+	assert.Equal(users.Id("b"), standings[1].UserId)
+	assert.Equal(1.0, standings[1].Score)
+}
+
+func (s *ArenaTestSuite) TestFire() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b")
+	results := arenaGames(
+		finished("a", "b", 1),
+		finished("b", "a", 0),
+		finished("a", "b", 1),
+		finished("b", "a", 0),
+	)
+
+	standings := standings(Arena, players, results)
+
+	assert.Equal(2+2+4+4.0, standings[0].Score)
+	assert.Equal(4, standings[0].Streak)
+}
+
+func (s *ArenaTestSuite) TestBerserk() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b")
+
+	won := finished("a", "b", 1)
+	won.WhiteBerserk = true
+	lost := finished("b", "a", 1)
+	lost.BlackBerserk = true
+	aborted := Result{
+		Pairing:  Pairing{WhiteId: "a", BlackId: "b"},
+		Finished: true,
+		Aborted:  true,
+	}
+
+	standings := standings(Arena, players, arenaGames(won, lost, aborted))
+
+	// only winning after berserking earns the extra point, and
+	// aborted games don't count
+	for _, standing := range standings {
+		assert.Equal(2, standing.Played)
+		if standing.UserId == "a" {
+			assert.Equal(3.0, standing.Score)
+		} else {
+			assert.Equal(2.0, standing.Score)
+		}
+	}
+}
+
+// TestPairing pairs the waiting players by rank, but not with whoever
+// they just played
+func (s *ArenaTestSuite) TestPairing() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b", "c", "d", "e")
+	results := arenaGames(
+		finished("a", "b", 1),
+		finished("d", "c", 1),
+		Result{Pairing: Pairing{WhiteId: "e", BlackId: "c"}},
+	)
+
+	waiting := map[users.Id]bool{"a": true, "b": true, "d": true}
+	pairs := arenaRound(players, results, waiting)
+
+	// a and d lead and have both had white once, so the higher ranked
+	// takes it. b just played a, so waits.
+	assert.Equal([]pair{{0, 3}}, pairs)
+
+	// everyone starts out waiting
+	waiting = map[users.Id]bool{"a": true, "b": true, "c": true, "d": true, "e": true}
+	pairs = arenaRound(players, []Result{}, waiting)
+	assert.Equal([]pair{{0, 1}, {2, 3}}, pairs)
+}
+
+// TestTwoPlayers lets the only two players in an arena play each other
+// again and again
+func (s *ArenaTestSuite) TestTwoPlayers() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b")
+	results := arenaGames(finished("a", "b", 1))
+
+	waiting := map[users.Id]bool{"a": true, "b": true}
+	assert.Equal([]pair{{1, 0}}, arenaRound(players, results, waiting))
+}
@@ -0,0 +1,640 @@
+package tournaments
+
+// edge joins two vertices that may be matched, and what matching them is
+// worth
+type edge struct {
+	i, j   int
+	weight int64
+}
+
+// maxWeightMatching pairs up as many of n vertices as it can, and of the
+// ways to pair that many, finds one worth the most. It is Edmonds' blossom
+// algorithm, after Galil's "Efficient algorithms for finding maximum
+// matching in graphs", and takes O(n^3) time.
+//
+// It gives each vertex's mate, or -1 for vertices left unmatched.
+func maxWeightMatching(n int, edges []edge) []int {
+	m := &matching{nvertex: n, edges: edges}
+	m.run()
+	return m.result()
+}
+
+// matching holds the state of the algorithm. Vertices are numbered from
+// 0 to nvertex, and blossoms from nvertex to 2*nvertex. Edge k has the
+// endpoints 2k and 2k+1, its i and j vertices. Labels are 1 for S, 2 for
+// T and 0 for no label.
+type matching struct {
+	nvertex int
+	edges   []edge
+
+	endpoint  []int
+	neighbend [][]int
+	mate      []int
+
+	label     []int
+	labelend  []int
+	inblossom []int
+	parent    []int
+	childs    [][]int
+	base      []int
+	endps     [][]int
+	bestedge  []int
+	bestedges [][]int
+	unused    []int
+	dualvar   []int64
+	allowedge []bool
+	queue     []int
+}
+
+func (m *matching) slack(k int) int64 {
+	e := m.edges[k]
+	return m.dualvar[e.i] + m.dualvar[e.j] - 2*e.weight
+}
+
+// leaves lists the vertices inside a blossom
+func (m *matching) leaves(b int) []int {
+	if b < m.nvertex {
+		return []int{b}
+	}
+
+	leaves := []int{}
+	for _, t := range m.childs[b] {
+		leaves = append(leaves, m.leaves(t)...)
+	}
+	return leaves
+}
+
+// assignLabel labels w's top level blossom, reached through endpoint p,
+// then the mate of its base if it was labelled T
+func (m *matching) assignLabel(w, t, p int) {
+	b := m.inblossom[w]
+	m.label[w], m.label[b] = t, t
+	m.labelend[w], m.labelend[b] = p, p
+	m.bestedge[w], m.bestedge[b] = -1, -1
+
+	if t == 1 {
+		m.queue = append(m.queue, m.leaves(b)...)
+	} else if t == 2 {
+		base := m.base[b]
+		m.assignLabel(m.endpoint[m.mate[base]], 1, m.mate[base]^1)
+	}
+}
+
+// scanBlossom traces back from v and w to find a new blossom's base, or
+// -1 when they lead to separate free vertices and there is an augmenting
+// path instead
+func (m *matching) scanBlossom(v, w int) int {
+	path := []int{}
+	base := -1
+
+	for v != -1 || w != -1 {
+		b := m.inblossom[v]
+		if m.label[b]&4 != 0 {
+			base = m.base[b]
+			break
+		}
+
+		path = append(path, b)
+		m.label[b] = 5
+
+		if m.labelend[b] == -1 {
+			v = -1
+		} else {
+			v = m.endpoint[m.labelend[b]]
+			b = m.inblossom[v]
+			v = m.endpoint[m.labelend[b]]
+		}
+
+		if w != -1 {
+			v, w = w, v
+		}
+	}
+
+	for _, b := range path {
+		m.label[b] = 1
+	}
+
+	return base
+}
+
+// addBlossom makes a blossom with the given base, closed by edge k
+func (m *matching) addBlossom(base, k int) {
+	v, w := m.edges[k].i, m.edges[k].j
+	bb := m.inblossom[base]
+	bv := m.inblossom[v]
+	bw := m.inblossom[w]
+
+	b := m.unused[len(m.unused)-1]
+	m.unused = m.unused[:len(m.unused)-1]
+
+	m.base[b] = base
+	m.parent[b] = -1
+	m.parent[bb] = b
+
+	path := []int{}
+	endps := []int{}
+	for bv != bb {
+		m.parent[bv] = b
+		path = append(path, bv)
+		endps = append(endps, m.labelend[bv])
+		v = m.endpoint[m.labelend[bv]]
+		bv = m.inblossom[v]
+	}
+
+	path = append(path, bb)
+	reverse(path)
+	reverse(endps)
+	endps = append(endps, 2*k)
+
+	for bw != bb {
+		m.parent[bw] = b
+		path = append(path, bw)
+		endps = append(endps, m.labelend[bw]^1)
+		w = m.endpoint[m.labelend[bw]]
+		bw = m.inblossom[w]
+	}
+
+	m.childs[b] = path
+	m.endps[b] = endps
+
+	m.label[b] = 1
+	m.labelend[b] = m.labelend[bb]
+	m.dualvar[b] = 0
+
+	for _, v := range m.leaves(b) {
+		if m.label[m.inblossom[v]] == 2 {
+			m.queue = append(m.queue, v)
+		}
+		m.inblossom[v] = b
+	}
+
+	bestedgeto := make([]int, 2*m.nvertex)
+	for i := range bestedgeto {
+		bestedgeto[i] = -1
+	}
+
+	for _, bv := range path {
+		var nblists [][]int
+		if m.bestedges[bv] == nil {
+			for _, v := range m.leaves(bv) {
+				nblist := []int{}
+				for _, p := range m.neighbend[v] {
+					nblist = append(nblist, p/2)
+				}
+				nblists = append(nblists, nblist)
+			}
+		} else {
+			nblists = [][]int{m.bestedges[bv]}
+		}
+
+		for _, nblist := range nblists {
+			for _, k := range nblist {
+				i, j := m.edges[k].i, m.edges[k].j
+				if m.inblossom[j] == b {
+					i, j = j, i
+				}
+				bj := m.inblossom[j]
+				if bj != b && m.label[bj] == 1 &&
+					(bestedgeto[bj] == -1 || m.slack(k) < m.slack(bestedgeto[bj])) {
+					bestedgeto[bj] = k
+				}
+			}
+		}
+
+		m.bestedges[bv] = nil
+		m.bestedge[bv] = -1
+	}
+
+	m.bestedges[b] = []int{}
+	for _, k := range bestedgeto {
+		if k != -1 {
+			m.bestedges[b] = append(m.bestedges[b], k)
+		}
+	}
+
+	m.bestedge[b] = -1
+	for _, k := range m.bestedges[b] {
+		if m.bestedge[b] == -1 || m.slack(k) < m.slack(m.bestedge[b]) {
+			m.bestedge[b] = k
+		}
+	}
+}
+
+// expandBlossom breaks a blossom back into its sub-blossoms, relabelling
+// them if it is done mid-stage
+func (m *matching) expandBlossom(b int, endstage bool) {
+	for _, s := range m.childs[b] {
+		m.parent[s] = -1
+		if s < m.nvertex {
+			m.inblossom[s] = s
+		} else if endstage && m.dualvar[s] == 0 {
+			m.expandBlossom(s, endstage)
+		} else {
+			for _, v := range m.leaves(s) {
+				m.inblossom[v] = s
+			}
+		}
+	}
+
+	if !endstage && m.label[b] == 2 {
+		childs, endps := m.childs[b], m.endps[b]
+
+		entrychild := m.inblossom[m.endpoint[m.labelend[b]^1]]
+		j := index(childs, entrychild)
+
+		var jstep, endptrick int
+		if j&1 != 0 {
+			j -= len(childs)
+			jstep = 1
+			endptrick = 0
+		} else {
+			jstep = -1
+			endptrick = 1
+		}
+
+		p := m.labelend[b]
+		for j != 0 {
+			m.label[m.endpoint[p^1]] = 0
+			m.label[m.endpoint[at(endps, j-endptrick)^endptrick^1]] = 0
+			m.assignLabel(m.endpoint[p^1], 2, p)
+
+			m.allowedge[at(endps, j-endptrick)/2] = true
+			j += jstep
+			p = at(endps, j-endptrick) ^ endptrick
+
+			m.allowedge[p/2] = true
+			j += jstep
+		}
+
+		bv := at(childs, j)
+		m.label[m.endpoint[p^1]], m.label[bv] = 2, 2
+		m.labelend[m.endpoint[p^1]], m.labelend[bv] = p, p
+		m.bestedge[bv] = -1
+
+		j += jstep
+		for at(childs, j) != entrychild {
+			bv := at(childs, j)
+			if m.label[bv] == 1 {
+				j += jstep
+				continue
+			}
+
+			reached := -1
+			for _, v := range m.leaves(bv) {
+				if m.label[v] != 0 {
+					reached = v
+					break
+				}
+			}
+
+			if reached != -1 {
+				m.label[reached] = 0
+				m.label[m.endpoint[m.mate[m.base[bv]]]] = 0
+				m.assignLabel(reached, 2, m.labelend[reached])
+			}
+
+			j += jstep
+		}
+	}
+
+	m.label[b], m.labelend[b] = -1, -1
+	m.childs[b], m.endps[b] = nil, nil
+	m.base[b] = -1
+	m.bestedges[b] = nil
+	m.bestedge[b] = -1
+	m.unused = append(m.unused, b)
+}
+
+// augmentBlossom swaps the matched and unmatched edges along the path
+// from vertex v to the base of blossom b
+func (m *matching) augmentBlossom(b, v int) {
+	t := v
+	for m.parent[t] != b {
+		t = m.parent[t]
+	}
+	if t >= m.nvertex {
+		m.augmentBlossom(t, v)
+	}
+
+	childs, endps := m.childs[b], m.endps[b]
+
+	i := index(childs, t)
+	j := i
+
+	var jstep, endptrick int
+	if i&1 != 0 {
+		j -= len(childs)
+		jstep = 1
+		endptrick = 0
+	} else {
+		jstep = -1
+		endptrick = 1
+	}
+
+	for j != 0 {
+		j += jstep
+		t = at(childs, j)
+		p := at(endps, j-endptrick) ^ endptrick
+		if t >= m.nvertex {
+			m.augmentBlossom(t, m.endpoint[p])
+		}
+
+		j += jstep
+		t = at(childs, j)
+		if t >= m.nvertex {
+			m.augmentBlossom(t, m.endpoint[p^1])
+		}
+
+		m.mate[m.endpoint[p]] = p ^ 1
+		m.mate[m.endpoint[p^1]] = p
+	}
+
+	m.childs[b] = append(append([]int{}, childs[i:]...), childs[:i]...)
+	m.endps[b] = append(append([]int{}, endps[i:]...), endps[:i]...)
+	m.base[b] = m.base[m.childs[b][0]]
+}
+
+// augmentMatching swaps the matched and unmatched edges along the
+// augmenting path through edge k
+func (m *matching) augmentMatching(k int) {
+	e := m.edges[k]
+
+	for _, start := range [][2]int{{e.i, 2*k + 1}, {e.j, 2 * k}} {
+		s, p := start[0], start[1]
+		for {
+			bs := m.inblossom[s]
+			if bs >= m.nvertex {
+				m.augmentBlossom(bs, s)
+			}
+			m.mate[s] = p
+
+			if m.labelend[bs] == -1 {
+				break
+			}
+
+			t := m.endpoint[m.labelend[bs]]
+			bt := m.inblossom[t]
+			s = m.endpoint[m.labelend[bt]]
+			j := m.endpoint[m.labelend[bt]^1]
+			if bt >= m.nvertex {
+				m.augmentBlossom(bt, j)
+			}
+			m.mate[j] = m.labelend[bt]
+			p = m.labelend[bt] ^ 1
+		}
+	}
+}
+
+func (m *matching) init() {
+	n := m.nvertex
+
+	var maxweight int64
+	for _, e := range m.edges {
+		if e.weight > maxweight {
+			maxweight = e.weight
+		}
+	}
+
+	m.endpoint = make([]int, 2*len(m.edges))
+	m.neighbend = make([][]int, n)
+	for k, e := range m.edges {
+		m.endpoint[2*k] = e.i
+		m.endpoint[2*k+1] = e.j
+		m.neighbend[e.i] = append(m.neighbend[e.i], 2*k+1)
+		m.neighbend[e.j] = append(m.neighbend[e.j], 2*k)
+	}
+
+	m.mate = filled(n, -1)
+	m.label = make([]int, 2*n)
+	m.labelend = filled(2*n, -1)
+	m.inblossom = make([]int, n)
+	m.parent = filled(2*n, -1)
+	m.childs = make([][]int, 2*n)
+	m.base = filled(2*n, -1)
+	m.endps = make([][]int, 2*n)
+	m.bestedge = filled(2*n, -1)
+	m.bestedges = make([][]int, 2*n)
+	m.dualvar = make([]int64, 2*n)
+	m.allowedge = make([]bool, len(m.edges))
+
+	for v := 0; v < n; v++ {
+		m.inblossom[v] = v
+		m.base[v] = v
+		m.dualvar[v] = maxweight
+	}
+	for b := n; b < 2*n; b++ {
+		m.unused = append(m.unused, b)
+	}
+}
+
+func (m *matching) run() {
+	n := m.nvertex
+	m.init()
+
+	// each stage augments the matching by one edge, until none can
+	for stage := 0; stage < n; stage++ {
+		for i := range m.label {
+			m.label[i] = 0
+			m.bestedge[i] = -1
+		}
+		for b := n; b < 2*n; b++ {
+			m.bestedges[b] = nil
+		}
+		for k := range m.allowedge {
+			m.allowedge[k] = false
+		}
+		m.queue = m.queue[:0]
+
+		for v := 0; v < n; v++ {
+			if m.mate[v] == -1 && m.label[m.inblossom[v]] == 0 {
+				m.assignLabel(v, 1, -1)
+			}
+		}
+
+		augmented := false
+		for {
+			for len(m.queue) > 0 && !augmented {
+				v := m.queue[len(m.queue)-1]
+				m.queue = m.queue[:len(m.queue)-1]
+
+				for _, p := range m.neighbend[v] {
+					k := p / 2
+					w := m.endpoint[p]
+
+					if m.inblossom[v] == m.inblossom[w] {
+						continue
+					}
+
+					var kslack int64
+					if !m.allowedge[k] {
+						kslack = m.slack(k)
+						if kslack <= 0 {
+							m.allowedge[k] = true
+						}
+					}
+
+					if m.allowedge[k] {
+						if m.label[m.inblossom[w]] == 0 {
+							m.assignLabel(w, 2, p^1)
+						} else if m.label[m.inblossom[w]] == 1 {
+							base := m.scanBlossom(v, w)
+							if base >= 0 {
+								m.addBlossom(base, k)
+							} else {
+								m.augmentMatching(k)
+								augmented = true
+								break
+							}
+						} else if m.label[w] == 0 {
+							m.label[w] = 2
+							m.labelend[w] = p ^ 1
+						}
+					} else if m.label[m.inblossom[w]] == 1 {
+						b := m.inblossom[v]
+						if m.bestedge[b] == -1 || kslack < m.slack(m.bestedge[b]) {
+							m.bestedge[b] = k
+						}
+					} else if m.label[w] == 0 {
+						if m.bestedge[w] == -1 || kslack < m.slack(m.bestedge[w]) {
+							m.bestedge[w] = k
+						}
+					}
+				}
+			}
+
+			if augmented {
+				break
+			}
+
+			// no augmenting path under the current duals, so change
+			// them by as much as they can be
+			deltatype := -1
+			var delta int64
+			deltaedge, deltablossom := -1, -1
+
+			for v := 0; v < n; v++ {
+				if m.label[m.inblossom[v]] == 0 && m.bestedge[v] != -1 {
+					d := m.slack(m.bestedge[v])
+					if deltatype == -1 || d < delta {
+						delta, deltatype, deltaedge = d, 2, m.bestedge[v]
+					}
+				}
+			}
+
+			for b := 0; b < 2*n; b++ {
+				if m.parent[b] == -1 && m.label[b] == 1 && m.bestedge[b] != -1 {
+					d := m.slack(m.bestedge[b]) / 2
+					if deltatype == -1 || d < delta {
+						delta, deltatype, deltaedge = d, 3, m.bestedge[b]
+					}
+				}
+			}
+
+			for b := n; b < 2*n; b++ {
+				if m.base[b] >= 0 && m.parent[b] == -1 && m.label[b] == 2 &&
+					(deltatype == -1 || m.dualvar[b] < delta) {
+					delta, deltatype, deltablossom = m.dualvar[b], 4, b
+				}
+			}
+
+			if deltatype == -1 {
+				// nothing more can be matched; the matching is as
+				// large as it gets
+				deltatype = 1
+				delta = m.dualvar[0]
+				for v := 1; v < n; v++ {
+					if m.dualvar[v] < delta {
+						delta = m.dualvar[v]
+					}
+				}
+				if delta < 0 {
+					delta = 0
+				}
+			}
+
+			for v := 0; v < n; v++ {
+				if m.label[m.inblossom[v]] == 1 {
+					m.dualvar[v] -= delta
+				} else if m.label[m.inblossom[v]] == 2 {
+					m.dualvar[v] += delta
+				}
+			}
+			for b := n; b < 2*n; b++ {
+				if m.base[b] >= 0 && m.parent[b] == -1 {
+					if m.label[b] == 1 {
+						m.dualvar[b] += delta
+					} else if m.label[b] == 2 {
+						m.dualvar[b] -= delta
+					}
+				}
+			}
+
+			if deltatype == 1 {
+				break
+			} else if deltatype == 2 {
+				m.allowedge[deltaedge] = true
+				i, j := m.edges[deltaedge].i, m.edges[deltaedge].j
+				if m.label[m.inblossom[i]] == 0 {
+					i, j = j, i
+				}
+				m.queue = append(m.queue, i)
+			} else if deltatype == 3 {
+				m.allowedge[deltaedge] = true
+				m.queue = append(m.queue, m.edges[deltaedge].i)
+			} else if deltatype == 4 {
+				m.expandBlossom(deltablossom, false)
+			}
+		}
+
+		if !augmented {
+			break
+		}
+
+		for b := n; b < 2*n; b++ {
+			if m.parent[b] == -1 && m.base[b] >= 0 && m.label[b] == 1 && m.dualvar[b] == 0 {
+				m.expandBlossom(b, true)
+			}
+		}
+	}
+}
+
+func (m *matching) result() []int {
+	mates := filled(m.nvertex, -1)
+	for v := 0; v < m.nvertex; v++ {
+		if m.mate[v] >= 0 {
+			mates[v] = m.endpoint[m.mate[v]]
+		}
+	}
+	return mates
+}
+
+func filled(n, value int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func reverse(values []int) {
+	for l, r := 0, len(values)-1; l < r; l, r = l+1, r-1 {
+		values[l], values[r] = values[r], values[l]
+	}
+}
+
+func index(values []int, value int) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// at indexes values from the end when i is negative
+func at(values []int, i int) int {
+	if i < 0 {
+		i += len(values)
+	}
+	return values[i]
+}
@@ -0,0 +1,112 @@
+package tournaments
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"math/rand"
+	"testing"
+)
+
+type MatchingTestSuite struct {
+	suite.Suite
+}
+
+func TestMatchingTestSuite(t *testing.T) {
+	suite.Run(t, new(MatchingTestSuite))
+}
+
+func (s *MatchingTestSuite) TestSmall() {
+	assert := assert.New(s.T())
+
+	assert.Equal([]int{}, maxWeightMatching(0, []edge{}))
+	assert.Equal([]int{1, 0}, maxWeightMatching(2, []edge{{0, 1, 1}}))
+
+	// the heavier middle edge loses to matching both ends
+	assert.Equal([]int{1, 0, 3, 2}, maxWeightMatching(4, []edge{
+		{0, 1, 5}, {1, 2, 11}, {2, 3, 5},
+	}))
+
+	// an odd cycle leaves one vertex out
+	mates := maxWeightMatching(3, []edge{{0, 1, 6}, {1, 2, 10}, {2, 0, 5}})
+	assert.Equal([]int{-1, 2, 1}, mates)
+}
+
+// TestAgainstBruteForce compares matchings of random graphs with the
+// best found by trying every matching
+func (s *MatchingTestSuite) TestAgainstBruteForce() {
+	assert := assert.New(s.T())
+	random := rand.New(rand.NewSource(1))
+
+	for trial := 0; trial < 2000; trial++ {
+		n := 1 + random.Intn(9)
+		edges := []edge{}
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				if random.Intn(3) > 0 {
+					edges = append(edges, edge{i, j, int64(random.Intn(20))})
+				}
+			}
+		}
+
+		mates := maxWeightMatching(n, edges)
+
+		size, weight, ok := measure(n, edges, mates)
+		assert.True(ok, "trial %d gave an inconsistent matching", trial)
+
+		bestSize, bestWeight := bruteForce(n, edges, make([]int, n), 0)
+		if !assert.Equal(bestSize, size, "trial %d", trial) ||
+			!assert.Equal(bestWeight, weight, "trial %d", trial) {
+			return
+		}
+	}
+}
+
+// measure counts the edges of a matching and what they are worth
+func measure(n int, edges []edge, mates []int) (int, int64, bool) {
+	size, weight := 0, int64(0)
+	for v, mate := range mates {
+		if mate == -1 {
+			continue
+		}
+		if mates[mate] != v {
+			return 0, 0, false
+		}
+		if v > mate {
+			continue
+		}
+
+		found := false
+		for _, e := range edges {
+			if (e.i == v && e.j == mate) || (e.i == mate && e.j == v) {
+				size, weight, found = size+1, weight+e.weight, true
+				break
+			}
+		}
+		if !found {
+			return 0, 0, false
+		}
+	}
+	return size, weight, true
+}
+
+// bruteForce finds the largest matching worth the most out of the edges
+// from the given one onwards, leaving out the vertices marked used
+func bruteForce(n int, edges []edge, used []int, from int) (int, int64) {
+	bestSize, bestWeight := 0, int64(0)
+	for k := from; k < len(edges); k++ {
+		e := edges[k]
+		if used[e.i] != 0 || used[e.j] != 0 {
+			continue
+		}
+
+		used[e.i], used[e.j] = 1, 1
+		size, weight := bruteForce(n, edges, used, k+1)
+		used[e.i], used[e.j] = 0, 0
+
+		size, weight = size+1, weight+e.weight
+		if size > bestSize || (size == bestSize && weight > bestWeight) {
+			bestSize, bestWeight = size, weight
+		}
+	}
+	return bestSize, bestWeight
+}
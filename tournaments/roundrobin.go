@@ -0,0 +1,63 @@
+package tournaments
+
+// bye stands in for the missing player when there is an odd number of
+// players
+const bye = -1
+
+type pair struct {
+	white int
+	black int
+}
+
+// roundRobinRounds is how many rounds it takes for everyone to play
+// everyone else once
+func roundRobinRounds(players int) int {
+	if players%2 == 0 {
+		return players - 1
+	} else {
+		return players
+	}
+}
+
+// bergerRound pairs the players of a round-robin round using Berger
+// tables. Players are numbered from 0 in seed order and rounds from 1.
+// With an odd number of players, whoever is paired against the last,
+// imaginary, player has a bye.
+func bergerRound(players, round int) []pair {
+	n := players
+	if n%2 == 1 {
+		n += 1
+	}
+
+	fixed := n - 1
+	shift := ((round - 1) * n / 2) % fixed
+
+	pairs := []pair{}
+	for board := 0; board < n/2; board++ {
+		var p pair
+
+		if board == 0 {
+			// the last player alternates colours each round
+			if round%2 == 0 {
+				p = pair{fixed, shift}
+			} else {
+				p = pair{shift, fixed}
+			}
+		} else {
+			p = pair{
+				(shift + board) % fixed,
+				(fixed - board + shift) % fixed,
+			}
+		}
+
+		if p.white >= players {
+			p = pair{p.black, bye}
+		} else if p.black >= players {
+			p.black = bye
+		}
+
+		pairs = append(pairs, p)
+	}
+
+	return pairs
+}
@@ -0,0 +1,73 @@
+package tournaments
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type RoundRobinTestSuite struct {
+	suite.Suite
+}
+
+func TestRoundRobinTestSuite(t *testing.T) {
+	suite.Run(t, new(RoundRobinTestSuite))
+}
+
+// TestBergerTables checks the published Berger table for six players,
+// numbered from 1 as in the tables
+func (s *RoundRobinTestSuite) TestBergerTables() {
+	expected := [][]pair{
+		{{1, 6}, {2, 5}, {3, 4}},
+		{{6, 4}, {5, 3}, {1, 2}},
+		{{2, 6}, {3, 1}, {4, 5}},
+		{{6, 5}, {1, 4}, {2, 3}},
+		{{3, 6}, {4, 2}, {5, 1}},
+	}
+
+	assert := assert.New(s.T())
+	assert.Equal(5, roundRobinRounds(6))
+
+	for i, round := range expected {
+		actual := bergerRound(6, i+1)
+		for j := range actual {
+			actual[j].white += 1
+			actual[j].black += 1
+		}
+
+		assert.Equal(round, actual, "round %d", i+1)
+	}
+}
+
+// TestEveryoneMeets checks that with an odd number of players everyone
+// plays everyone else exactly once, and has one bye
+func (s *RoundRobinTestSuite) TestEveryoneMeets() {
+	players := 5
+	met := make(map[pair]int)
+	byes := make(map[int]int)
+
+	assert := assert.New(s.T())
+	assert.Equal(5, roundRobinRounds(players))
+
+	for round := 1; round <= roundRobinRounds(players); round++ {
+		for _, p := range bergerRound(players, round) {
+			if p.black == bye {
+				byes[p.white] += 1
+			} else if p.white < p.black {
+				met[pair{p.white, p.black}] += 1
+			} else {
+				met[pair{p.black, p.white}] += 1
+			}
+		}
+	}
+
+	assert.Equal(10, len(met))
+	for _, count := range met {
+		assert.Equal(1, count)
+	}
+
+	assert.Equal(5, len(byes))
+	for _, count := range byes {
+		assert.Equal(1, count)
+	}
+}
@@ -0,0 +1,173 @@
+package tournaments
+
+import (
+	"sort"
+	"time"
+
+	"foodtastechess/users"
+)
+
+// Result is a pairing along with how it turned out. Aborted games are
+// lost by whoever aborted them.
+type Result struct {
+	Pairing
+
+	Finished     bool
+	Aborted      bool      `json:",omitempty"`
+	EndedAt      time.Time `json:"-"`
+	WhiteScore   float64
+	BlackScore   float64
+	WhiteBerserk bool `json:",omitempty"`
+	BlackBerserk bool `json:",omitempty"`
+}
+
+// forfeit scores an aborted game as a loss for whoever aborted it, so
+// they can't cost their opponent the point too. Games aborted before
+// that was recorded score nothing.
+func (r *Result) forfeit(abortedBy users.Id) {
+	r.Aborted = true
+
+	switch abortedBy {
+	case r.WhiteId:
+		r.BlackScore = 1
+	case r.BlackId:
+		r.WhiteScore = 1
+	}
+}
+
+type Standing struct {
+	Rank            int
+	UserId          users.Id
+	Seed            int
+	Score           float64
+	Played          int
+	SonnebornBerger float64
+	Buchholz        float64
+	MedianBuchholz  float64
+
+	// Streak is how many arena games a player has won in a row
+	Streak int `json:",omitempty"`
+}
+
+// standings ranks players by score. Round-robin ties are broken by
+// Sonneborn-Berger score, and Swiss ties by Buchholz then median
+// Buchholz score, before falling back to seed. Arenas score by points.
+func standings(format Format, players []Participant, results []Result) []Standing {
+	if format == Arena {
+		return arenaStandings(players, results)
+	}
+
+	byUser := make(map[users.Id]*Standing)
+	list := []*Standing{}
+	for _, player := range players {
+		standing := &Standing{UserId: player.UserId, Seed: player.Seed}
+		byUser[player.UserId] = standing
+		list = append(list, standing)
+	}
+
+	finished := []Result{}
+	for _, result := range results {
+		if !result.Finished {
+			continue
+		}
+		finished = append(finished, result)
+
+		if white, ok := byUser[result.WhiteId]; ok {
+			white.Score += result.WhiteScore
+			if !result.bye() {
+				white.Played += 1
+			}
+		}
+
+		if black, ok := byUser[result.BlackId]; ok {
+			black.Score += result.BlackScore
+			black.Played += 1
+		}
+	}
+
+	// the tie-breaks weigh each opponent's final score, so they need
+	// every score first
+	opponentScores := make(map[users.Id][]float64)
+	for _, result := range finished {
+		if result.bye() {
+			continue
+		}
+
+		white, whiteOk := byUser[result.WhiteId]
+		black, blackOk := byUser[result.BlackId]
+		if !whiteOk || !blackOk {
+			continue
+		}
+
+		white.SonnebornBerger += result.WhiteScore * black.Score
+		black.SonnebornBerger += result.BlackScore * white.Score
+
+		opponentScores[white.UserId] = append(opponentScores[white.UserId], black.Score)
+		opponentScores[black.UserId] = append(opponentScores[black.UserId], white.Score)
+	}
+
+	for _, standing := range list {
+		scores := opponentScores[standing.UserId]
+		standing.Buchholz = sum(scores)
+		standing.MedianBuchholz = standing.Buchholz
+
+		// the median drops the best and worst opponents once there
+		// are enough to leave some
+		if len(scores) > 2 {
+			sort.Float64s(scores)
+			standing.MedianBuchholz = sum(scores[1 : len(scores)-1])
+		}
+	}
+
+	if format == Swiss {
+		sort.Sort(bySwissTieBreaks(list))
+	} else {
+		sort.Sort(byRank(list))
+	}
+
+	ranked := []Standing{}
+	for i, standing := range list {
+		standing.Rank = i + 1
+		ranked = append(ranked, *standing)
+	}
+
+	return ranked
+}
+
+type byRank []*Standing
+
+func (s byRank) Len() int      { return len(s) }
+func (s byRank) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byRank) Less(i, j int) bool {
+	if s[i].Score != s[j].Score {
+		return s[i].Score > s[j].Score
+	} else if s[i].SonnebornBerger != s[j].SonnebornBerger {
+		return s[i].SonnebornBerger > s[j].SonnebornBerger
+	} else {
+		return s[i].Seed < s[j].Seed
+	}
+}
+
+type bySwissTieBreaks []*Standing
+
+func (s bySwissTieBreaks) Len() int      { return len(s) }
+func (s bySwissTieBreaks) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bySwissTieBreaks) Less(i, j int) bool {
+	if s[i].Score != s[j].Score {
+		return s[i].Score > s[j].Score
+	} else if s[i].Buchholz != s[j].Buchholz {
+		return s[i].Buchholz > s[j].Buchholz
+	} else if s[i].MedianBuchholz != s[j].MedianBuchholz {
+		return s[i].MedianBuchholz > s[j].MedianBuchholz
+	} else {
+		return s[i].Seed < s[j].Seed
+	}
+}
+
+func sum(values []float64) float64 {
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
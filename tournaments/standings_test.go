@@ -0,0 +1,114 @@
+package tournaments
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+
+	"foodtastechess/users"
+)
+
+type StandingsTestSuite struct {
+	suite.Suite
+}
+
+func TestStandingsTestSuite(t *testing.T) {
+	suite.Run(t, new(StandingsTestSuite))
+}
+
+func participants(ids ...users.Id) []Participant {
+	players := []Participant{}
+	for i, id := range ids {
+		players = append(players, Participant{UserId: id, Seed: i + 1})
+	}
+	return players
+}
+
+func finished(white, black users.Id, whiteScore float64) Result {
+	return Result{
+		Pairing:    Pairing{WhiteId: white, BlackId: black},
+		Finished:   true,
+		WhiteScore: whiteScore,
+		BlackScore: 1 - whiteScore,
+	}
+}
+
+func (s *StandingsTestSuite) TestScores() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b", "c")
+	results := []Result{
+		finished("a", "b", 1),
+		finished("c", "a", 0.5),
+		{Pairing: Pairing{WhiteId: "b", BlackId: "c"}},
+		{Pairing: Pairing{WhiteId: "b"}, Finished: true},
+	}
+
+	standings := standings(RoundRobin, players, results)
+
+	assert.Equal(3, len(standings))
+	assert.Equal(users.Id("a"), standings[0].UserId)
+	assert.Equal(1, standings[0].Rank)
+	assert.Equal(1.5, standings[0].Score)
+	assert.Equal(2, standings[0].Played)
+
+	assert.Equal(users.Id("c"), standings[1].UserId)
+	assert.Equal(0.5, standings[1].Score)
+	assert.Equal(1, standings[1].Played)
+
+	// byes aren't games played
+	assert.Equal(users.Id("b"), standings[2].UserId)
+	assert.Equal(0.0, standings[2].Score)
+	assert.Equal(1, standings[2].Played)
+}
+
+// TestForfeit tests that aborting a game loses it
+func (s *StandingsTestSuite) TestForfeit() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b")
+	aborted := Result{
+		Pairing:  Pairing{WhiteId: "a", BlackId: "b"},
+		Finished: true,
+	}
+	aborted.forfeit("a")
+
+	standings := standings(RoundRobin, players, []Result{aborted})
+
+	assert.Equal(users.Id("b"), standings[0].UserId)
+	assert.Equal(1.0, standings[0].Score)
+	assert.Equal(0.0, standings[1].Score)
+}
+
+// TestSonnebornBerger ties two players on points, who are split by whom
+// they scored against
+func (s *StandingsTestSuite) TestSonnebornBerger() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b", "c", "d")
+	results := []Result{
+		finished("a", "d", 1),
+		finished("b", "c", 0),
+		finished("c", "a", 1),
+		finished("d", "b", 0.5),
+		finished("a", "b", 0.5),
+		finished("c", "d", 0.5),
+	}
+
+	standings := standings(RoundRobin, players, results)
+
+	assert.Equal(users.Id("c"), standings[0].UserId)
+	assert.Equal(2.5, standings[0].Score)
+	assert.Equal(users.Id("a"), standings[1].UserId)
+	assert.Equal(1.5, standings[1].Score)
+
+	// b and d both drew each other and a or c, but d's draw was with
+	// the winner
+	assert.Equal(users.Id("d"), standings[2].UserId)
+	assert.Equal(1.0, standings[2].Score)
+	assert.Equal(1.75, standings[2].SonnebornBerger)
+	assert.Equal(users.Id("b"), standings[3].UserId)
+	assert.Equal(1.0, standings[3].Score)
+	assert.Equal(1.25, standings[3].SonnebornBerger)
+	assert.Equal(4, standings[3].Rank)
+}
@@ -0,0 +1,348 @@
+package tournaments
+
+import (
+	"sort"
+
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+// swissPlayer is a player's history going into a Swiss round
+type swissPlayer struct {
+	index     int
+	seed      int
+	score     float64
+	opponents map[users.Id]bool
+	colors    []game.Color
+	hadBye    bool
+	userId    users.Id
+}
+
+// colorDifference is how many more games a player has had white than
+// black
+func (p *swissPlayer) colorDifference() int {
+	difference := 0
+	for _, color := range p.colors {
+		if color == game.White {
+			difference += 1
+		} else {
+			difference -= 1
+		}
+	}
+	return difference
+}
+
+// preference is the colour a player is due, and how strongly: 2 when it
+// must be given, 1 when they have had more of the other colour, and 0
+// when they just had the other colour last
+func (p *swissPlayer) preference() (game.Color, int) {
+	n := len(p.colors)
+	if n == 0 {
+		return "", 0
+	}
+
+	last := p.colors[n-1]
+	due := game.White
+	if last == game.White {
+		due = game.Black
+	}
+
+	difference := p.colorDifference()
+	if difference > 0 {
+		due = game.Black
+	} else if difference < 0 {
+		due = game.White
+	}
+
+	switch {
+	case difference > 1 || difference < -1:
+		return due, 2
+	case n >= 2 && p.colors[n-2] == last:
+		return due, 2
+	case difference != 0:
+		return due, 1
+	default:
+		return due, 0
+	}
+}
+
+// allows reports whether a player can take a colour without having it
+// three times running or two more times than the other
+func (p *swissPlayer) allows(color game.Color) bool {
+	n := len(p.colors)
+	if n >= 2 && p.colors[n-1] == color && p.colors[n-2] == color {
+		return false
+	}
+
+	difference := p.colorDifference()
+	if color == game.White {
+		return difference < 2
+	} else {
+		return difference > -2
+	}
+}
+
+// swissRound pairs a round with the Dutch system. Players are ranked by
+// score then seed and split into score groups, and the top half of each
+// group is paired against the bottom half. When that would repeat a game
+// or break colour rules, players float to a neighbouring group, as few
+// and as near as possible. With an odd number of players the lowest
+// ranked player who hasn't had a bye gets one.
+//
+// Pairs are indexes into players, in board order. No pairs are returned
+// when the round can't be paired without repeating a game.
+func swissRound(players []Participant, results []Result) []pair {
+	ranked := swissPlayers(players, results)
+
+	if len(ranked)%2 == 0 {
+		pairs, _ := pairRanked(ranked)
+		return pairs
+	}
+
+	for _, hadByes := range []bool{false, true} {
+		for i := len(ranked) - 1; i >= 0; i-- {
+			if ranked[i].hadBye != hadByes {
+				continue
+			}
+
+			rest := []*swissPlayer{}
+			rest = append(rest, ranked[:i]...)
+			rest = append(rest, ranked[i+1:]...)
+
+			if pairs, ok := pairRanked(rest); ok {
+				return append(pairs, pair{ranked[i].index, bye})
+			}
+		}
+	}
+
+	return []pair{}
+}
+
+// swissPlayers builds each player's history from the results so far and
+// ranks them
+func swissPlayers(players []Participant, results []Result) []*swissPlayer {
+	byUser := make(map[users.Id]*swissPlayer)
+	ranked := []*swissPlayer{}
+	for i, player := range players {
+		p := &swissPlayer{
+			index:     i,
+			seed:      player.Seed,
+			opponents: make(map[users.Id]bool),
+			colors:    []game.Color{},
+			userId:    player.UserId,
+		}
+		byUser[player.UserId] = p
+		ranked = append(ranked, p)
+	}
+
+	for _, result := range results {
+		white, whiteOk := byUser[result.WhiteId]
+		black, blackOk := byUser[result.BlackId]
+
+		if result.bye() {
+			if whiteOk {
+				white.hadBye = true
+				white.score += result.WhiteScore
+			}
+			continue
+		}
+
+		if whiteOk {
+			white.opponents[result.BlackId] = true
+			white.colors = append(white.colors, game.White)
+			white.score += result.WhiteScore
+		}
+
+		if blackOk {
+			black.opponents[result.WhiteId] = true
+			black.colors = append(black.colors, game.Black)
+			black.score += result.BlackScore
+		}
+	}
+
+	sort.Sort(bySwissRank(ranked))
+	return ranked
+}
+
+// pairRanked pairs each of the ranked players with someone they haven't
+// met, in board order. Of the ways to do so it picks the one that keeps
+// players closest to their own score group, then gives the most players
+// the colour they are due, then keeps closest to pairing the top half of
+// each group against the bottom half. Finding it is a maximum weight
+// matching, with each of these goals outweighing everything the ones
+// after it could add up to.
+func pairRanked(ranked []*swissPlayer) ([]pair, bool) {
+	n := len(ranked)
+	positions, sizes := groupPositions(ranked)
+
+	// a pairing is worth less the further it strays from each goal. Over
+	// a whole round, strays from the order add up to less than n*n and
+	// from colours to less than 4*n.
+	var (
+		orderCost int64 = 1
+		colorCost int64 = int64(n*n) + 1
+		scoreCost int64 = colorCost * int64(4*n+1)
+	)
+
+	widest := int64(0)
+	if n > 0 {
+		widest = halfPoints(ranked[0].score - ranked[n-1].score)
+	}
+	worth := scoreCost * (widest*widest + 1)
+
+	edges := []edge{}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			higher, lower := ranked[i], ranked[j]
+
+			white, black, ok := colors(higher, lower, 1)
+			if !ok {
+				continue
+			}
+
+			scoreGap := halfPoints(higher.score - lower.score)
+			colorMisses := int64(disappointment(white, game.White) + disappointment(black, game.Black))
+
+			var order int
+			if higher.score == lower.score {
+				// the ideal opponent is half the group further down
+				order = abs(positions[j] - positions[i] - sizes[i]/2)
+			} else {
+				// floaters should be the last of their group, and meet
+				// the first of the next
+				order = sizes[i] - 1 - positions[i] + positions[j]
+			}
+
+			cost := scoreCost*scoreGap*scoreGap + colorCost*colorMisses + orderCost*int64(order)
+			edges = append(edges, edge{i, j, worth - cost})
+		}
+	}
+
+	mates := maxWeightMatching(n, edges)
+
+	pairs := []pair{}
+	for i, j := range mates {
+		if j == -1 {
+			return nil, false
+		}
+		if j < i {
+			continue
+		}
+
+		white, black, _ := colors(ranked[i], ranked[j], len(pairs)+1)
+		pairs = append(pairs, pair{white.index, black.index})
+	}
+
+	return pairs, true
+}
+
+// groupPositions gives each ranked player's place in their score group,
+// and the size of the group
+func groupPositions(ranked []*swissPlayer) ([]int, []int) {
+	positions := make([]int, len(ranked))
+	sizes := make([]int, len(ranked))
+
+	start := 0
+	for i := range ranked {
+		if i > 0 && ranked[i].score != ranked[i-1].score {
+			start = i
+		}
+		positions[i] = i - start
+	}
+
+	for i := len(ranked) - 1; i >= 0; i-- {
+		if i == len(ranked)-1 || ranked[i].score != ranked[i+1].score {
+			sizes[i] = positions[i] + 1
+		} else {
+			sizes[i] = sizes[i+1]
+		}
+	}
+
+	return positions, sizes
+}
+
+// disappointment is how strongly a player wanted the colour they didn't
+// get, or 0 if they got it
+func disappointment(player *swissPlayer, color game.Color) int {
+	due, strength := player.preference()
+	if due == "" || due == color {
+		return 0
+	}
+	return strength + 1
+}
+
+// halfPoints counts a score in half points, so it can be used in sums
+// that must be exact
+func halfPoints(score float64) int64 {
+	if score < 0 {
+		score = -score
+	}
+	return int64(score*2 + 0.5)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// colors decides who has white when two players meet, or that they
+// can't meet. The higher ranked player is given first.
+func colors(higher, lower *swissPlayer, board int) (*swissPlayer, *swissPlayer, bool) {
+	if higher.opponents[lower.userId] {
+		return nil, nil, false
+	}
+
+	higherDue, higherStrength := higher.preference()
+	lowerDue, lowerStrength := lower.preference()
+
+	var white, black *swissPlayer
+	switch {
+	case higherDue == "" && lowerDue == "":
+		// the first round alternates colours down the boards
+		if board%2 == 1 {
+			white, black = higher, lower
+		} else {
+			white, black = lower, higher
+		}
+	case higherDue == "":
+		white, black = forColor(lower, higher, lowerDue)
+	case lowerDue == "" || higherDue != lowerDue:
+		white, black = forColor(higher, lower, higherDue)
+	case lowerStrength > higherStrength:
+		white, black = forColor(lower, higher, lowerDue)
+	default:
+		white, black = forColor(higher, lower, higherDue)
+	}
+
+	if white.allows(game.White) && black.allows(game.Black) {
+		return white, black, true
+	} else if black.allows(game.White) && white.allows(game.Black) {
+		return black, white, true
+	} else {
+		return nil, nil, false
+	}
+}
+
+// forColor gives a player the colour they are due, and their opponent
+// the other
+func forColor(player, opponent *swissPlayer, color game.Color) (*swissPlayer, *swissPlayer) {
+	if color == game.White {
+		return player, opponent
+	} else {
+		return opponent, player
+	}
+}
+
+type bySwissRank []*swissPlayer
+
+func (s bySwissRank) Len() int      { return len(s) }
+func (s bySwissRank) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bySwissRank) Less(i, j int) bool {
+	if s[i].score != s[j].score {
+		return s[i].score > s[j].score
+	} else {
+		return s[i].seed < s[j].seed
+	}
+}
@@ -0,0 +1,227 @@
+package tournaments
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+
+	"foodtastechess/users"
+)
+
+type SwissTestSuite struct {
+	suite.Suite
+}
+
+func TestSwissTestSuite(t *testing.T) {
+	suite.Run(t, new(SwissTestSuite))
+}
+
+// play adds the results of a round to results, with the higher seed
+// winning each game
+func play(players []Participant, pairs []pair, round int, results []Result) []Result {
+	for board, p := range pairs {
+		result := Result{
+			Pairing: Pairing{
+				Round:   round,
+				Board:   board + 1,
+				WhiteId: players[p.white].UserId,
+			},
+			Finished: true,
+		}
+
+		if p.black == bye {
+			result.WhiteScore = byeScore(Swiss)
+		} else {
+			result.BlackId = players[p.black].UserId
+			if players[p.white].Seed < players[p.black].Seed {
+				result.WhiteScore = 1
+			} else {
+				result.BlackScore = 1
+			}
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
+
+func seeded(n int) []Participant {
+	ids := []users.Id{}
+	for i := 0; i < n; i++ {
+		ids = append(ids, users.Id(fmt.Sprintf("player%d", i+1)))
+	}
+	return participants(ids...)
+}
+
+// TestFirstRound pairs the top half against the bottom half, alternating
+// colours down the boards
+func (s *SwissTestSuite) TestFirstRound() {
+	assert := assert.New(s.T())
+
+	pairs := swissRound(seeded(6), []Result{})
+
+	assert.Equal([]pair{{0, 3}, {4, 1}, {2, 5}}, pairs)
+}
+
+// TestScoreGroups pairs winners with winners and losers with losers,
+// giving each the colour they didn't have
+func (s *SwissTestSuite) TestScoreGroups() {
+	assert := assert.New(s.T())
+
+	players := seeded(4)
+	results := play(players, swissRound(players, []Result{}), 1, []Result{})
+
+	// 1 beat 3 with white, 2 beat 4 with black
+	pairs := swissRound(players, results)
+
+	assert.Equal([]pair{{1, 0}, {2, 3}}, pairs)
+}
+
+func (s *SwissTestSuite) TestByes() {
+	assert := assert.New(s.T())
+
+	players := seeded(5)
+	results := []Result{}
+
+	byes := make(map[int]bool)
+	for round := 1; round <= 3; round++ {
+		pairs := swissRound(players, results)
+		assert.Equal(3, len(pairs))
+
+		last := pairs[len(pairs)-1]
+		assert.Equal(bye, last.black)
+		assert.False(byes[last.white], "second bye in round %d", round)
+		byes[last.white] = true
+
+		results = play(players, pairs, round, results)
+	}
+
+	// the bye scores a point
+	standings := standings(Swiss, players, results)
+	for _, standing := range standings {
+		if standing.UserId == players[4].UserId {
+			assert.Equal(1.0, standing.Score)
+		}
+	}
+}
+
+// TestNoRepeats runs a full-length Swiss, which must pair everyone with
+// someone new each round while keeping colours balanced
+func (s *SwissTestSuite) TestNoRepeats() {
+	assert := assert.New(s.T())
+
+	players := seeded(8)
+	results := []Result{}
+
+	for round := 1; round <= 7; round++ {
+		pairs := swissRound(players, results)
+		assert.Equal(4, len(pairs), "round %d", round)
+		results = play(players, pairs, round, results)
+	}
+
+	met := make(map[string]bool)
+	for _, result := range results {
+		key := fmt.Sprintf("%s:%s", result.WhiteId, result.BlackId)
+		if result.BlackId < result.WhiteId {
+			key = fmt.Sprintf("%s:%s", result.BlackId, result.WhiteId)
+		}
+		assert.False(met[key], "%s met twice", key)
+		met[key] = true
+	}
+	assert.Equal(28, len(met))
+
+	for _, player := range swissPlayers(players, results) {
+		difference := player.colorDifference()
+		assert.True(difference <= 2 && difference >= -2)
+	}
+}
+
+// TestLargeField pairs a long Swiss with many players, which must not
+// take long however the rounds fall
+func (s *SwissTestSuite) TestLargeField() {
+	assert := assert.New(s.T())
+
+	players := seeded(24)
+	done := make(chan []Result, 1)
+
+	go func() {
+		results := []Result{}
+		for round := 1; round <= 12; round++ {
+			pairs := swissRound(players, results)
+			if !assert.Equal(12, len(pairs), "round %d", round) {
+				break
+			}
+			results = play(players, pairs, round, results)
+		}
+		done <- results
+	}()
+
+	select {
+	case results := <-done:
+		met := make(map[string]bool)
+		for _, result := range results {
+			key := fmt.Sprintf("%s:%s", result.WhiteId, result.BlackId)
+			if result.BlackId < result.WhiteId {
+				key = fmt.Sprintf("%s:%s", result.BlackId, result.WhiteId)
+			}
+			assert.False(met[key], "%s met twice", key)
+			met[key] = true
+		}
+		assert.Equal(24*12/2, len(met))
+	case <-time.After(5 * time.Second):
+		assert.Fail("pairing twelve rounds of 24 players took too long")
+	}
+}
+
+func (s *SwissTestSuite) TestBuchholz() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b", "c", "d", "e", "f")
+	results := []Result{
+		finished("a", "b", 1),
+		finished("c", "d", 1),
+		finished("e", "f", 1),
+		finished("a", "c", 1),
+		finished("b", "e", 0.5),
+	}
+
+	standings := standings(Swiss, players, results)
+
+	ranking := []users.Id{}
+	for _, standing := range standings {
+		ranking = append(ranking, standing.UserId)
+	}
+
+	// d and f both lost their only game, but f lost to the stronger
+	// opponent
+	assert.Equal([]users.Id{"a", "e", "c", "b", "f", "d"}, ranking)
+	assert.Equal(1.5, standings[4].Buchholz)
+	assert.Equal(1.0, standings[5].Buchholz)
+
+	// with two opponents there are none to drop for the median
+	assert.Equal(3.5, standings[3].Buchholz)
+	assert.Equal(3.5, standings[3].MedianBuchholz)
+}
+
+func (s *SwissTestSuite) TestMedianBuchholz() {
+	assert := assert.New(s.T())
+
+	players := participants("a", "b", "c", "d")
+	results := []Result{
+		finished("a", "b", 1),
+		finished("c", "d", 1),
+		finished("a", "c", 1),
+		finished("d", "b", 0.5),
+		finished("d", "a", 1),
+		finished("b", "c", 0),
+	}
+
+	standings := standings(Swiss, players, results)
+
+	// a scored 2 against b with 0.5, c with 2 and d with 1.5
+	assert.Equal(users.Id("a"), standings[0].UserId)
+	assert.Equal(4.0, standings[0].Buchholz)
+	assert.Equal(1.5, standings[0].MedianBuchholz)
+}
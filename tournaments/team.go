@@ -0,0 +1,81 @@
+package tournaments
+
+import (
+	"foodtastechess/users"
+)
+
+// the teams of a team match, as numbered on their participants
+const (
+	homeTeam = 1
+	awayTeam = 2
+)
+
+// Team is one side of a team match, with its players in board order
+type Team struct {
+	Name    string
+	Players []users.Id
+}
+
+// TeamScore is a team's share of the points from its boards
+type TeamScore struct {
+	Name   string
+	Score  float64
+	Boards int
+}
+
+// teamRound pairs each home player with the away player on the same
+// board. The home team has white on odd boards and black on even ones.
+// Players are in seed order, which for a team match is board order.
+func teamRound(players []Participant) []pair {
+	home := []int{}
+	away := []int{}
+	for i, player := range players {
+		if player.Team == homeTeam {
+			home = append(home, i)
+		} else if player.Team == awayTeam {
+			away = append(away, i)
+		}
+	}
+
+	pairs := []pair{}
+	for board := 0; board < len(home) && board < len(away); board++ {
+		if board%2 == 0 {
+			pairs = append(pairs, pair{home[board], away[board]})
+		} else {
+			pairs = append(pairs, pair{away[board], home[board]})
+		}
+	}
+
+	return pairs
+}
+
+// teamScores totals the points each team has scored on its boards
+func teamScores(tournament Tournament, players []Participant, results []Result) []TeamScore {
+	teams := make(map[users.Id]int)
+	for _, player := range players {
+		teams[player.UserId] = player.Team
+	}
+
+	scores := []TeamScore{
+		{Name: tournament.HomeTeam},
+		{Name: tournament.AwayTeam},
+	}
+
+	for _, result := range results {
+		whiteTeam := teams[result.WhiteId]
+		blackTeam := teams[result.BlackId]
+		if whiteTeam == 0 || blackTeam == 0 {
+			continue
+		}
+
+		white := &scores[whiteTeam-1]
+		black := &scores[blackTeam-1]
+
+		white.Boards += 1
+		black.Boards += 1
+		white.Score += result.WhiteScore
+		black.Score += result.BlackScore
+	}
+
+	return scores
+}
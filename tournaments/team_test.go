@@ -0,0 +1,54 @@
+package tournaments
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type TeamTestSuite struct {
+	suite.Suite
+}
+
+func TestTeamTestSuite(t *testing.T) {
+	suite.Run(t, new(TeamTestSuite))
+}
+
+// teamPlayers seeds two teams of three by board
+func teamPlayers() []Participant {
+	return []Participant{
+		{UserId: "h1", Seed: 1, Team: homeTeam},
+		{UserId: "a1", Seed: 1, Team: awayTeam},
+		{UserId: "h2", Seed: 2, Team: homeTeam},
+		{UserId: "a2", Seed: 2, Team: awayTeam},
+		{UserId: "h3", Seed: 3, Team: homeTeam},
+		{UserId: "a3", Seed: 3, Team: awayTeam},
+	}
+}
+
+func (s *TeamTestSuite) TestBoards() {
+	assert := assert.New(s.T())
+
+	pairs := teamRound(teamPlayers())
+
+	// colours alternate down the boards
+	assert.Equal([]pair{{0, 1}, {3, 2}, {4, 5}}, pairs)
+}
+
+func (s *TeamTestSuite) TestScores() {
+	assert := assert.New(s.T())
+
+	tournament := Tournament{Format: TeamMatch, HomeTeam: "London", AwayTeam: "Leeds"}
+	results := []Result{
+		finished("h1", "a1", 1),
+		finished("a2", "h2", 0.5),
+		{Pairing: Pairing{WhiteId: "h3", BlackId: "a3"}},
+	}
+
+	scores := teamScores(tournament, teamPlayers(), results)
+
+	assert.Equal([]TeamScore{
+		{Name: "London", Score: 1.5, Boards: 3},
+		{Name: "Leeds", Score: 0.5, Boards: 3},
+	}, scores)
+}
@@ -0,0 +1,114 @@
+package tournaments
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"foodtastechess/game"
+	"foodtastechess/users"
+)
+
+type Format string
+
+const (
+	RoundRobin Format = "roundrobin"
+	Swiss      Format = "swiss"
+	Arena      Format = "arena"
+	TeamMatch  Format = "team"
+)
+
+func (u *Format) Scan(value interface{}) error {
+	*u = Format(value.([]byte))
+	return nil
+}
+
+func (u Format) Value() (driver.Value, error) {
+	return string(u), nil
+}
+
+type Status string
+
+const (
+	StatusCreated  Status = "created"
+	StatusStarted  Status = "started"
+	StatusFinished Status = "finished"
+)
+
+func (u *Status) Scan(value interface{}) error {
+	*u = Status(value.([]byte))
+	return nil
+}
+
+func (u Status) Value() (driver.Value, error) {
+	return string(u), nil
+}
+
+type Tournament struct {
+	Id            int
+	Name          string
+	Format        Format
+	OrganizerId   users.Id `sql:"index" json:"-"`
+	TimeInitial   int
+	TimeIncrement int
+	Rounds        int
+	CurrentRound  int
+	Status        Status
+
+	// arenas run for Minutes from when they start, until EndsAt
+	Minutes int
+	EndsAt  time.Time
+
+	// the names of the sides of a team match
+	HomeTeam string `json:",omitempty"`
+	AwayTeam string `json:",omitempty"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (t Tournament) TableName() string {
+	return fmt.Sprintf("%stournaments", tablePrefix)
+}
+
+func (t Tournament) TimeControl() game.TimeControl {
+	return game.TimeControl{
+		Initial:   t.TimeInitial,
+		Increment: t.TimeIncrement,
+	}
+}
+
+// Participant is a player in a tournament. Seeds number players from 1
+// in the order they were entered. In a team match players are seeded by
+// board within their team.
+type Participant struct {
+	Id           int `json:"-"`
+	TournamentId int `sql:"index" json:"-"`
+	UserId       users.Id
+	Seed         int
+	Team         int `json:",omitempty"`
+}
+
+func (p Participant) TableName() string {
+	return fmt.Sprintf("%stournament_players", tablePrefix)
+}
+
+// Pairing is a game of a tournament round. A pairing without a black
+// player is a bye, and has no game.
+type Pairing struct {
+	Id           int `json:"-"`
+	TournamentId int `sql:"index" json:"-"`
+	Round        int
+	Board        int
+	WhiteId      users.Id
+	BlackId      users.Id
+	GameId       game.Id `sql:"index"`
+}
+
+func (p Pairing) TableName() string {
+	return fmt.Sprintf("%stournament_pairings", tablePrefix)
+}
+
+func (p Pairing) bye() bool {
+	return p.BlackId == ""
+}
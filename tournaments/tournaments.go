@@ -0,0 +1,642 @@
+package tournaments
+
+import (
+	"fmt"
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/op/go-logging"
+	"sync"
+	"time"
+
+	"foodtastechess/commands"
+	"foodtastechess/config"
+	"foodtastechess/events"
+	"foodtastechess/game"
+	"foodtastechess/logger"
+	"foodtastechess/queries"
+	"foodtastechess/ratings"
+	"foodtastechess/users"
+)
+
+var tablePrefix string = ""
+
+const (
+	// how often arenas are paired and clocks are checked
+	tickInterval = 1 * time.Second
+
+	// the longest an arena may run
+	maxArenaMinutes = 24 * 60
+)
+
+// Tournaments runs round-robin and Swiss tournaments, and team matches:
+// it pairs each round, creates the games through commands, and moves on
+// to the next round once every game of the current one has ended. Arenas
+// instead pair players again as soon as they finish a game, until time
+// runs out.
+type Tournaments interface {
+	events.EventSubscriber
+
+	Create(organizerId users.Id, spec Spec) (Tournament, error)
+	Begin(tournamentId int, userId users.Id) error
+	Tournament(tournamentId int) (TournamentInformation, bool)
+	Tournaments() []Tournament
+}
+
+// Spec describes a tournament to create. Players are seeded in the
+// order they are listed. Round-robins take as many rounds as it takes
+// everyone to play each other, Swiss tournaments take Rounds, and arenas
+// run for Minutes. Team matches are between the two Teams instead of
+// Players, and are played in a single round.
+type Spec struct {
+	Name        string
+	Format      Format
+	Players     []users.Id
+	Teams       []Team
+	TimeControl game.TimeControl
+	Rounds      int
+	Minutes     int
+}
+
+type TournamentInformation struct {
+	Tournament
+
+	Players   []Participant
+	Results   []Result
+	Standings []Standing
+	Teams     []TeamScore `json:",omitempty"`
+}
+
+type TournamentsService struct {
+	Config    config.DatabaseConfig `inject:"databaseConfig"`
+	Commands  commands.Executor     `inject:"commands"`
+	Queries   queries.ClientQueries `inject:"clientQueries"`
+	Events    events.Events         `inject:"events"`
+	Users     users.Users           `inject:"users"`
+	Publisher events.EventPublisher `inject:"eventSubscriber"`
+
+	log      *logging.Logger
+	db       gorm.DB
+	events   *events.Queue
+	stopChan chan bool
+
+	// held while a tournament's rounds are changing
+	lock sync.Mutex
+}
+
+func New() Tournaments {
+	s := new(TournamentsService)
+	s.log = logger.Log("tournaments")
+	s.events = events.NewQueue()
+	s.stopChan = make(chan bool, 1)
+	return s
+}
+
+func (s *TournamentsService) PostPopulate() error {
+	// hook for test-suite, make a global table prefix if our config
+	// defines it
+	tablePrefix = s.Config.Prefix
+
+	dsn := fmt.Sprintf(
+		"%s:%s@tcp(%s:%s)/%s?charset=utf8&parseTime=True",
+		s.Config.Username, s.Config.Password,
+		s.Config.HostAddr, s.Config.Port,
+		s.Config.Database,
+	)
+
+	db, err := gorm.Open("mysql", dsn)
+
+	db.LogMode(true)
+	db.AutoMigrate(&Tournament{}, &Participant{}, &Pairing{})
+
+	s.db = db
+
+	// hear about games once queries have caught up with them, so
+	// commands issued in response see them
+	s.Publisher.Subscribe(s)
+
+	return err
+}
+
+func (s *TournamentsService) Start() error {
+	s.log.Notice("Running tournaments")
+	go s.Process()
+	return nil
+}
+
+// Process handles events away from the query buffer, since handling
+// them issues commands whose events go back through it
+func (s *TournamentsService) Process() {
+	// ticks keep coming however many events arrive between them
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.events.Ready():
+			for _, event := range s.events.Pop() {
+				s.handle(event)
+			}
+		case <-ticker.C:
+			s.tick()
+		case <-s.stopChan:
+			s.log.Info("Tournaments stopped")
+			return
+		}
+	}
+}
+
+func (s *TournamentsService) Stop() error {
+	s.log.Notice("Stopping tournaments")
+	s.stopChan <- true
+	return nil
+}
+
+func (s *TournamentsService) Receive(event events.Event) error {
+	switch event.Type {
+	case events.GameCreateType, events.GameEndType:
+		s.events.Push(event)
+	}
+	return nil
+}
+
+// Create enters a tournament that has yet to start
+func (s *TournamentsService) Create(organizerId users.Id, spec Spec) (Tournament, error) {
+	if spec.Name == "" {
+		return Tournament{}, commands.Refuse(commands.InvalidTournament, "Tournament needs a name.")
+	}
+
+	if ok, refusal := commands.CheckTimeControl(spec.TimeControl); !ok {
+		return Tournament{}, refusal
+	}
+
+	players := []Participant{}
+	if spec.Format == TeamMatch {
+		if len(spec.Teams) != 2 {
+			return Tournament{}, commands.Refuse(commands.InvalidTournament, "A team match is between two teams.")
+		}
+
+		if spec.Teams[0].Name == "" || spec.Teams[1].Name == "" {
+			return Tournament{}, commands.Refuse(commands.InvalidTournament, "Teams need names.")
+		}
+
+		if len(spec.Teams[0].Players) != len(spec.Teams[1].Players) {
+			return Tournament{}, commands.Refuse(commands.InvalidTournament, "Teams need a player for every board.")
+		}
+
+		for i, team := range spec.Teams {
+			for board, userId := range team.Players {
+				players = append(players, Participant{
+					UserId: userId,
+					Seed:   board + 1,
+					Team:   i + 1,
+				})
+			}
+		}
+	} else {
+		for i, userId := range spec.Players {
+			players = append(players, Participant{UserId: userId, Seed: i + 1})
+		}
+	}
+
+	if len(players) < 2 {
+		return Tournament{}, commands.Refuse(commands.InvalidTournament, "Tournament needs at least two players.")
+	}
+
+	seen := make(map[users.Id]bool)
+	for _, player := range players {
+		userId := player.UserId
+		if seen[userId] {
+			return Tournament{}, commands.Refuse(commands.InvalidTournament, "Players can only be entered once.")
+		}
+		seen[userId] = true
+
+		if _, found := s.Users.Get(userId); !found {
+			return Tournament{}, commands.Refuse(commands.UserNotFound, "Player does not exist.")
+		}
+	}
+
+	var rounds int
+	switch spec.Format {
+	case RoundRobin:
+		rounds = roundRobinRounds(len(players))
+	case Swiss:
+		// a Swiss tournament runs out of new pairings after as many
+		// rounds as a round-robin
+		if spec.Rounds < 1 || spec.Rounds > roundRobinRounds(len(players)) {
+			return Tournament{}, commands.Refuse(commands.InvalidTournament, "Too few players for that many rounds.")
+		}
+		rounds = spec.Rounds
+	case Arena:
+		if spec.TimeControl.Untimed() {
+			return Tournament{}, commands.Refuse(commands.InvalidTournament, "Arenas must be played with a clock.")
+		}
+		if spec.Minutes < 1 || spec.Minutes > maxArenaMinutes {
+			return Tournament{}, commands.Refuse(commands.InvalidTournament, "Arenas must last between a minute and a day.")
+		}
+	case TeamMatch:
+		rounds = 1
+	default:
+		return Tournament{}, commands.Refuse(commands.InvalidTournament, "Unknown tournament format.")
+	}
+
+	tournament := Tournament{
+		Name:          spec.Name,
+		Format:        spec.Format,
+		OrganizerId:   organizerId,
+		TimeInitial:   spec.TimeControl.Initial,
+		TimeIncrement: spec.TimeControl.Increment,
+		Rounds:        rounds,
+		Minutes:       spec.Minutes,
+		Status:        StatusCreated,
+	}
+
+	if spec.Format == TeamMatch {
+		tournament.HomeTeam = spec.Teams[0].Name
+		tournament.AwayTeam = spec.Teams[1].Name
+	}
+
+	s.db.Create(&tournament)
+
+	for _, player := range players {
+		player.TournamentId = tournament.Id
+		s.db.Create(&player)
+	}
+
+	return tournament, nil
+}
+
+// Begin pairs the first round of a tournament, or sets an arena's clock
+// running. Only its organizer may start it.
+func (s *TournamentsService) Begin(tournamentId int, userId users.Id) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	tournament, found := s.get(tournamentId)
+	if !found {
+		return commands.Refuse(commands.TournamentNotFound, "Tournament does not exist.")
+	}
+
+	if tournament.OrganizerId != userId {
+		return commands.Refuse(commands.NotOrganizer, "Only the organizer can start a tournament.")
+	}
+
+	if tournament.Status != StatusCreated {
+		return commands.Refuse(commands.TournamentStarted, "Tournament has already started.")
+	}
+
+	tournament.Status = StatusStarted
+
+	if tournament.Format == Arena {
+		tournament.EndsAt = time.Now().Add(time.Duration(tournament.Minutes) * time.Minute)
+		s.db.Save(&tournament)
+		s.pairArena(&tournament)
+	} else {
+		s.nextRound(&tournament)
+	}
+
+	return nil
+}
+
+// Tournament describes a tournament, with its games so far and its
+// current standings
+func (s *TournamentsService) Tournament(tournamentId int) (TournamentInformation, bool) {
+	tournament, found := s.get(tournamentId)
+	if !found {
+		return TournamentInformation{}, false
+	}
+
+	players := s.players(tournament)
+	results := s.results(tournament)
+
+	info := TournamentInformation{
+		Tournament: tournament,
+		Players:    players,
+		Results:    results,
+		Standings:  standings(tournament.Format, players, results),
+	}
+
+	if tournament.Format == TeamMatch {
+		info.Teams = teamScores(tournament, players, results)
+	}
+
+	return info, true
+}
+
+// Tournaments lists every tournament, newest first
+func (s *TournamentsService) Tournaments() []Tournament {
+	tournaments := []Tournament{}
+	s.db.Order("id desc").Find(&tournaments)
+	return tournaments
+}
+
+func (s *TournamentsService) handle(event events.Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	pairing := Pairing{}
+	s.db.Where(&Pairing{GameId: event.GameId}).First(&pairing)
+	if pairing.GameId != event.GameId {
+		return
+	}
+
+	switch event.Type {
+	case events.GameCreateType:
+		// games are created as a challenge from white, which black
+		// accepts on their behalf
+		ok, refusal := s.Commands.ExecCommand(
+			commands.JoinGame, pairing.BlackId, map[string]interface{}{
+				"gameId": pairing.GameId,
+			},
+		)
+		if !ok {
+			s.log.Error("Could not start game %v: %s", pairing.GameId, refusal)
+		}
+
+	case events.GameEndType:
+		tournament, found := s.get(pairing.TournamentId)
+		if !found || tournament.Status != StatusStarted {
+			return
+		}
+
+		if tournament.Format == Arena {
+			s.pairArena(&tournament)
+		} else if pairing.Round == tournament.CurrentRound && s.roundFinished(tournament) {
+			s.nextRound(&tournament)
+		}
+	}
+}
+
+// tick ends arenas whose time is up, pairs anyone left waiting in the
+// others, and ends games where a player has run out of time
+func (s *TournamentsService) tick() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var started []Tournament
+	s.db.Where(&Tournament{Status: StatusStarted}).Find(&started)
+
+	now := time.Now()
+	for _, tournament := range started {
+		if tournament.Format == Arena {
+			if now.After(tournament.EndsAt) {
+				// games already under way still count
+				tournament.Status = StatusFinished
+				s.db.Save(&tournament)
+			} else {
+				s.pairArena(&tournament)
+			}
+		}
+
+		s.flag(tournament)
+	}
+}
+
+// flag ends the tournament's games where a player's time has run out,
+// claiming the win for their opponent
+func (s *TournamentsService) flag(tournament Tournament) {
+	for _, result := range s.results(tournament) {
+		if result.Finished {
+			continue
+		}
+
+		gameInfo, found := s.Queries.GameInformation(result.GameId)
+		if !found || gameInfo.Clock == nil || gameInfo.GameStatus != queries.GameStatusStarted {
+			continue
+		}
+
+		claimant := users.Id("")
+		if gameInfo.Clock.Running == game.White && gameInfo.Clock.White == 0 {
+			claimant = result.BlackId
+		} else if gameInfo.Clock.Running == game.Black && gameInfo.Clock.Black == 0 {
+			claimant = result.WhiteId
+		}
+
+		if claimant == "" {
+			continue
+		}
+
+		ok, refusal := s.Commands.ExecCommand(
+			commands.ClaimTimeout, claimant, map[string]interface{}{
+				"gameId": result.GameId,
+			},
+		)
+		if !ok {
+			s.log.Error("Could not end game %v on time: %s", result.GameId, refusal)
+		}
+	}
+}
+
+// pairArena pairs the players of an arena who aren't playing
+func (s *TournamentsService) pairArena(tournament *Tournament) {
+	if time.Now().After(tournament.EndsAt) {
+		return
+	}
+
+	players := s.players(*tournament)
+	results := s.results(*tournament)
+
+	waiting := make(map[users.Id]bool)
+	for _, player := range players {
+		waiting[player.UserId] = true
+	}
+	for _, result := range results {
+		if !result.Finished {
+			waiting[result.WhiteId] = false
+			waiting[result.BlackId] = false
+		}
+	}
+
+	pairs := arenaRound(players, results, waiting)
+	if len(pairs) == 0 {
+		return
+	}
+
+	tournament.CurrentRound += 1
+	s.db.Save(tournament)
+
+	s.createGames(tournament, players, pairs)
+}
+
+// nextRound pairs and creates the games for the round after the current
+// one, or finishes the tournament after its last round
+func (s *TournamentsService) nextRound(tournament *Tournament) {
+	if tournament.CurrentRound >= tournament.Rounds {
+		tournament.Status = StatusFinished
+		s.db.Save(tournament)
+		return
+	}
+
+	players := s.players(*tournament)
+
+	var pairs []pair
+	switch tournament.Format {
+	case Swiss:
+		pairs = swissRound(players, s.results(*tournament))
+	case TeamMatch:
+		pairs = teamRound(players)
+	default:
+		pairs = bergerRound(len(players), tournament.CurrentRound+1)
+	}
+
+	// Swiss tournaments can run out of players who haven't met
+	if len(pairs) == 0 {
+		s.log.Error("Could not pair the next round of tournament %d", tournament.Id)
+		tournament.Status = StatusFinished
+		s.db.Save(tournament)
+		return
+	}
+
+	tournament.CurrentRound += 1
+	s.db.Save(tournament)
+
+	s.createGames(tournament, players, pairs)
+
+	// a round of nothing but byes has nothing to wait for
+	if s.roundFinished(*tournament) {
+		s.nextRound(tournament)
+	}
+}
+
+// createGames records the pairings of the tournament's current round, and
+// creates a game for each pair that isn't a bye. Pairings whose game is
+// refused are dropped.
+func (s *TournamentsService) createGames(tournament *Tournament, players []Participant, pairs []pair) {
+	pairings := []Pairing{}
+	for board, p := range pairs {
+		pairing := Pairing{
+			TournamentId: tournament.Id,
+			Round:        tournament.CurrentRound,
+			Board:        board + 1,
+			WhiteId:      players[p.white].UserId,
+		}
+
+		if p.black != bye {
+			pairing.BlackId = players[p.black].UserId
+			pairing.GameId = s.Events.NextGameId()
+		}
+
+		s.db.Create(&pairing)
+		pairings = append(pairings, pairing)
+	}
+
+	for _, pairing := range pairings {
+		if pairing.bye() {
+			continue
+		}
+
+		ok, refusal := s.Commands.ExecCommand(
+			commands.CreateGame, pairing.WhiteId, map[string]interface{}{
+				"gameId":      pairing.GameId,
+				"color":       game.White,
+				"opponent":    pairing.BlackId,
+				"timeControl": tournament.TimeControl(),
+			},
+		)
+		if !ok {
+			// a game that never exists would never end, and hold up
+			// the round
+			s.log.Error(
+				"Could not create game for %s and %s: %s",
+				pairing.WhiteId, pairing.BlackId, refusal,
+			)
+			s.db.Delete(&pairing)
+		}
+	}
+}
+
+// roundFinished reports whether every game of the current round has
+// ended
+func (s *TournamentsService) roundFinished(tournament Tournament) bool {
+	for _, result := range s.results(tournament) {
+		if result.Round == tournament.CurrentRound && !result.Finished {
+			return false
+		}
+	}
+
+	return true
+}
+
+// results works out how each of a tournament's pairings turned out from
+// the end of its game
+func (s *TournamentsService) results(tournament Tournament) []Result {
+	pairings := []Pairing{}
+	s.db.
+		Where(&Pairing{TournamentId: tournament.Id}).
+		Order("round asc, board asc").
+		Find(&pairings)
+
+	results := []Result{}
+	for _, pairing := range pairings {
+		result := Result{Pairing: pairing}
+
+		if pairing.bye() {
+			result.Finished = true
+			result.WhiteScore = byeScore(tournament.Format)
+		} else {
+			gameEnds := s.Events.EventsOfTypeForGame(pairing.GameId, events.GameEndType)
+			if len(gameEnds) > 0 {
+				result.Finished = true
+				result.EndedAt = gameEnds[0].CreatedAt
+				if gameEnds[0].Reason == game.GameEndAborted {
+					result.forfeit(gameEnds[0].AuthorId)
+				} else {
+					result.WhiteScore = ratings.WhiteScore(gameEnds[0].Winner)
+					result.BlackScore = 1 - result.WhiteScore
+				}
+			}
+
+			if tournament.Format == Arena {
+				berserks := s.Events.EventsOfTypeForGame(pairing.GameId, events.BerserkType)
+				for _, berserk := range berserks {
+					if berserk.Offerer == game.White {
+						result.WhiteBerserk = true
+					} else {
+						result.BlackBerserk = true
+					}
+				}
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// byeScore is what a player scores for sitting a round out
+func byeScore(format Format) float64 {
+	if format == Swiss {
+		return 1
+	} else {
+		return 0
+	}
+}
+
+func (s *TournamentsService) get(tournamentId int) (Tournament, bool) {
+	tournament := Tournament{}
+	s.db.Where(&Tournament{Id: tournamentId}).First(&tournament)
+	found := (tournament.Id == tournamentId && tournamentId != 0)
+	return tournament, found
+}
+
+func (s *TournamentsService) players(tournament Tournament) []Participant {
+	players := []Participant{}
+	s.db.
+		Where(&Participant{TournamentId: tournament.Id}).
+		Order("seed asc").
+		Find(&players)
+	return players
+}
+
+func (s *TournamentsService) ResetTestDB() {
+	if tablePrefix != "test_" {
+		s.log.Error(
+			"Cannot reset a database not configured with ConfigTestProvider",
+		)
+		return
+	}
+	s.db.DropTable(&Tournament{})
+	s.db.DropTable(&Participant{})
+	s.db.DropTable(&Pairing{})
+	s.db.AutoMigrate(&Tournament{}, &Participant{}, &Pairing{})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/satori/go.uuid"
 	"time"
 
+	"foodtastechess/game"
 	"foodtastechess/logger"
 )
 
@@ -31,6 +32,9 @@ type User struct {
 	AccessToken       string `json:"-"`
 	AccessTokenSecret string `json:"-"`
 
+	// filled in from the ratings service, by category
+	Ratings map[game.TimeCategory]float64 `sql:"-" json:",omitempty"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }